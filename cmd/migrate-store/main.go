@@ -0,0 +1,71 @@
+// Command migrate-store copies all invites and webhooks from one storage
+// driver to another, e.g. from a BBolt file into SQLite for reporting and back:
+//
+//	migrate-store -from /data/wedding.db -to /data/wedding.sqlite
+//	migrate-store -from-driver sqlite -from /data/wedding.sqlite -to-driver bbolt -to /data/wedding.db
+//
+// Stop the server first: BBolt files are locked by the process using them,
+// and RSVPs written during the copy would be lost.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func main() {
+	fromDriver := flag.String("from-driver", store.DriverBBolt, "source driver: bbolt or sqlite")
+	from := flag.String("from", "", "source database path")
+	toDriver := flag.String("to-driver", store.DriverSQLite, "destination driver: bbolt or sqlite")
+	to := flag.String("to", "", "destination database path, created if missing")
+	replace := flag.Bool("replace", false, "replace the invites and webhooks already in the destination")
+	flag.Parse()
+
+	log.SetFormatter(&log.JSONFormatter{})
+	if *from == "" || *to == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*fromDriver, *from, *toDriver, *to, *replace); err != nil {
+		log.WithError(err).Fatal("store migration failed")
+	}
+}
+
+func run(fromDriver, from, toDriver, to string, replace bool) error {
+	// Reason: opening a missing BBolt path would create an empty source and
+	// "copy" nothing
+	if _, err := os.Stat(from); err != nil {
+		return fmt.Errorf("checking source: %w", err)
+	}
+
+	src, err := store.Open(fromDriver, from)
+	if err != nil {
+		return fmt.Errorf("opening source: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := store.Open(toDriver, to)
+	if err != nil {
+		return fmt.Errorf("opening destination: %w", err)
+	}
+	defer dst.Close()
+
+	copied, err := store.Copy(context.Background(), dst, src, replace)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"invites":    copied.Invites,
+		"webhooks":   copied.Webhooks,
+		"deliveries": copied.Deliveries,
+		"from":       fromDriver + ":" + from,
+		"to":         toDriver + ":" + to,
+	}).Info("copied store")
+	return nil
+}
@@ -1,45 +1,131 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sync"
 	"syscall"
+	"time"
+	// Reason: the image has no zoneinfo, and NOTIFY_TIMEZONE needs it
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"github.com/dimitarkovachev/wedding/internal/admin"
 	"github.com/dimitarkovachev/wedding/internal/api"
+	"github.com/dimitarkovachev/wedding/internal/assets"
 	"github.com/dimitarkovachev/wedding/internal/config"
+	"github.com/dimitarkovachev/wedding/internal/events"
+	"github.com/dimitarkovachev/wedding/internal/guest"
+	"github.com/dimitarkovachev/wedding/internal/logging"
 	"github.com/dimitarkovachev/wedding/internal/middleware"
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/notify"
 	"github.com/dimitarkovachev/wedding/internal/seed"
 	"github.com/dimitarkovachev/wedding/internal/store"
+	"github.com/dimitarkovachev/wedding/internal/tracing"
+	"github.com/dimitarkovachev/wedding/internal/webhook"
+	"github.com/dimitarkovachev/wedding/web"
 )
 
 func main() {
 	cfg := config.Load()
 
-	log.SetFormatter(&log.JSONFormatter{})
+	log.SetFormatter(&logging.RedactingFormatter{Formatter: &log.JSONFormatter{}})
 	log.SetLevel(log.InfoLevel)
 
 	gin.SetMode(cfg.GinMode)
 
-	if err := os.MkdirAll(filepath.Dir(cfg.DBPath), 0755); err != nil {
-		log.WithError(err).Fatal("failed to create db directory")
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TraceExporter,
+		FilePath:    cfg.TraceFile,
+		ServiceName: cfg.ServiceName,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("failed to set up tracing")
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.WithError(err).Error("tracing shutdown error")
+		}
+	}()
+
+	driver := store.ResolveDriver(cfg.StoreDriver, cfg.DBPath)
+	if driver != store.DriverMemory {
+		if err := os.MkdirAll(filepath.Dir(cfg.DBPath), 0755); err != nil {
+			log.WithError(err).Fatal("failed to create db directory")
+		}
+	}
+
+	storeOpts := []store.Option{store.WithViewPolicy(store.ViewPolicy{
+		DedupeWindow: cfg.ViewDedupeWindow,
+		MaxRecent:    cfg.ViewHistoryLimit,
+	})}
+	if cfg.RSVPDeadline != "" {
+		deadline, err := time.Parse(time.RFC3339, cfg.RSVPDeadline)
+		if err != nil {
+			log.WithError(err).Fatal("invalid RSVP_DEADLINE, expected RFC 3339")
+		}
+		storeOpts = append(storeOpts, store.WithRSVPDeadline(deadline))
 	}
 
-	bboltStore, err := store.NewBBoltStore(cfg.DBPath)
+	db, err := store.Open(driver, cfg.DBPath, storeOpts...)
 	if err != nil {
-		log.WithError(err).Fatal("failed to open bbolt store")
+		log.WithError(err).WithField("driver", driver).Fatal("failed to open store")
 	}
-	defer bboltStore.Close()
+	log.WithField("driver", driver).Info("store opened")
+	defer db.Close()
 
-	if err := seed.LoadFromFile(cfg.SeedFile, bboltStore); err != nil {
+	webhooks := webhook.NewDispatcher(context.Background(), db, webhook.Options{
+		Timeout:      cfg.WebhookTimeout,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		RetryBase:    cfg.WebhookRetryBase,
+		PollInterval: cfg.WebhookPollInterval,
+		Retention:    cfg.WebhookLogRetention,
+	})
+	defer webhooks.Stop()
+	var publishers events.Publishers
+	if cfg.SMTPHost != "" {
+		notifier, err := notify.FromConfig(context.Background(), cfg, db)
+		if err != nil {
+			log.WithError(err).Fatal("failed to set up email notifications")
+		}
+		defer notifier.Stop()
+		publishers = append(publishers, notifier)
+	}
+	// Reason: wrapped before anything else uses the store, so guest replies,
+	// admin edits and buffered views all publish their events
+	db = events.WithEvents(db, webhooks, publishers)
+
+	// Reason: views are queued and written in batches so public reads never
+	// wait for the store's writer lock; Shutdown below flushes the queue
+	viewBuffer := store.NewViewBuffer(db, store.ViewBufferOptions{
+		QueueSize:     cfg.ViewQueueSize,
+		BatchSize:     cfg.ViewBatchSize,
+		FlushInterval: cfg.ViewFlushInterval,
+	})
+	publicStore := store.WithViewBuffer(db, viewBuffer)
+
+	nameRules, err := names.NewRules(names.ParseScripts(cfg.NameAllowedScripts), cfg.NameExtraChars, cfg.NameMaxLength)
+	if err != nil {
+		log.WithError(err).Fatal("invalid name rules")
+	}
+	log.WithField("pattern", nameRules.Pattern()).Info("guest name rules")
+
+	// Reason: a large seed file can take a while; an interrupt during it
+	// should stop the seed rather than wait for it
+	seedCtx, stopSeed := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err = seed.LoadFromFile(seedCtx, cfg.SeedFile, db, nameRules)
+	stopSeed()
+	if err != nil {
 		log.WithError(err).Fatal("failed to seed data")
 	}
 
@@ -47,36 +133,159 @@ func main() {
 	if err != nil {
 		log.WithError(err).Fatal("failed to load embedded swagger spec")
 	}
+	if err := nameRules.ApplyToSpec(swagger); err != nil {
+		log.WithError(err).Fatal("failed to apply name rules to spec")
+	}
 
 	validator, err := middleware.NewOpenAPIValidator(swagger)
 	if err != nil {
 		log.WithError(err).Fatal("failed to create openapi validator")
 	}
 
+	responseMode, err := middleware.ParseResponseValidationMode(cfg.ResponseValidation)
+	if err != nil {
+		log.WithError(err).Fatal("invalid response validation mode")
+	}
+
+	responseValidator, err := middleware.NewOpenAPIResponseValidator(swagger, responseMode)
+	if err != nil {
+		log.WithError(err).Fatal("failed to create openapi response validator")
+	}
+
+	adminSwagger, err := admin.GetSwagger()
+	if err != nil {
+		log.WithError(err).Fatal("failed to load embedded admin swagger spec")
+	}
+	if err := nameRules.ApplyToSpec(adminSwagger); err != nil {
+		log.WithError(err).Fatal("failed to apply name rules to admin spec")
+	}
+
+	adminResponseValidator, err := middleware.NewOpenAPIResponseValidator(adminSwagger, responseMode)
+	if err != nil {
+		log.WithError(err).Fatal("failed to create admin openapi response validator")
+	}
+
+	policies := middleware.DefaultRateLimitPolicies(
+		cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.RateLimitInviteRPS, cfg.RateLimitInviteBurst,
+	)
+	if cfg.RateLimitPolicyFile != "" {
+		policies, err = middleware.LoadRateLimitPolicies(cfg.RateLimitPolicyFile)
+		if err != nil {
+			log.WithError(err).Fatal("failed to load rate limit policies")
+		}
+	}
+
+	rateLimiter, err := middleware.NewRateLimiter(context.Background(), policies, middleware.RateLimiterOptions{
+		MaxVisitors: cfg.RateLimitMaxVisitors,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("failed to create rate limiter")
+	}
+	defer rateLimiter.Stop()
+
+	idempotency := middleware.NewIdempotency(context.Background(), db, middleware.IdempotencyOptions{
+		TTL: cfg.IdempotencyTTL,
+	})
+	defer idempotency.Stop()
+
+	// Reason: WEB_DIR is a development override; normally the embedded files
+	// are served so the binary does not depend on its working directory
+	var webFS fs.FS = web.FS
+	if cfg.WebDir != "" {
+		webFS = os.DirFS(cfg.WebDir)
+		log.WithField("dir", cfg.WebDir).Warn("serving web assets from disk")
+	}
+
+	guestFS, err := assets.Sub(webFS, "guest")
+	if err != nil {
+		log.WithError(err).Fatal("failed to find guest page assets")
+	}
+	guestSite, err := assets.New(guestFS, "/static/", []string{guest.PageFile, "style.css"}, cfg.WebDir != "")
+	if err != nil {
+		log.WithError(err).Fatal("failed to load guest page assets")
+	}
+	weddingInfo := guest.Info{
+		Couple:  cfg.WeddingCouple,
+		Venue:   cfg.WeddingVenue,
+		Address: cfg.WeddingAddress,
+		MapURL:  cfg.WeddingMapURL,
+	}
+	if cfg.WeddingDate != "" {
+		if weddingInfo.Date, err = time.Parse(time.RFC3339, cfg.WeddingDate); err != nil {
+			log.WithError(err).Fatal("invalid WEDDING_DATE, expected RFC 3339")
+		}
+	}
+	guestHandler, err := guest.NewHandler(publicStore, nameRules, guestSite, weddingInfo, cfg.PublicURL)
+	if err != nil {
+		log.WithError(err).Fatal("failed to load guest page")
+	}
+
 	r := gin.New()
+	r.Use(otelgin.Middleware(cfg.ServiceName + "-public"))
+	// Reason: the request logger wraps Recovery so panics are still logged as 500s
+	r.Use(middleware.NewRequestLogger("public"))
 	r.Use(gin.Recovery())
-	r.Use(middleware.NewRateLimiter(rate.Limit(cfg.RateLimitRPS), cfg.RateLimitBurst))
+	// Reason: the language is needed by the rate limiter and validator error bodies
+	r.Use(middleware.NewLanguageDetector())
+	r.Use(middleware.NewRequestTimeout(cfg.RequestTimeout))
+	r.Use(rateLimiter.Handler())
+
+	// Reason: the RSVP page is not part of the API spec, so it is registered
+	// before the OpenAPI validators, which only apply to later routes
+	r.GET("/i/:id", guestHandler.Show)
+	r.HEAD("/i/:id", guestHandler.Show)
+	r.POST("/i/:id", guestHandler.Submit)
+	r.GET("/static/*filepath", guestSite.File)
+	r.HEAD("/static/*filepath", guestSite.File)
+	r.GET(guest.PreviewImagePath, guestHandler.PreviewImage)
+	r.HEAD(guest.PreviewImagePath, guestHandler.PreviewImage)
+
+	// Reason: registered ahead of the request validator so its 400 bodies are checked too
+	r.Use(responseValidator)
 	r.Use(validator)
+	// Reason: after the validator, so a malformed request never claims a key,
+	// and inside the response validator, so replayed responses are checked too
+	r.Use(idempotency.Handler())
 
-	handler := api.NewHandler(bboltStore)
+	handler := api.NewHandler(publicStore, nameRules, swagger)
 	api.RegisterHandlers(r, handler)
 
-	srv := &http.Server{
-		Handler: r,
-		Addr:    net.JoinHostPort("0.0.0.0", cfg.Port),
+	if cfg.WriteTimeout > 0 && cfg.RequestTimeout >= cfg.WriteTimeout {
+		log.WithFields(log.Fields{"request_timeout": cfg.RequestTimeout, "write_timeout": cfg.WriteTimeout}).
+			Warn("REQUEST_TIMEOUT is not shorter than HTTP_WRITE_TIMEOUT; timed out requests may get no response")
+	}
+	srv := newServer(cfg, net.JoinHostPort("0.0.0.0", cfg.Port), r)
+
+	adminFS, err := assets.Sub(webFS, "admin")
+	if err != nil {
+		log.WithError(err).Fatal("failed to find admin UI assets")
+	}
+	adminUI, err := assets.New(adminFS, "/ui/", []string{"index.html", "app.js", "editor.js"}, cfg.WebDir != "")
+	if err != nil {
+		log.WithError(err).Fatal("failed to load admin UI assets")
+	}
+	adminIndex, err := adminUI.Page("index.html")
+	if err != nil {
+		log.WithError(err).Fatal("failed to render admin UI")
 	}
 
 	adminRouter := gin.New()
+	adminRouter.Use(otelgin.Middleware(cfg.ServiceName + "-admin"))
+	adminRouter.Use(middleware.NewRequestLogger("admin"))
 	adminRouter.Use(gin.Recovery())
+	adminRouter.Use(middleware.NewRequestTimeout(cfg.RequestTimeout))
+	adminRouter.Use(adminResponseValidator)
 
-	adminHandler := admin.NewHandler(bboltStore)
-	admin.RegisterHandlers(adminRouter, adminHandler)
-	adminRouter.StaticFile("/", filepath.Join(cfg.WebDir, "admin", "index.html"))
+	adminHandler := admin.NewHandler(db, nameRules)
+	admin.RegisterHandlersWithOptions(adminRouter, adminHandler, admin.GinServerOptions{
+		ErrorHandler: admin.ParamErrorHandler,
+	})
+	adminRouter.GET("/", adminIndex)
+	adminRouter.HEAD("/", adminIndex)
+	adminRouter.GET("/ui/*filepath", adminUI.File)
+	adminRouter.HEAD("/ui/*filepath", adminUI.File)
 
-	adminSrv := &http.Server{
-		Handler: adminRouter,
-		Addr:    net.JoinHostPort("0.0.0.0", cfg.AdminPort),
-	}
+	adminSrv := newServer(cfg, net.JoinHostPort("0.0.0.0", cfg.AdminPort), adminRouter)
 
 	go func() {
 		log.WithField("addr", srv.Addr).Info("starting server")
@@ -97,10 +306,44 @@ func main() {
 	sig := <-quit
 	log.WithField("signal", fmt.Sprintf("%v", sig)).Info("shutting down servers")
 
-	if err := srv.Close(); err != nil {
-		log.WithError(err).Error("server close error")
+	// Reason: requests still in flight may queue views, so the buffer is only
+	// shut down once both servers have finished them
+	shutdownCtx := context.Background()
+	if cfg.ShutdownTimeout > 0 {
+		var cancelShutdown context.CancelFunc
+		shutdownCtx, cancelShutdown = context.WithTimeout(shutdownCtx, cfg.ShutdownTimeout)
+		defer cancelShutdown()
+	}
+	var wg sync.WaitGroup
+	for name, s := range map[string]*http.Server{"server": srv, "admin server": adminSrv} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Shutdown(shutdownCtx); err != nil {
+				log.WithError(err).Errorf("%s shutdown error", name)
+				_ = s.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := viewBuffer.Shutdown(flushCtx); err != nil {
+		log.WithError(err).Error("failed to flush queued views")
 	}
-	if err := adminSrv.Close(); err != nil {
-		log.WithError(err).Error("admin server close error")
+}
+
+// newServer returns an HTTP server for handler with the configured
+// connection timeouts, so a slow or stalled client cannot hold a connection
+// (and the request's store work) open indefinitely.
+func newServer(cfg *config.Config, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		Addr:              addr,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
 	}
 }
@@ -0,0 +1,72 @@
+// Command webhook-receiver is a local stand-in for a webhook endpoint. It
+// checks the signature on every delivery and logs the event, so webhooks can
+// be tried out without a real receiver:
+//
+//	webhook-receiver -addr :8088 -secret "$WEBHOOK_SECRET"
+//
+// then create a webhook for http://localhost:8088/ with the same secret
+// through the admin API. -fail N answers the first N deliveries with 500 to
+// watch the retries in the delivery log.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dimitarkovachev/wedding/internal/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", ":8088", "listen address")
+	secret := flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "webhook secret (default $WEBHOOK_SECRET)")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "largest accepted clock difference of a signature")
+	fail := flag.Int64("fail", 0, "answer the first N deliveries with 500")
+	flag.Parse()
+
+	log.SetFormatter(&log.JSONFormatter{})
+	if *secret == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var failures atomic.Int64
+	failures.Store(*fail)
+	http.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		logger := log.WithFields(log.Fields{
+			"event":       r.Header.Get(webhook.EventHeader),
+			"delivery_id": r.Header.Get(webhook.DeliveryHeader),
+		})
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			logger.WithError(err).Warn("failed to read delivery")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := webhook.Verify(*secret, r.Header.Get(webhook.SignatureHeader), body, time.Now(), *tolerance); err != nil {
+			logger.WithError(err).Warn("rejected delivery")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failures.Add(-1) >= 0 {
+			logger.Info("failing delivery on purpose")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		logger.WithField("payload", json.RawMessage(body)).Info("received event")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.WithField("addr", *addr).Info("webhook receiver listening")
+	srv := &http.Server{Addr: *addr, ReadHeaderTimeout: 5 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		log.WithError(err).Fatal("webhook receiver failed")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -20,6 +22,7 @@ type Invite struct {
 	Additional      []string `json:"additional"`
 	IsAccepted      bool     `json:"isAccepted"`
 	IsOpened        bool     `json:"isOpened"`
+	Language        string   `json:"language"`
 }
 
 type InviteUpdate struct {
@@ -28,7 +31,16 @@ type InviteUpdate struct {
 }
 
 type ErrorResponse struct {
-	Message string `json:"message"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields"`
+}
+
+type FieldError struct {
+	Location   string `json:"location"`
+	Pointer    string `json:"pointer"`
+	Constraint string `json:"constraint"`
+	Message    string `json:"message"`
 }
 
 type HealthResponse struct {
@@ -66,6 +78,33 @@ func waitForHealthy(timeout time.Duration) bool {
 	return false
 }
 
+// waitForOpened polls the invite until it reports isOpened. Views are
+// written asynchronously, within VIEW_FLUSH_INTERVAL of the request.
+func waitForOpened(t *testing.T, inviteURL string) Invite {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(baseURL + inviteURL)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		var inv Invite
+		err = json.NewDecoder(resp.Body).Decode(&inv)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if inv.IsOpened {
+			return inv
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected isOpened=true after prior view")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 // --- Happy path ---
 
 func TestGetInvite(t *testing.T) {
@@ -136,22 +175,10 @@ func TestAcceptWithoutAdditionals(t *testing.T) {
 	}
 
 	// GET after PUT: accepted and opened
-	getResp, err := http.Get(baseURL + inviteURL)
-	if err != nil {
-		t.Fatalf("GET failed: %v", err)
-	}
-	defer getResp.Body.Close()
-
-	var inv Invite
-	if err := json.NewDecoder(getResp.Body).Decode(&inv); err != nil {
-		t.Fatalf("decode failed: %v", err)
-	}
+	inv := waitForOpened(t, inviteURL)
 	if !inv.IsAccepted {
 		t.Fatal("expected isAccepted=true after PUT")
 	}
-	if !inv.IsOpened {
-		t.Fatal("expected isOpened=true after prior view")
-	}
 }
 
 func TestAcceptWithAdditionals(t *testing.T) {
@@ -194,22 +221,10 @@ func TestAcceptWithAdditionals(t *testing.T) {
 	}
 
 	// GET after PUT: accepted, opened, with additionals
-	getResp, err := http.Get(baseURL + inviteURL)
-	if err != nil {
-		t.Fatalf("GET failed: %v", err)
-	}
-	defer getResp.Body.Close()
-
-	var inv Invite
-	if err := json.NewDecoder(getResp.Body).Decode(&inv); err != nil {
-		t.Fatalf("decode failed: %v", err)
-	}
+	inv := waitForOpened(t, inviteURL)
 	if !inv.IsAccepted {
 		t.Fatal("expected isAccepted=true")
 	}
-	if !inv.IsOpened {
-		t.Fatal("expected isOpened=true after previous views")
-	}
 	if len(inv.Additional) != 2 {
 		t.Fatalf("expected 2 additional, got %d", len(inv.Additional))
 	}
@@ -218,6 +233,53 @@ func TestAcceptWithAdditionals(t *testing.T) {
 	}
 }
 
+func TestPutIdempotencyKey(t *testing.T) {
+	// Reason: invite 005 is only used here, and the key must be new on every run
+	const inviteURL = "/invites/aaaa0000-0000-0000-0000-000000000005"
+	key := fmt.Sprintf("e2e-%d", time.Now().UnixNano())
+
+	put := func(update InviteUpdate) (*http.Response, []byte) {
+		t.Helper()
+		body, _ := json.Marshal(update)
+		req, _ := http.NewRequest(http.MethodPut, baseURL+inviteURL, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data
+	}
+
+	update := InviteUpdate{IsAccepted: true, Additional: []string{"Мила Николова"}}
+	first, firstBody := put(update)
+	if first.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", first.StatusCode, firstBody)
+	}
+
+	retry, retryBody := put(update)
+	if retry.StatusCode != http.StatusOK || !bytes.Equal(retryBody, firstBody) {
+		t.Fatalf("expected the first response replayed, got %d: %s", retry.StatusCode, retryBody)
+	}
+	if got := retry.Header.Get("Idempotent-Replayed"); got != "true" {
+		t.Fatalf("expected Idempotent-Replayed: true, got %q", got)
+	}
+
+	reused, reusedBody := put(InviteUpdate{IsAccepted: true})
+	if reused.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a reused key, got %d: %s", reused.StatusCode, reusedBody)
+	}
+	var errResp ErrorResponse
+	if err := json.Unmarshal(reusedBody, &errResp); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if errResp.Code != "idempotency_key_reused" {
+		t.Fatalf("expected code idempotency_key_reused, got %q", errResp.Code)
+	}
+}
+
 // --- Fault cases ---
 
 func TestGetNonExistentInvite(t *testing.T) {
@@ -232,6 +294,34 @@ func TestGetNonExistentInvite(t *testing.T) {
 	}
 }
 
+func TestNotFoundLocalized(t *testing.T) {
+	for lang, want := range map[string]string{
+		"bg": "поканата не е намерена",
+		"en": "invite not found",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, baseURL+"/invites/00000000-0000-0000-0000-000000000000", nil)
+		req.Header.Set("Accept-Language", lang)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+
+		var errResp ErrorResponse
+		err = json.NewDecoder(resp.Body).Decode(&errResp)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to decode error: %v", err)
+		}
+		if errResp.Message != want {
+			t.Fatalf("Accept-Language %s: expected %q, got %q", lang, want, errResp.Message)
+		}
+		if got := resp.Header.Get("Content-Language"); got != lang {
+			t.Fatalf("expected Content-Language %s, got %q", lang, got)
+		}
+	}
+}
+
 func TestPutInvalidNamesLatin(t *testing.T) {
 	body, _ := json.Marshal(InviteUpdate{
 		IsAccepted: true,
@@ -252,6 +342,17 @@ func TestPutInvalidNamesLatin(t *testing.T) {
 	if resp.StatusCode != http.StatusBadRequest {
 		t.Fatalf("expected 400 for Latin names, got %d", resp.StatusCode)
 	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if errResp.Code != "validation_failed" {
+		t.Fatalf("expected code validation_failed, got %q", errResp.Code)
+	}
+	if len(errResp.Fields) != 1 || errResp.Fields[0].Pointer != "/additional/0" {
+		t.Fatalf("expected one field error at /additional/0, got %+v", errResp.Fields)
+	}
 }
 
 func TestPutInvalidNamesNumbers(t *testing.T) {
@@ -297,6 +398,48 @@ func TestPutTooManyAdditionals(t *testing.T) {
 	if resp.StatusCode != http.StatusBadRequest {
 		t.Fatalf("expected 400 for too many additionals, got %d", resp.StatusCode)
 	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if errResp.Code != "too_many_guests" {
+		t.Fatalf("expected code too_many_guests, got %q", errResp.Code)
+	}
+	if len(errResp.Fields) != 1 || errResp.Fields[0].Pointer != "/additional/1" {
+		t.Fatalf("expected one field error at /additional/1, got %+v", errResp.Fields)
+	}
+}
+
+func TestPutDuplicateAdditional(t *testing.T) {
+	// Invite 001 already lists "Иван Петров" in people
+	body, _ := json.Marshal(InviteUpdate{
+		IsAccepted: true,
+		Additional: []string{"иван  петров"},
+	})
+
+	req, _ := http.NewRequest(http.MethodPut,
+		baseURL+"/invites/aaaa0000-0000-0000-0000-000000000001",
+		bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for duplicate guest, got %d", resp.StatusCode)
+	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if errResp.Code != "duplicate_guest" {
+		t.Fatalf("expected code duplicate_guest, got %q", errResp.Code)
+	}
 }
 
 func TestPutAcceptedFalse(t *testing.T) {
@@ -317,3 +460,33 @@ func TestPutAcceptedFalse(t *testing.T) {
 		t.Fatalf("expected 400 for accepted=false, got %d", resp.StatusCode)
 	}
 }
+
+// --- Guest RSVP page ---
+
+func TestGuestPage(t *testing.T) {
+	resp, err := http.Get(baseURL + "/i/aaaa0000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("expected text/html, got %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "Иван Петров") || !strings.Contains(string(body), `name="additional"`) {
+		t.Fatalf("expected page with the invite form, got %s", body)
+	}
+
+	resp, err = http.Get(baseURL + "/i/00000000-0000-0000-0000-000000000000")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown invite, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// defaultMinSimilarity is the lowest similarity reported when the request
+// does not set min_similarity.
+const defaultMinSimilarity = 0.85
+
+func (h *Handler) GetAdminDuplicates(c *gin.Context, params GetAdminDuplicatesParams) {
+	minSimilarity := defaultMinSimilarity
+	if params.MinSimilarity != nil {
+		minSimilarity = *params.MinSimilarity
+	}
+	if minSimilarity < 0 || minSimilarity > 1 {
+		constraint := "maximum"
+		if minSimilarity < 0 {
+			constraint = "minimum"
+		}
+		fields := []FieldError{{
+			Location:   Query,
+			Pointer:    "/min_similarity",
+			Constraint: constraint,
+			Message:    "min_similarity must be between 0 and 1",
+		}}
+		c.JSON(http.StatusBadRequest, Error{Code: ValidationFailed, Message: "invalid query parameter", Fields: &fields})
+		return
+	}
+
+	invites, err := h.store.GetAllInvites(c.Request.Context())
+	if err != nil {
+		storeError(c, err, "failed to get all invites")
+		return
+	}
+
+	c.JSON(http.StatusOK, DuplicatesReport{
+		MinSimilarity: minSimilarity,
+		Pairs:         findDuplicates(invites, minSimilarity),
+	})
+}
+
+// ParamErrorHandler answers parameter binding errors from the generated
+// wrappers with the standard Error body.
+func ParamErrorHandler(c *gin.Context, err error, status int) {
+	c.JSON(status, Error{Code: ValidationFailed, Message: err.Error()})
+}
+
+type guestEntry struct {
+	ref GuestRef
+	key string
+}
+
+// findDuplicates compares every guest name across all invites and returns
+// the pairs at least minSimilarity alike, most similar first.
+func findDuplicates(invites map[string]store.InviteRecord, minSimilarity float64) []DuplicatePair {
+	var entries []guestEntry
+	add := func(id string, field GuestRefField, list []string) {
+		for i, name := range list {
+			entries = append(entries, guestEntry{
+				ref: GuestRef{InviteId: id, Field: field, Index: i, Name: name},
+				key: names.Key(name),
+			})
+		}
+	}
+	for id, rec := range invites {
+		add(id, People, rec.People)
+		add(id, Additional, rec.Additional)
+	}
+	// Reason: map iteration is random; sort so pairs are always reported the
+	// same way round
+	sort.Slice(entries, func(i, j int) bool { return lessRef(entries[i].ref, entries[j].ref) })
+
+	pairs := []DuplicatePair{}
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			score := names.KeySimilarity(entries[i].key, entries[j].key)
+			if score < minSimilarity {
+				continue
+			}
+			pairs = append(pairs, DuplicatePair{
+				Similarity: math.Round(score*1000) / 1000,
+				First:      entries[i].ref,
+				Second:     entries[j].ref,
+			})
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Similarity > pairs[j].Similarity })
+	return pairs
+}
+
+func lessRef(a, b GuestRef) bool {
+	if a.InviteId != b.InviteId {
+		return a.InviteId < b.InviteId
+	}
+	if a.Field != b.Field {
+		// Reason: list invited people before their plus-ones
+		return a.Field == People
+	}
+	return a.Index < b.Index
+}
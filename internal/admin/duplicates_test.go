@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	invites := map[string]store.InviteRecord{
+		"a": {People: []string{"Иван Петров", "Мария Петрова"}, Additional: []string{"Георги Димитров"}},
+		"b": {People: []string{"иван  петров"}},
+		"c": {People: []string{"Петрова Мария"}, Additional: []string{"Георги Димитрова"}},
+		"d": {People: []string{"Елена Стоянова"}},
+	}
+
+	pairs := findDuplicates(invites, 0.9)
+	if len(pairs) != 3 {
+		t.Fatalf("expected 3 pairs, got %+v", pairs)
+	}
+
+	want := []struct {
+		first, second GuestRef
+		similarity    float64
+	}{
+		{GuestRef{InviteId: "a", Field: People, Index: 0, Name: "Иван Петров"}, GuestRef{InviteId: "b", Field: People, Index: 0, Name: "иван  петров"}, 1},
+		{GuestRef{InviteId: "a", Field: People, Index: 1, Name: "Мария Петрова"}, GuestRef{InviteId: "c", Field: People, Index: 0, Name: "Петрова Мария"}, 1},
+		{GuestRef{InviteId: "a", Field: Additional, Index: 0, Name: "Георги Димитров"}, GuestRef{InviteId: "c", Field: Additional, Index: 0, Name: "Георги Димитрова"}, 0.938},
+	}
+	for i, w := range want {
+		if pairs[i].First != w.first || pairs[i].Second != w.second || pairs[i].Similarity != w.similarity {
+			t.Fatalf("pair %d: expected %+v/%+v (%.3f), got %+v", i, w.first, w.second, w.similarity, pairs[i])
+		}
+	}
+}
+
+func TestHandler_GetAdminDuplicates(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reports/duplicates?min_similarity=0.5", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var report DuplicatesReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if report.MinSimilarity != 0.5 {
+		t.Fatalf("expected min_similarity 0.5, got %v", report.MinSimilarity)
+	}
+	// Reason: "Иван Петров" and "Мария Петрова" in the seeded invite share a surname
+	if len(report.Pairs) != 1 {
+		t.Fatalf("expected 1 pair, got %+v", report.Pairs)
+	}
+}
+
+func TestHandler_GetAdminDuplicates_InvalidThreshold(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	for _, query := range []string{"?min_similarity=1.5", "?min_similarity=-1", "?min_similarity=abc"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/reports/duplicates"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+}
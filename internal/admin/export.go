@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/logging"
+	"github.com/dimitarkovachev/wedding/internal/store"
+	"github.com/dimitarkovachev/wedding/internal/translit"
+)
+
+func (h *Handler) GetAdminGuestsExport(c *gin.Context, params GetAdminGuestsExportParams) {
+	invites, err := h.store.GetAllInvites(c.Request.Context())
+	if err != nil {
+		storeError(c, err, "failed to get all invites")
+		return
+	}
+
+	latin := params.Latin != nil && *params.Latin
+	body, err := guestsCSV(invites, latin)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("failed to write guests export")
+		c.JSON(http.StatusInternalServerError, Error{Code: InternalError, Message: "internal error"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="guests.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", body)
+}
+
+// guestsCSV writes one row per invited person and additional guest, ordered
+// by invite ID with people before their plus-ones.
+func guestsCSV(invites map[string]store.InviteRecord, latin bool) ([]byte, error) {
+	ids := make([]string, 0, len(invites))
+	for id := range invites {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"invite_id", "field", "index", "name"}
+	if latin {
+		header = append(header, "name_latin")
+	}
+	if err := w.Write(append(header, "accepted")); err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		rec := invites[id]
+		accepted := strconv.FormatBool(rec.Accepted)
+		for _, field := range []struct {
+			name GuestRefField
+			list []string
+		}{{People, rec.People}, {Additional, rec.Additional}} {
+			for i, name := range field.list {
+				row := []string{id, string(field.name), strconv.Itoa(i), name}
+				if latin {
+					row = append(row, translit.Bulgarian(name))
+				}
+				if err := w.Write(append(row, accepted)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func TestGuestsCSV(t *testing.T) {
+	invites := map[string]store.InviteRecord{
+		"b": {People: []string{"Юлия Цветкова"}, Accepted: true, Additional: []string{"Жечо Щерев"}},
+		"a": {People: []string{"Иван Петров"}},
+	}
+
+	tests := []struct {
+		name  string
+		latin bool
+		want  [][]string
+	}{
+		{
+			name: "cyrillic only",
+			want: [][]string{
+				{"invite_id", "field", "index", "name", "accepted"},
+				{"a", "people", "0", "Иван Петров", "false"},
+				{"b", "people", "0", "Юлия Цветкова", "true"},
+				{"b", "additional", "0", "Жечо Щерев", "true"},
+			},
+		},
+		{
+			name:  "with latin column",
+			latin: true,
+			want: [][]string{
+				{"invite_id", "field", "index", "name", "name_latin", "accepted"},
+				{"a", "people", "0", "Иван Петров", "Ivan Petrov", "false"},
+				{"b", "people", "0", "Юлия Цветкова", "Yulia Tsvetkova", "true"},
+				{"b", "additional", "0", "Жечо Щерев", "Zhecho Shterev", "true"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := guestsCSV(invites, tt.latin)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			got, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+			if err != nil {
+				t.Fatalf("failed to parse csv: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandler_GetAdminGuestsExport(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/exports/guests?latin=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("expected text/csv, got %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "Иван Петров,Ivan Petrov,false") {
+		t.Fatalf("expected transliterated row, got %s", w.Body.String())
+	}
+}
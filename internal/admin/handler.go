@@ -3,52 +3,103 @@ package admin
 import (
 	"context"
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	log "github.com/sirupsen/logrus"
 
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/names"
 	"github.com/dimitarkovachev/wedding/internal/store"
 )
 
 // AdminStore defines the store operations needed by the admin handler.
 type AdminStore interface {
 	GetAllInvites(ctx context.Context) (map[string]store.InviteRecord, error)
+	ListInvites(ctx context.Context, q store.ListQuery) (*store.ListPage, error)
+	GetInvite(ctx context.Context, id string) (*store.InviteRecord, error)
+	CreateInvite(ctx context.Context, id string, rec store.InviteRecord) error
+	EditInvite(ctx context.Context, id string, edit func(*store.InviteRecord) error) (*store.InviteRecord, error)
+	DeleteInvite(ctx context.Context, id string) error
 	ReplaceAllInvites(ctx context.Context, invites map[string]store.InviteRecord) error
+	store.WebhookStore
 }
 
 type Handler struct {
 	store AdminStore
+	rules names.Rules
 }
 
-func NewHandler(s AdminStore) *Handler {
-	return &Handler{store: s}
+// NewHandler creates a Handler that rejects guest names breaking rules.
+func NewHandler(s AdminStore, rules names.Rules) *Handler {
+	return &Handler{store: s, rules: rules}
 }
 
 var _ ServerInterface = (*Handler)(nil)
 
-func (h *Handler) GetAdminInvites(c *gin.Context) {
-	invites, err := h.store.GetAllInvites(c.Request.Context())
-	if err != nil {
-		log.WithError(err).Error("failed to get all invites")
-		c.JSON(http.StatusInternalServerError, Error{Message: "internal error"})
-		return
-	}
-
-	c.JSON(http.StatusOK, invites)
-}
-
 func (h *Handler) PutAdminInvites(c *gin.Context) {
 	var invites map[string]store.InviteRecord
 	if err := c.ShouldBindJSON(&invites); err != nil {
-		c.JSON(http.StatusBadRequest, Error{Message: "invalid request body"})
+		c.JSON(http.StatusBadRequest, Error{Code: InvalidBody, Message: "invalid request body"})
+		return
+	}
+
+	if fields := h.validateInvites(invites); len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, Error{
+			Code:    ValidationFailed,
+			Message: "invalid invites",
+			Fields:  &fields,
+		})
 		return
 	}
 
 	if err := h.store.ReplaceAllInvites(c.Request.Context(), invites); err != nil {
-		log.WithError(err).Error("failed to replace invites")
-		c.JSON(http.StatusInternalServerError, Error{Message: "internal error"})
+		storeError(c, err, "failed to replace invites")
 		return
 	}
 
 	c.JSON(http.StatusOK, invites)
 }
+
+// validateInvites reports unsupported languages and additional guest names
+// breaking the name rules, ordered by pointer.
+func (h *Handler) validateInvites(invites map[string]store.InviteRecord) []FieldError {
+	var fields []FieldError
+	for id, rec := range invites {
+		fields = append(fields, languageFields("/"+id+"/language", rec.Language)...)
+		fields = append(fields, h.nameFields("/"+id+"/additional", rec.Additional)...)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Pointer < fields[j].Pointer })
+	return fields
+}
+
+// languageFields reports lang at pointer unless it is empty or supported.
+func languageFields(pointer, lang string) []FieldError {
+	if lang == "" {
+		return nil
+	}
+	if _, ok := i18n.Parse(lang); ok {
+		return nil
+	}
+	return []FieldError{{
+		Location:   Body,
+		Pointer:    pointer,
+		Constraint: "enum",
+		Message:    "language must be one of bg, en",
+	}}
+}
+
+// nameFields reports the additional guest names under pointer that break
+// the name rules.
+func (h *Handler) nameFields(pointer string, additional []string) []FieldError {
+	var fields []FieldError
+	for _, v := range h.rules.CheckAll(additional) {
+		fields = append(fields, FieldError{
+			Location:   Body,
+			Pointer:    pointer + "/" + strconv.Itoa(v.Index),
+			Constraint: v.Constraint,
+			Message:    "name violates " + v.Constraint + " rule " + h.rules.Pattern(),
+		})
+	}
+	return fields
+}
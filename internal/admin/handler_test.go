@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"path/filepath"
 	"testing"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
 
+	"github.com/dimitarkovachev/wedding/internal/middleware"
+	"github.com/dimitarkovachev/wedding/internal/names"
 	"github.com/dimitarkovachev/wedding/internal/store"
 )
 
@@ -17,17 +19,40 @@ func init() {
 	gin.SetMode(gin.TestMode)
 }
 
-func setupAdminRouter(t *testing.T) *gin.Engine {
+// testSpec returns the embedded spec with rules applied.
+func testSpec(t *testing.T, rules names.Rules) *openapi3.T {
 	t.Helper()
 
-	dbPath := filepath.Join(t.TempDir(), "test.db")
-	s, err := store.NewBBoltStore(dbPath)
+	spec, err := GetSwagger()
 	if err != nil {
-		t.Fatalf("failed to create store: %v", err)
+		t.Fatalf("failed to load embedded spec: %v", err)
+	}
+	if err := rules.ApplyToSpec(spec); err != nil {
+		t.Fatalf("failed to apply name rules: %v", err)
 	}
-	t.Cleanup(func() { s.Close() })
+	return spec
+}
+
+// newTestEngine returns an engine that fails any response violating spec, so
+// every handler test also checks the response schema.
+func newTestEngine(t *testing.T, spec *openapi3.T) *gin.Engine {
+	t.Helper()
 
-	err = s.Seed(map[string]store.InviteRecord{
+	mw, err := middleware.NewOpenAPIResponseValidator(spec, middleware.ResponseValidationFail)
+	if err != nil {
+		t.Fatalf("failed to create response validator: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(mw)
+	return r
+}
+
+func setupAdminRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	s := store.NewMemoryStore()
+	err := s.Seed(t.Context(), map[string]store.InviteRecord{
 		"550e8400-e29b-41d4-a716-446655440000": {
 			People:          []string{"Иван Петров", "Мария Петрова"},
 			AdditionalCount: 2,
@@ -38,12 +63,32 @@ func setupAdminRouter(t *testing.T) *gin.Engine {
 		t.Fatalf("failed to seed: %v", err)
 	}
 
-	h := NewHandler(s)
-	r := gin.New()
-	RegisterHandlers(r, h)
+	rules := names.DefaultRules()
+	h := NewHandler(s, rules)
+	r := newTestEngine(t, testSpec(t, rules))
+	RegisterHandlersWithOptions(r, h, GinServerOptions{ErrorHandler: ParamErrorHandler})
 	return r
 }
 
+// byID indexes a page's invites by ID.
+func (p invitePage) byID() map[string]store.InviteRecord {
+	invites := make(map[string]store.InviteRecord, len(p.Items))
+	for _, e := range p.Items {
+		invites[e.ID] = e.Invite
+	}
+	return invites
+}
+
+func decodePage(t *testing.T, w *httptest.ResponseRecorder) invitePage {
+	t.Helper()
+
+	var page invitePage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	return page
+}
+
 func TestHandler_GetAdminInvites_Expected(t *testing.T) {
 	r := setupAdminRouter(t)
 
@@ -55,10 +100,7 @@ func TestHandler_GetAdminInvites_Expected(t *testing.T) {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
 	}
 
-	var invites map[string]store.InviteRecord
-	if err := json.NewDecoder(w.Body).Decode(&invites); err != nil {
-		t.Fatalf("failed to decode: %v", err)
-	}
+	invites := decodePage(t, w).byID()
 	if len(invites) != 1 {
 		t.Fatalf("expected 1 invite, got %d", len(invites))
 	}
@@ -72,16 +114,12 @@ func TestHandler_GetAdminInvites_Expected(t *testing.T) {
 }
 
 func TestHandler_GetAdminInvites_EmptyBucket(t *testing.T) {
-	dbPath := filepath.Join(t.TempDir(), "test.db")
-	s, err := store.NewBBoltStore(dbPath)
-	if err != nil {
-		t.Fatalf("failed to create store: %v", err)
-	}
-	t.Cleanup(func() { s.Close() })
+	s := store.NewMemoryStore()
 
-	h := NewHandler(s)
-	r := gin.New()
-	RegisterHandlers(r, h)
+	rules := names.DefaultRules()
+	h := NewHandler(s, rules)
+	r := newTestEngine(t, testSpec(t, rules))
+	RegisterHandlersWithOptions(r, h, GinServerOptions{ErrorHandler: ParamErrorHandler})
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/admin/invites", nil)
@@ -91,10 +129,7 @@ func TestHandler_GetAdminInvites_EmptyBucket(t *testing.T) {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
 	}
 
-	var invites map[string]store.InviteRecord
-	if err := json.NewDecoder(w.Body).Decode(&invites); err != nil {
-		t.Fatalf("failed to decode: %v", err)
-	}
+	invites := decodePage(t, w).byID()
 	if len(invites) != 0 {
 		t.Fatalf("expected 0 invites, got %d", len(invites))
 	}
@@ -121,10 +156,7 @@ func TestHandler_PutAdminInvites_Expected(t *testing.T) {
 	req = httptest.NewRequest(http.MethodGet, "/admin/invites", nil)
 	r.ServeHTTP(w, req)
 
-	var invites map[string]store.InviteRecord
-	if err := json.NewDecoder(w.Body).Decode(&invites); err != nil {
-		t.Fatalf("failed to decode: %v", err)
-	}
+	invites := decodePage(t, w).byID()
 	if len(invites) != 1 {
 		t.Fatalf("expected 1 invite after replace, got %d", len(invites))
 	}
@@ -149,6 +181,53 @@ func TestHandler_PutAdminInvites_InvalidBody(t *testing.T) {
 	}
 }
 
+func TestHandler_PutAdminInvites_UnsupportedLanguage(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	body, _ := json.Marshal(map[string]store.InviteRecord{
+		"bbb-001": {People: []string{"Тест"}, Language: "en"},
+		"bbb-002": {People: []string{"Тест"}, Language: "fr"},
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/admin/invites", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp Error
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if resp.Fields == nil || len(*resp.Fields) != 1 || (*resp.Fields)[0].Pointer != "/bbb-002/language" {
+		t.Fatalf("expected one field error at /bbb-002/language, got %+v", resp.Fields)
+	}
+}
+
+func TestHandler_PutAdminInvites_InvalidNames(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	body, _ := json.Marshal(map[string]store.InviteRecord{
+		"bbb-001": {People: []string{"Тест"}, AdditionalCount: 2, Additional: []string{"Иван", "John"}},
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/admin/invites", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp Error
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if resp.Fields == nil || len(*resp.Fields) != 1 || (*resp.Fields)[0].Pointer != "/bbb-001/additional/1" {
+		t.Fatalf("expected one field error at /bbb-001/additional/1, got %+v", resp.Fields)
+	}
+}
+
 func TestHandler_PutAdminInvites_EmptyMap(t *testing.T) {
 	r := setupAdminRouter(t)
 
@@ -167,10 +246,7 @@ func TestHandler_PutAdminInvites_EmptyMap(t *testing.T) {
 	req = httptest.NewRequest(http.MethodGet, "/admin/invites", nil)
 	r.ServeHTTP(w, req)
 
-	var invites map[string]store.InviteRecord
-	if err := json.NewDecoder(w.Body).Decode(&invites); err != nil {
-		t.Fatalf("failed to decode: %v", err)
-	}
+	invites := decodePage(t, w).byID()
 	if len(invites) != 0 {
 		t.Fatalf("expected 0 invites after empty replace, got %d", len(invites))
 	}
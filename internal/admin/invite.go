@@ -0,0 +1,192 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/dimitarkovachev/wedding/internal/logging"
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func (h *Handler) CreateAdminInvite(c *gin.Context) {
+	in, ok := h.bindInput(c)
+	if !ok {
+		return
+	}
+
+	var rec store.InviteRecord
+	applyInput(&rec, in, time.Now().UTC())
+	id := uuid.NewString()
+	if err := h.store.CreateInvite(c.Request.Context(), id, rec); err != nil {
+		storeError(c, err, "failed to create invite")
+		return
+	}
+
+	c.Header("Location", "/admin/invites/"+id)
+	c.JSON(http.StatusCreated, inviteEntry{ID: id, Status: rec.Status(), Invite: rec})
+}
+
+func (h *Handler) GetAdminInvite(c *gin.Context, id string) {
+	rec, err := h.store.GetInvite(c.Request.Context(), id)
+	if err != nil {
+		storeError(c, err, "failed to get invite")
+		return
+	}
+
+	c.JSON(http.StatusOK, inviteEntry{ID: id, Status: rec.Status(), Invite: *rec})
+}
+
+func (h *Handler) PutAdminInvite(c *gin.Context, id string) {
+	in, ok := h.bindInput(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	rec, err := h.store.EditInvite(c.Request.Context(), id, func(r *store.InviteRecord) error {
+		applyInput(r, in, now)
+		return nil
+	})
+	if err != nil {
+		storeError(c, err, "failed to update invite")
+		return
+	}
+
+	c.JSON(http.StatusOK, inviteEntry{ID: id, Status: rec.Status(), Invite: *rec})
+}
+
+func (h *Handler) DeleteAdminInvite(c *gin.Context, id string) {
+	if err := h.store.DeleteInvite(c.Request.Context(), id); err != nil {
+		storeError(c, err, "failed to delete invite")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetAdminNameRules(c *gin.Context) {
+	scripts := h.rules.Scripts
+	if scripts == nil {
+		scripts = []string{}
+	}
+	c.JSON(http.StatusOK, NameRules{
+		Pattern:    h.rules.Pattern(),
+		Scripts:    scripts,
+		ExtraChars: h.rules.ExtraChars,
+		MaxLength:  h.rules.MaxLength,
+	})
+}
+
+// storeError writes the response for a store error: the sentinel errors and
+// an expired request context get their own status and code, anything else
+// is logged with msg and reported as an internal error without its text.
+func storeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, Error{Code: NotFound, Message: "invite not found"})
+	case errors.Is(err, store.ErrInviteExists):
+		c.JSON(http.StatusConflict, Error{Code: InviteExists, Message: "invite already exists"})
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		logging.FromContext(c.Request.Context()).WithError(err).Warn(msg)
+		c.JSON(http.StatusServiceUnavailable, Error{Code: Timeout, Message: "request timed out"})
+	default:
+		logging.FromContext(c.Request.Context()).WithError(err).Error(msg)
+		c.JSON(http.StatusInternalServerError, Error{Code: InternalError, Message: "internal error"})
+	}
+}
+
+// bindInput decodes and validates an InviteInput body, writing the 400
+// response itself when it is rejected. Names are normalized first, so they
+// are checked as they will be stored.
+func (h *Handler) bindInput(c *gin.Context) (InviteInput, bool) {
+	var in InviteInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, Error{Code: InvalidBody, Message: "invalid request body"})
+		return in, false
+	}
+
+	for i, p := range in.People {
+		in.People[i] = names.Normalize(p)
+	}
+	if in.Additional != nil {
+		for i, a := range *in.Additional {
+			(*in.Additional)[i] = names.Normalize(a)
+		}
+	}
+
+	if fields := h.inputFields(in); len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, Error{Code: ValidationFailed, Message: "invalid invite", Fields: &fields})
+		return in, false
+	}
+	return in, true
+}
+
+// inputFields checks in against the InviteInput schema and the name rules.
+func (h *Handler) inputFields(in InviteInput) []FieldError {
+	var fields []FieldError
+	invalid := func(pointer, constraint, message string) {
+		fields = append(fields, FieldError{Location: Body, Pointer: pointer, Constraint: constraint, Message: message})
+	}
+
+	if len(in.People) == 0 {
+		invalid("/people", "minItems", "at least one person is required")
+	}
+	for i, p := range in.People {
+		if p == "" {
+			invalid("/people/"+strconv.Itoa(i), "minLength", "name must not be empty")
+		}
+	}
+	if in.AdditionalCount < 0 {
+		invalid("/additional_count", "minimum", "additional_count must not be negative")
+	}
+	if in.Additional != nil {
+		if len(*in.Additional) > in.AdditionalCount && in.AdditionalCount >= 0 {
+			invalid("/additional/"+strconv.Itoa(in.AdditionalCount), "maxAdditional",
+				"at most "+strconv.Itoa(in.AdditionalCount)+" additional guests are allowed")
+		}
+		fields = append(fields, h.nameFields("/additional", *in.Additional)...)
+	}
+	switch in.Rsvp {
+	case InviteInputRsvpNone, InviteInputRsvpAccepted, InviteInputRsvpDeclined:
+	default:
+		invalid("/rsvp", "enum", "rsvp must be one of none, accepted, declined")
+	}
+	if in.Language != nil {
+		fields = append(fields, languageFields("/language", string(*in.Language))...)
+	}
+	return fields
+}
+
+// applyInput copies the editable fields onto rec, keeping its view history.
+// accepted_at is stamped with now only when the invite becomes accepted.
+func applyInput(rec *store.InviteRecord, in InviteInput, now time.Time) {
+	rec.People = in.People
+	rec.AdditionalCount = in.AdditionalCount
+	rec.Additional = nil
+	if in.Additional != nil && len(*in.Additional) > 0 {
+		rec.Additional = *in.Additional
+	}
+	rec.Language = ""
+	if in.Language != nil {
+		rec.Language = string(*in.Language)
+	}
+
+	switch in.Rsvp {
+	case InviteInputRsvpAccepted:
+		if !rec.Accepted || rec.AcceptedAt == nil {
+			rec.AcceptedAt = &now
+		}
+		rec.Accepted, rec.Declined = true, false
+	case InviteInputRsvpDeclined:
+		rec.Accepted, rec.Declined, rec.AcceptedAt = false, true, nil
+	default:
+		rec.Accepted, rec.Declined, rec.AcceptedAt = false, false, nil
+	}
+}
@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+const seededID = "550e8400-e29b-41d4-a716-446655440000"
+
+func doJSON(t *testing.T, r *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+	}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeEntry(t *testing.T, w *httptest.ResponseRecorder) inviteEntry {
+	t.Helper()
+
+	var e inviteEntry
+	if err := json.NewDecoder(w.Body).Decode(&e); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	return e
+}
+
+func TestHandler_AdminInvite_Lifecycle(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	w := doJSON(t, r, http.MethodPost, "/admin/invites", map[string]any{
+		"people":           []string{"  елена  стоянова "},
+		"additional_count": 1,
+		"rsvp":             "none",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	created := decodeEntry(t, w)
+	if w.Header().Get("Location") != "/admin/invites/"+created.ID {
+		t.Fatalf("expected Location for %s, got %q", created.ID, w.Header().Get("Location"))
+	}
+	if created.Invite.People[0] != "Елена Стоянова" {
+		t.Fatalf("expected normalized name, got %q", created.Invite.People[0])
+	}
+
+	w = doJSON(t, r, http.MethodPut, "/admin/invites/"+created.ID, map[string]any{
+		"people":           []string{"Елена Стоянова"},
+		"additional_count": 1,
+		"additional":       []string{"Петър Стоянов"},
+		"rsvp":             "accepted",
+		"language":         "en",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	updated := decodeEntry(t, w)
+	if updated.Status != "accepted" || updated.Invite.AcceptedAt == nil || updated.Invite.Language != "en" {
+		t.Fatalf("expected accepted invite in en, got %+v", updated)
+	}
+
+	w = doJSON(t, r, http.MethodPut, "/admin/invites/"+created.ID, map[string]any{
+		"people":           []string{"Елена Стоянова"},
+		"additional_count": 1,
+		"rsvp":             "declined",
+	})
+	declined := decodeEntry(t, w)
+	if declined.Status != "declined" || declined.Invite.AcceptedAt != nil || declined.Invite.Accepted {
+		t.Fatalf("expected declined invite without accepted_at, got %+v", declined)
+	}
+
+	w = doJSON(t, r, http.MethodGet, "/admin/invites/"+created.ID, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := decodeEntry(t, w); got.Invite.Views.Browser.Count+got.Invite.Views.Bot.Count != 0 {
+		t.Fatalf("expected admin GET not to record a view, got %+v", got.Invite.Views)
+	}
+
+	w = doJSON(t, r, http.MethodDelete, "/admin/invites/"+created.ID, nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w = doJSON(t, r, method, "/admin/invites/"+created.ID, nil)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%s after delete: expected 404, got %d", method, w.Code)
+		}
+	}
+}
+
+func TestHandler_PutAdminInvite_NotFound(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	w := doJSON(t, r, http.MethodPut, "/admin/invites/missing", map[string]any{
+		"people": []string{"Тест"}, "additional_count": 0, "rsvp": "none",
+	})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// failingStore answers every single-invite call with err.
+type failingStore struct {
+	*store.MemoryStore
+	err error
+}
+
+func (s failingStore) GetInvite(context.Context, string) (*store.InviteRecord, error) {
+	return nil, s.err
+}
+
+func (s failingStore) CreateInvite(context.Context, string, store.InviteRecord) error { return s.err }
+
+func (s failingStore) EditInvite(context.Context, string, func(*store.InviteRecord) error) (*store.InviteRecord, error) {
+	return nil, s.err
+}
+
+func (s failingStore) DeleteInvite(context.Context, string) error { return s.err }
+
+func TestHandler_AdminInvite_StoreErrors(t *testing.T) {
+	input := map[string]any{"people": []string{"Тест"}, "additional_count": 0, "rsvp": "none"}
+	requests := []struct {
+		method, path string
+		body         any
+	}{
+		{http.MethodPost, "/admin/invites", input},
+		{http.MethodGet, "/admin/invites/" + seededID, nil},
+		{http.MethodPut, "/admin/invites/" + seededID, input},
+		{http.MethodDelete, "/admin/invites/" + seededID, nil},
+	}
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   ErrorCode
+	}{
+		{"not found", fmt.Errorf("getting invite: %w", store.ErrNotFound), http.StatusNotFound, NotFound},
+		{"exists", fmt.Errorf("creating invite: %w", store.ErrInviteExists), http.StatusConflict, InviteExists},
+		{"storage", fmt.Errorf("reading invite: %w: disk on fire", store.ErrStorage), http.StatusInternalServerError, InternalError},
+		{"timeout", fmt.Errorf("reading invite: %w", context.DeadlineExceeded), http.StatusServiceUnavailable, Timeout},
+	}
+	for _, tt := range tests {
+		rules := names.DefaultRules()
+		r := newTestEngine(t, testSpec(t, rules))
+		RegisterHandlers(r, NewHandler(failingStore{store.NewMemoryStore(), tt.err}, rules))
+
+		for _, req := range requests {
+			// Reason: only creating can collide with an existing ID
+			if tt.code == InviteExists && req.method != http.MethodPost {
+				continue
+			}
+			w := doJSON(t, r, req.method, req.path, req.body)
+			if w.Code != tt.status {
+				t.Fatalf("%s %s %s: expected %d, got %d: %s", tt.name, req.method, req.path, tt.status, w.Code, w.Body.String())
+			}
+			var resp Error
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("%s: failed to decode: %v", tt.name, err)
+			}
+			if resp.Code != tt.code || strings.Contains(resp.Message, "disk") {
+				t.Fatalf("%s %s: expected code %s without internal text, got %+v", tt.name, req.method, tt.code, resp)
+			}
+		}
+	}
+}
+
+func TestHandler_PutAdminInvite_Invalid(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	tests := []struct {
+		name    string
+		body    map[string]any
+		pointer string
+	}{
+		{"no people", map[string]any{"people": []string{}, "additional_count": 0, "rsvp": "none"}, "/people"},
+		{"blank person", map[string]any{"people": []string{" "}, "additional_count": 0, "rsvp": "none"}, "/people/0"},
+		{"negative count", map[string]any{"people": []string{"Тест"}, "additional_count": -1, "rsvp": "none"}, "/additional_count"},
+		{"too many", map[string]any{"people": []string{"Тест"}, "additional_count": 1, "additional": []string{"Иван", "Петър"}, "rsvp": "none"}, "/additional/1"},
+		{"latin name", map[string]any{"people": []string{"Тест"}, "additional_count": 1, "additional": []string{"John"}, "rsvp": "none"}, "/additional/0"},
+		{"bad rsvp", map[string]any{"people": []string{"Тест"}, "additional_count": 0, "rsvp": "maybe"}, "/rsvp"},
+		{"bad language", map[string]any{"people": []string{"Тест"}, "additional_count": 0, "rsvp": "none", "language": "fr"}, "/language"},
+	}
+	for _, tt := range tests {
+		w := doJSON(t, r, http.MethodPut, "/admin/invites/"+seededID, tt.body)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", tt.name, w.Code, w.Body.String())
+		}
+		var resp Error
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode: %v", tt.name, err)
+		}
+		if resp.Fields == nil || len(*resp.Fields) != 1 || (*resp.Fields)[0].Pointer != tt.pointer {
+			t.Fatalf("%s: expected one field error at %s, got %+v", tt.name, tt.pointer, resp.Fields)
+		}
+	}
+}
+
+func TestHandler_GetAdminNameRules(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	w := doJSON(t, r, http.MethodGet, "/admin/name-rules", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rules NameRules
+	if err := json.NewDecoder(w.Body).Decode(&rules); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if rules.Pattern != `^[\p{Cyrillic} \-]+$` || rules.MaxLength != 100 || len(rules.Scripts) != 1 {
+		t.Fatalf("expected default rules, got %+v", rules)
+	}
+}
@@ -0,0 +1,174 @@
+package admin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+	"github.com/dimitarkovachev/wedding/internal/translit"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// inviteEntry and invitePage mirror the InviteEntry and InvitePage schemas
+// but carry store records, as the admin API has always served them.
+type inviteEntry struct {
+	ID     string             `json:"id"`
+	Status store.Status       `json:"status"`
+	Invite store.InviteRecord `json:"invite"`
+}
+
+type invitePage struct {
+	Items      []inviteEntry `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+func (h *Handler) GetAdminInvites(c *gin.Context, params GetAdminInvitesParams) {
+	q, fields := listQuery(params)
+	if len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, Error{Code: ValidationFailed, Message: "invalid query parameter", Fields: &fields})
+		return
+	}
+
+	page, err := h.store.ListInvites(c.Request.Context(), q)
+	if err != nil {
+		storeError(c, err, "failed to list invites")
+		return
+	}
+
+	resp := invitePage{Items: make([]inviteEntry, 0, len(page.Invites))}
+	for _, e := range page.Invites {
+		resp.Items = append(resp.Items, inviteEntry{ID: e.ID, Status: e.Record.Status(), Invite: e.Record})
+	}
+	if page.Next != nil {
+		resp.NextCursor = encodeCursor(q, *page.Next)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// listQuery turns the query parameters into a store query, reporting every
+// parameter out of range.
+func listQuery(params GetAdminInvitesParams) (store.ListQuery, []FieldError) {
+	q := store.ListQuery{Sort: store.SortByID, Limit: defaultPageSize}
+	var fields []FieldError
+	invalid := func(name, constraint, message string) {
+		fields = append(fields, FieldError{Location: Query, Pointer: "/" + name, Constraint: constraint, Message: message})
+	}
+
+	if params.Sort != nil {
+		switch *params.Sort {
+		case Id, Name, AcceptedAt, LastViewed:
+			q.Sort = store.SortField(*params.Sort)
+		default:
+			invalid("sort", "enum", "sort must be one of id, name, accepted_at, last_viewed")
+		}
+	}
+	if params.Order != nil {
+		switch *params.Order {
+		case Asc:
+		case Desc:
+			q.Desc = true
+		default:
+			invalid("order", "enum", "order must be asc or desc")
+		}
+	}
+	if params.Limit != nil {
+		switch {
+		case *params.Limit < 1:
+			invalid("limit", "minimum", "limit must be at least 1")
+		case *params.Limit > maxPageSize:
+			invalid("limit", "maximum", "limit must be at most 500")
+		default:
+			q.Limit = *params.Limit
+		}
+	}
+
+	var status store.Status
+	if params.Status != nil {
+		switch *params.Status {
+		case InviteStatusPending, InviteStatusOpened, InviteStatusAccepted, InviteStatusDeclined:
+			status = store.Status(*params.Status)
+		default:
+			invalid("status", "enum", "status must be one of pending, opened, accepted, declined")
+		}
+	}
+	var search *foldedName
+	if params.Q != nil && strings.TrimSpace(*params.Q) != "" {
+		f := foldName(*params.Q)
+		search = &f
+	}
+	if status != "" || search != nil {
+		q.Filter = func(_ string, r store.InviteRecord) bool {
+			if status != "" && r.Status() != status {
+				return false
+			}
+			return search == nil || anyNameMatches(r.People, *search) || anyNameMatches(r.Additional, *search)
+		}
+	}
+
+	if params.Cursor != nil && *params.Cursor != "" {
+		after, ok := decodeCursor(q, *params.Cursor)
+		if !ok {
+			invalid("cursor", "format", "cursor is invalid or was issued for a different sort and order")
+		}
+		q.After = after
+	}
+	return q, fields
+}
+
+// pageCursor is the JSON inside an opaque next_cursor. It records the sort
+// and order so a cursor cannot be replayed against a different ordering.
+type pageCursor struct {
+	Sort  store.SortField `json:"s"`
+	Desc  bool            `json:"d,omitempty"`
+	Value string          `json:"v"`
+	ID    string          `json:"i"`
+}
+
+func encodeCursor(q store.ListQuery, c store.Cursor) string {
+	data, _ := json.Marshal(pageCursor{Sort: q.Sort, Desc: q.Desc, Value: c.Value, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(q store.ListQuery, s string) (*store.Cursor, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	var pc pageCursor
+	if err := json.Unmarshal(data, &pc); err != nil || pc.Sort != q.Sort || pc.Desc != q.Desc {
+		return nil, false
+	}
+	return &store.Cursor{Value: pc.Value, ID: pc.ID}, true
+}
+
+// foldedName holds a name lower-cased as written and transliterated.
+type foldedName struct {
+	native string
+	latin  string
+}
+
+func foldName(name string) foldedName {
+	native := strings.ToLower(names.Normalize(name))
+	return foldedName{native: native, latin: strings.ToLower(translit.Bulgarian(native))}
+}
+
+func anyNameMatches(list []string, query foldedName) bool {
+	for _, name := range list {
+		n := foldName(name)
+		// Reason: compare Cyrillic directly as well, since a word-final "ия"
+		// transliterates differently once more letters follow it
+		if strings.Contains(n.native, query.native) || strings.Contains(n.latin, query.latin) {
+			return true
+		}
+	}
+	return false
+}
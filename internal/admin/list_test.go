@@ -0,0 +1,153 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// setupListRouter replaces the seeded invite with a set covering every status.
+func setupListRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	r := setupAdminRouter(t)
+	accepted := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	viewed := store.Views{Browser: store.ViewStats{Count: 1, First: &accepted, Last: &accepted}}
+	body, _ := json.Marshal(map[string]store.InviteRecord{
+		"a": {People: []string{"Яна Иванова"}, Accepted: true, AcceptedAt: &accepted, Views: viewed},
+		"b": {People: []string{"Борис Стоев"}},
+		"c": {People: []string{"Атанас Колев"}, Views: viewed},
+		"d": {People: []string{"Виктория Ненова"}, Declined: true},
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/admin/invites", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	return r
+}
+
+func TestHandler_GetAdminInvites_Pages(t *testing.T) {
+	r := setupListRouter(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"limit=3", []string{"a", "b", "c", "d"}},
+		{"sort=name&limit=1", []string{"c", "b", "d", "a"}},
+		{"sort=name&order=desc&limit=2", []string{"a", "d", "b", "c"}},
+		{"sort=accepted_at&limit=2", []string{"a", "b", "c", "d"}},
+		{"status=opened", []string{"c"}},
+		{"status=declined", []string{"d"}},
+		{"status=pending&q=boris", []string{"b"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatalf("%s: expected pagination to end", tt.query)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/invites?"+tt.query+cursor, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected 200, got %d: %s", tt.query, w.Code, w.Body.String())
+			}
+			page := decodePage(t, w)
+			for _, e := range page.Items {
+				if e.Status != e.Invite.Status() {
+					t.Fatalf("%s: expected status %s for %s, got %s", tt.query, e.Invite.Status(), e.ID, e.Status)
+				}
+				got = append(got, e.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = "&cursor=" + url.QueryEscape(page.NextCursor)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestHandler_GetAdminInvites_InvalidParams(t *testing.T) {
+	r := setupListRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/invites?sort=name&limit=1", nil))
+	cursor := decodePage(t, w).NextCursor
+	if cursor == "" {
+		t.Fatal("expected a next cursor")
+	}
+
+	tests := []struct {
+		query   string
+		pointer string
+	}{
+		{"status=lost", "/status"},
+		{"sort=age", "/sort"},
+		{"order=up", "/order"},
+		{"limit=0", "/limit"},
+		{"limit=501", "/limit"},
+		{"cursor=!!", "/cursor"},
+		{"sort=id&cursor=" + url.QueryEscape(cursor), "/cursor"},
+		{"sort=name&order=desc&cursor=" + url.QueryEscape(cursor), "/cursor"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/invites?"+tt.query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", tt.query, w.Code, w.Body.String())
+		}
+		var resp Error
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode: %v", tt.query, err)
+		}
+		if resp.Fields == nil || len(*resp.Fields) != 1 || (*resp.Fields)[0].Pointer != tt.pointer {
+			t.Fatalf("%s: expected one field error at %s, got %+v", tt.query, tt.pointer, resp.Fields)
+		}
+	}
+}
+
+func TestHandler_GetAdminInvites_Search(t *testing.T) {
+	r := setupAdminRouter(t)
+
+	tests := []struct {
+		q     string
+		found bool
+	}{
+		{"Иван", true},
+		{"иван", true},
+		{"ivan", true},
+		{"PETROVA", true},
+		{"мария петрова", true},
+		{"maria", true},
+		{"Елена", false},
+		{"elena", false},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/invites?q="+url.QueryEscape(tt.q), nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected 200, got %d: %s", tt.q, w.Code, w.Body.String())
+		}
+
+		invites := decodePage(t, w).Items
+		if found := len(invites) == 1; found != tt.found {
+			t.Fatalf("%q: expected found=%v, got %d invites", tt.q, tt.found, len(invites))
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"net/url"
 	"path"
 	"strings"
@@ -15,37 +16,425 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
+	"github.com/oapi-codegen/runtime"
 )
 
+// Defines values for ErrorCode.
+const (
+	InternalError    ErrorCode = "internal_error"
+	InvalidBody      ErrorCode = "invalid_body"
+	InviteExists     ErrorCode = "invite_exists"
+	NotFound         ErrorCode = "not_found"
+	Timeout          ErrorCode = "timeout"
+	ValidationFailed ErrorCode = "validation_failed"
+)
+
+// Defines values for FieldErrorLocation.
+const (
+	Body   FieldErrorLocation = "body"
+	Header FieldErrorLocation = "header"
+	Path   FieldErrorLocation = "path"
+	Query  FieldErrorLocation = "query"
+)
+
+// Defines values for GuestRefField.
+const (
+	Additional GuestRefField = "additional"
+	People     GuestRefField = "people"
+)
+
+// Defines values for InviteInputLanguage.
+const (
+	InviteInputLanguageBg InviteInputLanguage = "bg"
+	InviteInputLanguageEn InviteInputLanguage = "en"
+)
+
+// Defines values for InviteInputRsvp.
+const (
+	InviteInputRsvpAccepted InviteInputRsvp = "accepted"
+	InviteInputRsvpDeclined InviteInputRsvp = "declined"
+	InviteInputRsvpNone     InviteInputRsvp = "none"
+)
+
+// Defines values for InviteRecordLanguage.
+const (
+	InviteRecordLanguageBg InviteRecordLanguage = "bg"
+	InviteRecordLanguageEn InviteRecordLanguage = "en"
+)
+
+// Defines values for InviteStatus.
+const (
+	InviteStatusAccepted InviteStatus = "accepted"
+	InviteStatusDeclined InviteStatus = "declined"
+	InviteStatusOpened   InviteStatus = "opened"
+	InviteStatusPending  InviteStatus = "pending"
+)
+
+// Defines values for WebhookDeliveryState.
+const (
+	Delivered WebhookDeliveryState = "delivered"
+	Failed    WebhookDeliveryState = "failed"
+	Pending   WebhookDeliveryState = "pending"
+)
+
+// Defines values for WebhookEvent.
+const (
+	InviteAccepted WebhookEvent = "invite.accepted"
+	InviteDeclined WebhookEvent = "invite.declined"
+	InviteUpdated  WebhookEvent = "invite.updated"
+	InviteViewed   WebhookEvent = "invite.viewed"
+)
+
+// Defines values for GetAdminInvitesParamsSort.
+const (
+	AcceptedAt GetAdminInvitesParamsSort = "accepted_at"
+	Id         GetAdminInvitesParamsSort = "id"
+	LastViewed GetAdminInvitesParamsSort = "last_viewed"
+	Name       GetAdminInvitesParamsSort = "name"
+)
+
+// Defines values for GetAdminInvitesParamsOrder.
+const (
+	Asc  GetAdminInvitesParamsOrder = "asc"
+	Desc GetAdminInvitesParamsOrder = "desc"
+)
+
+// DuplicatePair defines model for DuplicatePair.
+type DuplicatePair struct {
+	First  GuestRef `json:"first"`
+	Second GuestRef `json:"second"`
+
+	// Similarity 1 means identical after normalization
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicatesReport defines model for DuplicatesReport.
+type DuplicatesReport struct {
+	MinSimilarity float64         `json:"min_similarity"`
+	Pairs         []DuplicatePair `json:"pairs"`
+}
+
 // Error defines model for Error.
 type Error struct {
+	// Code Machine readable error category
+	Code ErrorCode `json:"code"`
+
+	// Fields Individual violations, present when the error concerns specific fields
+	Fields *[]FieldError `json:"fields,omitempty"`
+
+	// Message Human readable summary of the error
 	Message string `json:"message"`
 }
 
+// ErrorCode Machine readable error category
+type ErrorCode string
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Constraint Violated constraint, usually the OpenAPI keyword
+	Constraint string `json:"constraint"`
+
+	// Location Part of the request the field belongs to
+	Location FieldErrorLocation `json:"location"`
+
+	// Message Human readable description of the violation
+	Message string `json:"message"`
+
+	// Pointer JSON pointer (RFC 6901) to the offending value within its location
+	Pointer string `json:"pointer"`
+}
+
+// FieldErrorLocation Part of the request the field belongs to
+type FieldErrorLocation string
+
+// GuestName Name of an additional guest. The pattern and maxLength are replaced at runtime with the server's configured name rules.
+type GuestName = string
+
+// GuestRef defines model for GuestRef.
+type GuestRef struct {
+	Field    GuestRefField `json:"field"`
+	Index    int           `json:"index"`
+	InviteId string        `json:"invite_id"`
+	Name     string        `json:"name"`
+}
+
+// GuestRefField defines model for GuestRef.Field.
+type GuestRefField string
+
+// InviteEntry defines model for InviteEntry.
+type InviteEntry struct {
+	Id     string       `json:"id"`
+	Invite InviteRecord `json:"invite"`
+
+	// Status RSVP state: accepted or declined once answered, opened when viewed but unanswered, pending otherwise
+	Status InviteStatus `json:"status"`
+}
+
+// InviteInput Editable fields of an invite
+type InviteInput struct {
+	// Additional At most additional_count names
+	Additional      *[]GuestName         `json:"additional,omitempty"`
+	AdditionalCount int                  `json:"additional_count"`
+	Language        *InviteInputLanguage `json:"language,omitempty"`
+	People          []string             `json:"people"`
+
+	// Rsvp The guests' answer; none leaves the invite pending or opened
+	Rsvp InviteInputRsvp `json:"rsvp"`
+}
+
+// InviteInputLanguage defines model for InviteInput.Language.
+type InviteInputLanguage string
+
+// InviteInputRsvp The guests' answer; none leaves the invite pending or opened
+type InviteInputRsvp string
+
+// InvitePage defines model for InvitePage.
+type InvitePage struct {
+	Items []InviteEntry `json:"items"`
+
+	// NextCursor Pass as cursor to fetch the next page; omitted on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
 // InviteRecord defines model for InviteRecord.
 type InviteRecord struct {
 	Accepted        bool         `json:"accepted"`
 	AcceptedAt      *time.Time   `json:"accepted_at"`
-	Additional      *[]string    `json:"additional,omitempty"`
+	Additional      *[]GuestName `json:"additional"`
 	AdditionalCount int          `json:"additional_count"`
-	People          []string     `json:"people"`
-	ViewedAt        *[]time.Time `json:"viewed_at,omitempty"`
+
+	// Declined Set by admins when guests send their regrets; cleared when the invite is accepted
+	Declined *bool `json:"declined,omitempty"`
+
+	// Language Preferred language for guest-facing messages; omitted to follow Accept-Language
+	Language *InviteRecordLanguage `json:"language,omitempty"`
+	People   []string              `json:"people"`
+
+	// ViewedAt View list written by earlier versions; accepted on import and folded into views, never returned
+	// Deprecated: this property has been marked as deprecated upstream, but no `x-deprecated-reason` was set
+	ViewedAt *[]time.Time `json:"viewed_at"`
+
+	// Views Compacted view history. Views of the same kind within the dedupe window count once; only browser views mark an invite opened.
+	Views *InviteViews `json:"views,omitempty"`
+}
+
+// InviteRecordLanguage Preferred language for guest-facing messages; omitted to follow Accept-Language
+type InviteRecordLanguage string
+
+// InviteStatus RSVP state: accepted or declined once answered, opened when viewed but unanswered, pending otherwise
+type InviteStatus string
+
+// InviteViews Compacted view history. Views of the same kind within the dedupe window count once; only browser views mark an invite opened.
+type InviteViews struct {
+	Bot     ViewStats `json:"bot"`
+	Browser ViewStats `json:"browser"`
+
+	// Recent Latest views, oldest first, capped by VIEW_HISTORY_LIMIT
+	Recent *[]ViewEvent `json:"recent,omitempty"`
 }
 
 // InvitesMap defines model for InvitesMap.
 type InvitesMap map[string]InviteRecord
 
+// NameRules defines model for NameRules.
+type NameRules struct {
+	// ExtraChars Other allowed characters
+	ExtraChars string `json:"extra_chars"`
+
+	// MaxLength Maximum name length in characters; 0 means unlimited
+	MaxLength int `json:"max_length"`
+
+	// Pattern The GuestName pattern in Go/OpenAPI syntax
+	Pattern string `json:"pattern"`
+
+	// Scripts Unicode scripts whose letters are allowed
+	Scripts []string `json:"scripts"`
+}
+
+// ViewEvent defines model for ViewEvent.
+type ViewEvent struct {
+	At time.Time `json:"at"`
+
+	// Bot Set for crawlers and link-preview fetchers
+	Bot *bool `json:"bot,omitempty"`
+}
+
+// ViewStats defines model for ViewStats.
+type ViewStats struct {
+	Count int        `json:"count"`
+	First *time.Time `json:"first,omitempty"`
+	Last  *time.Time `json:"last,omitempty"`
+}
+
+// Webhook defines model for Webhook.
+type Webhook struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Events    []WebhookEvent `json:"events"`
+	Id        string         `json:"id"`
+	Url       string         `json:"url"`
+}
+
+// WebhookDelivery One event on its way to one webhook
+type WebhookDelivery struct {
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+	EventId   string    `json:"event_id"`
+
+	// EventType invite.viewed when guests open their invite (bots excluded), invite.accepted when it becomes accepted, invite.declined when an admin marks it declined, invite.updated for any other change to its guests or answer
+	EventType WebhookEvent `json:"event_type"`
+
+	// Id Sent as X-Webhook-Delivery, the same on every attempt
+	Id string `json:"id"`
+
+	// LastError Why the last attempt failed
+	LastError *string `json:"last_error,omitempty"`
+
+	// LastStatus HTTP status of the last attempt; omitted when it got no answer
+	LastStatus *int `json:"last_status,omitempty"`
+
+	// NextAttemptAt When a pending delivery is tried next
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	// Payload The event, exactly as posted
+	Payload map[string]interface{} `json:"payload"`
+
+	// State pending while in the outbox, delivered after a 2xx answer, failed after WEBHOOK_MAX_ATTEMPTS attempts
+	State     WebhookDeliveryState `json:"state"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// WebhookDeliveryState pending while in the outbox, delivered after a 2xx answer, failed after WEBHOOK_MAX_ATTEMPTS attempts
+type WebhookDeliveryState string
+
+// WebhookDeliveryList defines model for WebhookDeliveryList.
+type WebhookDeliveryList struct {
+	Items []WebhookDelivery `json:"items"`
+}
+
+// WebhookEvent invite.viewed when guests open their invite (bots excluded), invite.accepted when it becomes accepted, invite.declined when an admin marks it declined, invite.updated for any other change to its guests or answer
+type WebhookEvent string
+
+// WebhookInput Editable fields of a webhook
+type WebhookInput struct {
+	Events []WebhookEvent `json:"events"`
+
+	// Secret Key for the HMAC-SHA256 signature on every delivery. Required when creating; kept when omitted on update. Never returned.
+	Secret *string `json:"secret,omitempty"`
+
+	// Url http or https URL the events are posted to
+	Url string `json:"url"`
+}
+
+// WebhookList defines model for WebhookList.
+type WebhookList struct {
+	Items []Webhook `json:"items"`
+}
+
+// GetAdminGuestsExportParams defines parameters for GetAdminGuestsExport.
+type GetAdminGuestsExportParams struct {
+	// Latin Add a name_latin column with the Bulgarian Streamlined System transliteration
+	Latin *bool `form:"latin,omitempty" json:"latin,omitempty"`
+}
+
+// GetAdminInvitesParams defines parameters for GetAdminInvites.
+type GetAdminInvitesParams struct {
+	// Status Only return invites in this RSVP state
+	Status *InviteStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Q Only return invites where a person or additional guest name contains this text. Matching ignores case and works in Cyrillic or its Latin transliteration, so "ivan" finds "Иван".
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// Sort Sort by invite ID, first person's name, acceptance time or most recent view. Invites without an acceptance or view sort last.
+	Sort  *GetAdminInvitesParamsSort  `form:"sort,omitempty" json:"sort,omitempty"`
+	Order *GetAdminInvitesParamsOrder `form:"order,omitempty" json:"order,omitempty"`
+
+	// Limit Page size
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor from the previous page. It is only valid with the same sort and order.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetAdminInvitesParamsSort defines parameters for GetAdminInvites.
+type GetAdminInvitesParamsSort string
+
+// GetAdminInvitesParamsOrder defines parameters for GetAdminInvites.
+type GetAdminInvitesParamsOrder string
+
+// GetAdminDuplicatesParams defines parameters for GetAdminDuplicates.
+type GetAdminDuplicatesParams struct {
+	// MinSimilarity Lowest similarity (0-1) reported; defaults to 0.85
+	MinSimilarity *float64 `form:"min_similarity,omitempty" json:"min_similarity,omitempty"`
+}
+
+// GetAdminWebhookDeliveriesParams defines parameters for GetAdminWebhookDeliveries.
+type GetAdminWebhookDeliveriesParams struct {
+	// Limit Most deliveries returned
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// CreateAdminInviteJSONRequestBody defines body for CreateAdminInvite for application/json ContentType.
+type CreateAdminInviteJSONRequestBody = InviteInput
+
 // PutAdminInvitesJSONRequestBody defines body for PutAdminInvites for application/json ContentType.
 type PutAdminInvitesJSONRequestBody = InvitesMap
 
+// PutAdminInviteJSONRequestBody defines body for PutAdminInvite for application/json ContentType.
+type PutAdminInviteJSONRequestBody = InviteInput
+
+// CreateAdminWebhookJSONRequestBody defines body for CreateAdminWebhook for application/json ContentType.
+type CreateAdminWebhookJSONRequestBody = WebhookInput
+
+// PutAdminWebhookJSONRequestBody defines body for PutAdminWebhook for application/json ContentType.
+type PutAdminWebhookJSONRequestBody = WebhookInput
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
-	// Get all invites
+	// Export every invited person and additional guest as CSV
+	// (GET /admin/exports/guests)
+	GetAdminGuestsExport(c *gin.Context, params GetAdminGuestsExportParams)
+	// List invites one page at a time
 	// (GET /admin/invites)
-	GetAdminInvites(c *gin.Context)
+	GetAdminInvites(c *gin.Context, params GetAdminInvitesParams)
+	// Create an invite with a generated ID
+	// (POST /admin/invites)
+	CreateAdminInvite(c *gin.Context)
 	// Replace all invites
 	// (PUT /admin/invites)
 	PutAdminInvites(c *gin.Context)
+	// Delete one invite
+	// (DELETE /admin/invites/{id})
+	DeleteAdminInvite(c *gin.Context, id string)
+	// Get one invite without recording a view
+	// (GET /admin/invites/{id})
+	GetAdminInvite(c *gin.Context, id string)
+	// Update one invite
+	// (PUT /admin/invites/{id})
+	PutAdminInvite(c *gin.Context, id string)
+	// Active guest name rules, for client-side validation
+	// (GET /admin/name-rules)
+	GetAdminNameRules(c *gin.Context)
+	// Report probable duplicate guests across all invites
+	// (GET /admin/reports/duplicates)
+	GetAdminDuplicates(c *gin.Context, params GetAdminDuplicatesParams)
+	// List webhooks
+	// (GET /admin/webhooks)
+	GetAdminWebhooks(c *gin.Context)
+	// Create a webhook with a generated ID
+	// (POST /admin/webhooks)
+	CreateAdminWebhook(c *gin.Context)
+	// Delete one webhook with its pending deliveries and log
+	// (DELETE /admin/webhooks/{id})
+	DeleteAdminWebhook(c *gin.Context, id string)
+	// Get one webhook
+	// (GET /admin/webhooks/{id})
+	GetAdminWebhook(c *gin.Context, id string)
+	// Update one webhook
+	// (PUT /admin/webhooks/{id})
+	PutAdminWebhook(c *gin.Context, id string)
+	// Delivery log of one webhook, newest first
+	// (GET /admin/webhooks/{id}/deliveries)
+	GetAdminWebhookDeliveries(c *gin.Context, id string, params GetAdminWebhookDeliveriesParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -57,9 +446,101 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
+// GetAdminGuestsExport operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminGuestsExport(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAdminGuestsExportParams
+
+	// ------------- Optional query parameter "latin" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "latin", c.Request.URL.Query(), &params.Latin)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter latin: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminGuestsExport(c, params)
+}
+
 // GetAdminInvites operation middleware
 func (siw *ServerInterfaceWrapper) GetAdminInvites(c *gin.Context) {
 
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAdminInvitesParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", c.Request.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", c.Request.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter q: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", c.Request.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sort: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "order" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "order", c.Request.URL.Query(), &params.Order)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter order: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", c.Request.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cursor: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminInvites(c, params)
+}
+
+// CreateAdminInvite operation middleware
+func (siw *ServerInterfaceWrapper) CreateAdminInvite(c *gin.Context) {
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -67,7 +548,7 @@ func (siw *ServerInterfaceWrapper) GetAdminInvites(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetAdminInvites(c)
+	siw.Handler.CreateAdminInvite(c)
 }
 
 // PutAdminInvites operation middleware
@@ -83,6 +564,250 @@ func (siw *ServerInterfaceWrapper) PutAdminInvites(c *gin.Context) {
 	siw.Handler.PutAdminInvites(c)
 }
 
+// DeleteAdminInvite operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAdminInvite(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAdminInvite(c, id)
+}
+
+// GetAdminInvite operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminInvite(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminInvite(c, id)
+}
+
+// PutAdminInvite operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminInvite(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminInvite(c, id)
+}
+
+// GetAdminNameRules operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminNameRules(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminNameRules(c)
+}
+
+// GetAdminDuplicates operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminDuplicates(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAdminDuplicatesParams
+
+	// ------------- Optional query parameter "min_similarity" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "min_similarity", c.Request.URL.Query(), &params.MinSimilarity)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter min_similarity: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminDuplicates(c, params)
+}
+
+// GetAdminWebhooks operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminWebhooks(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminWebhooks(c)
+}
+
+// CreateAdminWebhook operation middleware
+func (siw *ServerInterfaceWrapper) CreateAdminWebhook(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateAdminWebhook(c)
+}
+
+// DeleteAdminWebhook operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAdminWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteAdminWebhook(c, id)
+}
+
+// GetAdminWebhook operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminWebhook(c, id)
+}
+
+// PutAdminWebhook operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminWebhook(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutAdminWebhook(c, id)
+}
+
+// GetAdminWebhookDeliveries operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminWebhookDeliveries(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAdminWebhookDeliveriesParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAdminWebhookDeliveries(c, id, params)
+}
+
 // GinServerOptions provides options for the Gin server.
 type GinServerOptions struct {
 	BaseURL      string
@@ -110,23 +835,94 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.GET(options.BaseURL+"/admin/exports/guests", wrapper.GetAdminGuestsExport)
 	router.GET(options.BaseURL+"/admin/invites", wrapper.GetAdminInvites)
+	router.POST(options.BaseURL+"/admin/invites", wrapper.CreateAdminInvite)
 	router.PUT(options.BaseURL+"/admin/invites", wrapper.PutAdminInvites)
+	router.DELETE(options.BaseURL+"/admin/invites/:id", wrapper.DeleteAdminInvite)
+	router.GET(options.BaseURL+"/admin/invites/:id", wrapper.GetAdminInvite)
+	router.PUT(options.BaseURL+"/admin/invites/:id", wrapper.PutAdminInvite)
+	router.GET(options.BaseURL+"/admin/name-rules", wrapper.GetAdminNameRules)
+	router.GET(options.BaseURL+"/admin/reports/duplicates", wrapper.GetAdminDuplicates)
+	router.GET(options.BaseURL+"/admin/webhooks", wrapper.GetAdminWebhooks)
+	router.POST(options.BaseURL+"/admin/webhooks", wrapper.CreateAdminWebhook)
+	router.DELETE(options.BaseURL+"/admin/webhooks/:id", wrapper.DeleteAdminWebhook)
+	router.GET(options.BaseURL+"/admin/webhooks/:id", wrapper.GetAdminWebhook)
+	router.PUT(options.BaseURL+"/admin/webhooks/:id", wrapper.PutAdminWebhook)
+	router.GET(options.BaseURL+"/admin/webhooks/:id/deliveries", wrapper.GetAdminWebhookDeliveries)
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xUTW/bMAz9KwK3o5dk63bxrcOGIYcBRS87DEPBSIyrTpZUiU5hFP7vA2XHadpgH8C+",
-	"TiYsku/xPUr3oEMbgyfPGep7yPqaWizh+5RCkiCmECmxpfK7pZyxIQm5jwQ1ZE7WNzAMFSS67WwiA/Xn",
-	"OfFLtU8MmxvSDEMFa7+zTJekQzJPIVBrikzmAcYmBEfopXZ/eoUsCduQWonAINMLti1BBb5zDjeOoObU",
-	"UfWYaQVojGUbPDrpYZnafGKiuRBTwv647kqHzvODIuuZGkqSFSlER7/WeWfpbh5qLjs53Q96PfJhInOC",
-	"+0HL75iUP2IspszVF0dmPU+0hRqeLQ+LtJy2aHlk8/AEQphavw3SxlDWyUZBgBrWnil5dApNa706v1ir",
-	"bUiqRY+N9Y26I2PkawUApUgZZBRtLIvy8GnKON83gAp2lPLY/+VitVgJoxDJY7RQw9litTiDCiLydRls",
-	"WbCXdlRB/jRUzJHpC+baQA0fiAvGpBaI+DkGn8eaV6uVfHTwTOO6YIzO6tJgeZOFzv7a/ZycxZCi3bFm",
-	"586piaz6Sj0ZtenV+p1M+eY3khifhRP4s2c0ZVSQu7bF1I8qKTwQLJekO6HmRfdUzduOMr8Npv9jQh6u",
-	"izwXwz+zcDpViaJDTUblTmvKeds5Vx6J13/HyB06a9SkvNqI9P/FFl2Ouhxv0jAM3wIAAP//nm9MRcYG",
-	"AAA=",
+	"H4sIAAAAAAAC/+xb/W4cOXJ/lULngLWR1kjeXS9yEoJAK2tt5STLkWT7grUz4DRrZnjuJntJtmbmDL1H",
+	"HiPI/3mHfaSgSPbXdLdm5F1pfYD+sjzNr6r61Tf5OUpUliuJ0ppo/3NkkjlmzP35oshTkTCLb5jQ9EOu",
+	"VY7aCnSfp0IbS3/8SeM02o/+abdeaTcss/uyQGMvcBrdxJHBREl+pxkiEynTwq5oFkeTaJFboWS0Hz2D",
+	"DJk0IDhKKxKWApta1CCVzlgq/s7cuDia0v9ttB9xVUxSjOLIrnKM9iNZZBPU0c1NHGn8pRAaebT/c3PP",
+	"ONBYHf1jNVlN/oaJpTNWXDIXmCttu4zKhBy3Kdl4pjjKmdButrCYmU1Ma4vqplqPac1WHRLXDlRu1kfd",
+	"sdaqR/aJ4rjpTG7qEQ28IUZiyk1XiieSi2vBC5bCtVCpE5qJIddoUFpYzFGCnSMgLQaJkglqacDkmIip",
+	"SCCsG2/Hpp9otCepw6M4ytAYNsPuIV8VGZOgkXE2SRFMkWVMr0BN66PVIjRWCznr8NxxrN5jkNdHivec",
+	"4IwlcyGxPkPgB7M4U5pEiLLIaJ9rlgru2DieMpEiJ95I9+t4ojgNlcqOp6qQ4ZOwOMalMJbYaEWGqrDu",
+	"i0UtWTr29H3sEBhHDXb2IEQaq5mQtkvNOydp5FAPiqEwBUvTlWPpeY7y8M0JfMLVQmke9eydqsRreGf1",
+	"N0zbUjYkATTW/e2gAhNMlZwZsKrBtMCYnNl5FEe/FOhYOkfGsZ/ybaHS+FgeqYJ5H1W5cmzvrvvvl+ev",
+	"IXyFJxc/HcEPf9579hSscouq6RQlF3IG1ywtEBbCzoUEYQ1UjNqE0MbA8hhxU4y3g9eZ7dcs6+EJ/UrU",
+	"MwmMc0G/shRmNGEEV3OEnFnCGjDJIWPLU5QzOwemSX55yhLkwCzoQhI6HW2OaIP6GvU3hmA0FbNCIwdJ",
+	"e+kiRTOiA5eLRfvP9vbiKBOy+n8chW2j/ei/fv7wIf98tNIiTUVyAx8+7Hz85z/1CajyTj3uEFPn3EpQ",
+	"5ahyZ9tronuxJCTHJU0MX4j1M+8EgnYK3vhcT5SB27eLtV4jWOGo3DKs0CfNEzfpWFq96lI6cBy/0SYT",
+	"7Fe+wIT0mly8ZbYw28269GM7FPKoWqc6xjBVJzIveozSMRfW6ax3KQGwYbV4jQUNkXYWOrSQKWMbWB8n",
+	"qpDWYXNrV1XrU4+nWl86RBkiI+TtxT1ISpmcFcFiVVZv5kxgLygDeJvxR1t5OjMyIU/80GfdA2tznXc5",
+	"RbrvzID5Bpg0C9QHIJVESJFdo3FK7gUAeTBvSoPKUTq3VtJBU0jNkgRz675wTFJBgz5uMno9ShpYGg49",
+	"jKM3gZ1rylGyayspN/WsR84Sl3acFNoo3efnjAFmwH8nTzBFm3jTSBMhZzM8AJUJS75W+UAqZcZ/2egP",
+	"PAHD9Acd7nCgkkNtJCZKpcikg274Oma2HQkziztk36M4kkWakipG+1YX2IO1tvrdXZ8GNrhVv7o6VaGs",
+	"I5pLtDBZAeOZkMYHsR7nYFBykoPQoHGm0ZoDSFJk5LuqYDdgXhhoYLrLyqZOr0FD4xQ1rVmOganS/gw7",
+	"U5aQJgVnbmqEEIJUmqoFHLptd07LDeIvtRmdMeucvha4qMDAMddIMS0vBbMeN+ICUmEsLDQdWRKTkelU",
+	"oIZr1EYoaQ4qphHkRZYrbV1kMVUpRw5CWgW0rYlB4jWSIGyhvUmpDt6Ly64H3oAjt812NuCdG3oX81Rh",
+	"Y1hFLyvn2mbkxeW7N0AeE/cb3NJQIhqUTDCYZORxMLkeoF5iMCksFLIeUhloO0e9EKYJmvAtiqPKdm9n",
+	"rOOoyZwOGUcqy1lCZ6dDwVwYq/RqBG54GW8bigg/CcnLoJh+5MiLnEJJydUCvHsmmg9AyXQFE60WhjDl",
+	"FsqY/lTHAoEZo05MMFEbqyF0MJKJIdLCJneaozHBvozqlFk0toQ1Ad1YcMWLGBKW5ySwFbw7OX4/fnVy",
+	"eXV+8Z/j05Ozk6ttoxE6xPE1bb2ptlBSFTt+DEPTnLG8HUm9aXHzLjFkZwuy8xdF6ldqSwmXVrNxMme6",
+	"B1DnBF5gZAMpO50zTfDSpk/3M7YcpyEe6qbrS4rFfDbiB4GQjQUPYC+UrwqZiky0THzDxVQpSl/oVLm0",
+	"KoESEl6q3TJ7Nitp2bLv7H6lHga8lYIKFRAGwGKuDFFAyxuXkQXmNIGzwcivm7RAUn2KuCWUFmf78FNj",
+	"sRt62O1td1DXruMmT5lotkgdyZJDKuSnnVyjMzIuxmpBovLIa4QyO3h8r889NZPBWKMqtm5HXMq2H90p",
+	"VBWy/+TvcTJX6lPPuTWyDSFd54R4XVactzJAYe8BGxQP5aSFTrfIkHnkR1anipsk3cKKF5iKa9Q91elz",
+	"ieAWc0EIaRJbUYClJMLCT+4mldZiFtSyi4AvZvJQ+cB/9D/fjfmiN+SVlrKRv+6E0Tslc+LaDStJTNEr",
+	"CKQOQTfUHDubvJ+v6iQmrAFVmbN/KTMQA726uvIxUFGFCs1l67DYRT3CwkxZkCpERb3W2qVrYX4V0rbP",
+	"jxJYFSvxwCGK9K0WVL7CpY3iLaWbs1WqGO93Dk66MeCSJTZdkWRyZVpupkaziwS7y5THXMxFihAiJ1XY",
+	"iVrG5dmRh7YLg2+Xy8CcOMgkfHp//OOr8/O/jM8O/zo+vLo6PntzdQkV2PvCxGpx4oUXb19wWOT8jirR",
+	"p/mVkrRUomZvyZ84apx5XdQt/WydbAvzcSqM/a2VhLUlN7rh4ey+pe0dUPggeBSygGZqq3KfvgpdRspP",
+	"JsoawGWSFhz50zj8PqpSjlK1JpioDOt0txpZJSRupCsgZ0K6iNzQxPJ7NSEw3jlxJlc+HaHAS86Q7C+Z",
+	"4vK8utblEoIt6qpi4qiRrawdrP4l7NwL1MDSO1QeB53Eb/Sbw1W6OCqk+KXA8JkSWt+z1dhz5r/gyvGY",
+	"TMKrs8OjnctXh98+/wGMmElmC92w9aWVG8FFwJ+XplMYIWcH8Anz0OprFKs8P0fwupWjr1X1v33+Q7uq",
+	"/8M69+OIagV4LtNVRVSICtoUza3NCRP0r4G3F6eONs9uF/l6++kbR43WgRv/b/u7uxutTSvEuEXzfkdz",
+	"8MVm4MYV9Keqr2Pr+4JBFSnRICBkTLKZ8xbInddwauEaSsCZZcQdYVPa430YcVguEMVRqN5QX3+0N9qj",
+	"c5M9YbmI9qPvRnuj70J/zlG+6/bexWWutDW7XqHpw8xjlfjmtj7h0X70Eq3byuVL5thNcqtplqFL7/Z/",
+	"7hTxOQfm8rcxdeskJCotMlk3n34s0hnTgkm4tBpZ5s3U5cpYzMBqJk0qbDiFMxLRftVY9I2byC3s8iCS",
+	"mef0lBWpjfanLDXYk2F8JOmZXEnjQfHt3l5ottpgrS0u7W5iruu7HH0B8M16ae3o8p2njYFve4JWC5f9",
+	"KInu7xxDAXEfqpZS7E0WWV+Oy9jxK25y7YkrpzjVdj/8K2ngU7duw6b6HR1BR56SnRfC5MqIssN7CyU3",
+	"cfS8wwWW+/sQQsndv5n1NTbeWujjUIV7H5u6bb+7/22vGl1szSTkFKNeHP/H2+PLq/HVydnx+dsreBLa",
+	"9k+dfof7CeRZHNaDHfZS4yRIo3yzdb0dS2Hi0eU7t0rQMT9rs3KFqs4mvSI7HIx5OJDxkaUwUFclBxSm",
+	"6vFtx9P1puE2Z1nMUSOwkklKd3lEhwESOhPS+JOT0o3gjNlk7kzfTCqNBhJm0PF5oVy8IqFsMdPCwho4",
+	"dVqyZi5iMAo+ROKayQ8RTIXkBj5Ev/73r//76//8+n8fotEAe36J4lsUpUP+JUFjUuICTl7EvmIYaP/G",
+	"BHX2ispkguD670r7/qavRbqa4whOSv4JO1eFdaFaPU/5UioY2pKyrCESjDfNPRYxxOplnMbLOe2OUsj5",
+	"QvzW1/373Luv0hz1wMbMJI2d/f+IkwPLrzfpZghG/H0I0q7417/x8z0X6vim7vNwfcH/71k3++xu3Wge",
+	"wlSrzLktV8ZShXEtwBGcWMo9nZF2V4Rq/+bydVP2Thx/hoTm97gVfJv91pebzkY3tsd+UiGGaKWoOqsU",
+	"NBirmzj6/mF8h+euYxxUBjJ69F3Dvoui4Mosq1KKzAJzZsjfljI9HunIJeINpxT5kBeN/ZHuef2+uPM5",
+	"3U07rg55xhrkn/3OW4drA71wI4seKhLtAOu0cW/u9qjqQTXDC9or5J8fBpkzlAQb5HDywvXaU42Mr8Cy",
+	"TyjhSetm5NMD0BguaTxq7IDGesVrdEpDQtFktFPbokdr3xTrceT96azrPW6lsnv3tnOfxpr6vqMpkgSN",
+	"mRZpunpwN1UiwF2LfUT8MOIvvLioKVrHFJ3cafez4Dc+tEvRYhf7L9zv6x6rBcTv+woxTsn8otyD5PsH",
+	"AQltK5UFf4n8ESDDAPGSdeFL7eG2yKOje7dEg8HD1Rxb3vgRUl8XpF6ibeCpSri1uw1DyQ1zubZvELYq",
+	"MS53C28cQurm8ui2E9yQxvV2MIId9Dd2sd3O8Ff8mxe0KNiikn/cqgOOmctFDdrORcj19pCb17o1KVxr",
+	"ll52oAbh7v7fFl58LRnBgyp12RprKvcfFOE/2pSvy6a8ddBouak6iiFTsaPL23S3Oq/63t09Qr3eZIAN",
+	"jedAOoxrEnuYWHGNzWKuGxX7i1+poPaDERyhfkrXZIdG33Ti1bvPjWypn4huKo+fqgWdqn6cCU/2dp49",
+	"Bb8p8gMI5UEDVsHe6F+eD9TkOk88a952H59WVcZncd8zkuqh7H1W8TrPaHuES49bXXs8UOakZ2Jfji5/",
+	"8zflHrX91qxFaQu5VhP/SLFkfXkzgiVaGTOU1YSbCZth/74ceI+wabbMe/h07Dpf4cQxdTtOXjxiY1PV",
+	"tZJwo8ba3uMnrTKQagFKxqG9aIoJjZggDxcfhamvTLhoju5U+JKQbFwTvKxujITGsxEzd+OnfuaZaAwv",
+	"Rf3ftHQZWoFv2Em+9pijGwI26sLvq8s19xEGtm77PHBluKSsBxfh0z9UWXhRX2N5VNkNZdeSWf1l1679",
+	"vktZqqkwm+pSJc4eujBV7vsY8t+xMtXCjbBm/X6ywPAOQs02lq8GgbL3ECbual5R8wi7r7p61ZDSH1Ol",
+	"omCEyRCrmPXoonMjdQQvGsoQOoWtK/EwU2WgI3FB6w9Xob62+OPBlLOsQbWU9I+JJx6Nw9dchqoENRC3",
+	"7Na+qZGHrpULetyYRjBWuMS2obsHzecs9FTcP19RMkxx5mCq6qcsp+cvxxfHV8evr07OX3f1fM0b1qZj",
+	"U/3nTBnbPG/zafq93h77eP82ofXaZQA6qX/H3BDuV3BF69FSfI3Rq385l6oZFQQbJiMm91s9f7+fAOPm",
+	"5ub/BwD3FIBEOFEAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
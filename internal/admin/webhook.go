@@ -0,0 +1,250 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/dimitarkovachev/wedding/internal/events"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// Limits on webhook input, as in the WebhookInput schema.
+const (
+	minSecretLength = 16
+	maxSecretLength = 256
+)
+
+// deliveryEntry mirrors the WebhookDelivery schema, with the payload passed
+// through exactly as it was posted.
+type deliveryEntry struct {
+	ID            string          `json:"id"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	State         string          `json:"state"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastStatus    int             `json:"last_status,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+type deliveryList struct {
+	Items []deliveryEntry `json:"items"`
+}
+
+func (h *Handler) GetAdminWebhooks(c *gin.Context) {
+	hooks, err := h.store.ListWebhooks(c.Request.Context())
+	if err != nil {
+		webhookError(c, err, "failed to list webhooks")
+		return
+	}
+
+	resp := WebhookList{Items: make([]Webhook, 0, len(hooks))}
+	for _, w := range hooks {
+		resp.Items = append(resp.Items, webhookResponse(w))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) CreateAdminWebhook(c *gin.Context) {
+	in, ok := bindWebhook(c, true)
+	if !ok {
+		return
+	}
+
+	w := store.Webhook{
+		ID:        uuid.NewString(),
+		URL:       in.Url,
+		Secret:    *in.Secret,
+		Events:    webhookEvents(in.Events),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := h.store.PutWebhook(c.Request.Context(), w); err != nil {
+		webhookError(c, err, "failed to create webhook")
+		return
+	}
+
+	c.Header("Location", "/admin/webhooks/"+w.ID)
+	c.JSON(http.StatusCreated, webhookResponse(w))
+}
+
+func (h *Handler) GetAdminWebhook(c *gin.Context, id string) {
+	w, err := h.store.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		webhookError(c, err, "failed to get webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(*w))
+}
+
+func (h *Handler) PutAdminWebhook(c *gin.Context, id string) {
+	in, ok := bindWebhook(c, false)
+	if !ok {
+		return
+	}
+
+	w, err := h.store.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		webhookError(c, err, "failed to update webhook")
+		return
+	}
+	w.URL = in.Url
+	w.Events = webhookEvents(in.Events)
+	if in.Secret != nil {
+		w.Secret = *in.Secret
+	}
+	if err := h.store.PutWebhook(c.Request.Context(), *w); err != nil {
+		webhookError(c, err, "failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(*w))
+}
+
+func (h *Handler) DeleteAdminWebhook(c *gin.Context, id string) {
+	if err := h.store.DeleteWebhook(c.Request.Context(), id); err != nil {
+		webhookError(c, err, "failed to delete webhook")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetAdminWebhookDeliveries(c *gin.Context, id string, params GetAdminWebhookDeliveriesParams) {
+	limit := defaultPageSize
+	var fields []FieldError
+	if params.Limit != nil {
+		switch {
+		case *params.Limit < 1:
+			fields = append(fields, FieldError{Location: Query, Pointer: "/limit", Constraint: "minimum", Message: "limit must be at least 1"})
+		case *params.Limit > maxPageSize:
+			fields = append(fields, FieldError{Location: Query, Pointer: "/limit", Constraint: "maximum", Message: "limit must be at most 500"})
+		default:
+			limit = *params.Limit
+		}
+	}
+	if len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, Error{Code: ValidationFailed, Message: "invalid query parameter", Fields: &fields})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.store.GetWebhook(ctx, id); err != nil {
+		webhookError(c, err, "failed to get webhook")
+		return
+	}
+	list, err := h.store.ListDeliveries(ctx, id, limit)
+	if err != nil {
+		webhookError(c, err, "failed to list webhook deliveries")
+		return
+	}
+
+	resp := deliveryList{Items: make([]deliveryEntry, 0, len(list))}
+	for _, d := range list {
+		resp.Items = append(resp.Items, deliveryEntry{
+			ID:            d.ID,
+			EventID:       d.EventID,
+			EventType:     d.EventType,
+			Payload:       d.Payload,
+			State:         d.State,
+			Attempts:      d.Attempts,
+			NextAttemptAt: d.NextAttemptAt,
+			LastStatus:    d.LastStatus,
+			LastError:     d.LastError,
+			CreatedAt:     d.CreatedAt,
+			UpdatedAt:     d.UpdatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// webhookError is storeError for webhook endpoints, whose missing resource
+// is a webhook rather than an invite.
+func webhookError(c *gin.Context, err error, msg string) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, Error{Code: NotFound, Message: "webhook not found"})
+		return
+	}
+	storeError(c, err, msg)
+}
+
+// webhookResponse is w as returned to admins, without its secret.
+func webhookResponse(w store.Webhook) Webhook {
+	evs := make([]WebhookEvent, len(w.Events))
+	for i, e := range w.Events {
+		evs[i] = WebhookEvent(e)
+	}
+	return Webhook{Id: w.ID, Url: w.URL, Events: evs, CreatedAt: w.CreatedAt}
+}
+
+func webhookEvents(in []WebhookEvent) []string {
+	evs := make([]string, len(in))
+	for i, e := range in {
+		evs[i] = string(e)
+	}
+	return evs
+}
+
+// bindWebhook decodes and validates a WebhookInput body, writing the 400
+// response itself when it is rejected. The secret is only required when
+// creating.
+func bindWebhook(c *gin.Context, requireSecret bool) (WebhookInput, bool) {
+	var in WebhookInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, Error{Code: InvalidBody, Message: "invalid request body"})
+		return in, false
+	}
+
+	if fields := webhookFields(in, requireSecret); len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, Error{Code: ValidationFailed, Message: "invalid webhook", Fields: &fields})
+		return in, false
+	}
+	return in, true
+}
+
+// webhookFields checks in against the WebhookInput schema.
+func webhookFields(in WebhookInput, requireSecret bool) []FieldError {
+	var fields []FieldError
+	invalid := func(pointer, constraint, message string) {
+		fields = append(fields, FieldError{Location: Body, Pointer: pointer, Constraint: constraint, Message: message})
+	}
+
+	if u, err := url.Parse(in.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("/url", "pattern", "url must be an absolute http or https URL")
+	}
+	switch {
+	case in.Secret == nil:
+		if requireSecret {
+			invalid("/secret", "required", "secret is required")
+		}
+	case utf8.RuneCountInString(*in.Secret) < minSecretLength:
+		invalid("/secret", "minLength", "secret must be at least "+strconv.Itoa(minSecretLength)+" characters")
+	case utf8.RuneCountInString(*in.Secret) > maxSecretLength:
+		invalid("/secret", "maxLength", "secret must be at most "+strconv.Itoa(maxSecretLength)+" characters")
+	}
+	if len(in.Events) == 0 {
+		invalid("/events", "minItems", "at least one event is required")
+	}
+	for i, e := range in.Events {
+		pointer := "/events/" + strconv.Itoa(i)
+		switch {
+		case !slices.Contains(events.Types, string(e)):
+			invalid(pointer, "enum", "event must be one of invite.viewed, invite.accepted, invite.declined, invite.updated")
+		case slices.Contains(in.Events[:i], e):
+			invalid(pointer, "uniqueItems", "event is listed more than once")
+		}
+	}
+	return fields
+}
@@ -0,0 +1,183 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func setupWebhookRouter(t *testing.T) (*gin.Engine, *store.MemoryStore) {
+	t.Helper()
+	s := store.NewMemoryStore()
+	rules := names.DefaultRules()
+	r := newTestEngine(t, testSpec(t, rules))
+	RegisterHandlersWithOptions(r, NewHandler(s, rules), GinServerOptions{ErrorHandler: ParamErrorHandler})
+	return r, s
+}
+
+func webhookInput(secret string, evs ...string) map[string]any {
+	in := map[string]any{"url": "https://example.com/hooks", "events": evs}
+	if secret != "" {
+		in["secret"] = secret
+	}
+	return in
+}
+
+func createWebhook(t *testing.T, r *gin.Engine) Webhook {
+	t.Helper()
+	w := doJSON(t, r, http.MethodPost, "/admin/webhooks", webhookInput("secret-0123456789", "invite.accepted"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var hook Webhook
+	if err := json.Unmarshal(w.Body.Bytes(), &hook); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if got := w.Header().Get("Location"); got != "/admin/webhooks/"+hook.Id {
+		t.Fatalf("expected Location /admin/webhooks/%s, got %q", hook.Id, got)
+	}
+	return hook
+}
+
+func TestHandler_Webhooks(t *testing.T) {
+	r, s := setupWebhookRouter(t)
+
+	hook := createWebhook(t, r)
+	if hook.Url != "https://example.com/hooks" || len(hook.Events) != 1 || hook.Events[0] != InviteAccepted {
+		t.Fatalf("expected the created webhook, got %+v", hook)
+	}
+	saved, err := s.GetWebhook(context.Background(), hook.Id)
+	if err != nil || saved.Secret != "secret-0123456789" {
+		t.Fatalf("expected the secret saved, got %+v, %v", saved, err)
+	}
+
+	w := doJSON(t, r, http.MethodGet, "/admin/webhooks", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "secret-0123456789") {
+		t.Fatalf("expected the secret never returned, got %s", w.Body.String())
+	}
+	var list WebhookList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Id != hook.Id {
+		t.Fatalf("expected the one webhook listed, got %+v", list)
+	}
+
+	// Updating without a secret keeps it
+	w = doJSON(t, r, http.MethodPut, "/admin/webhooks/"+hook.Id, webhookInput("", "invite.declined", "invite.updated"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	saved, _ = s.GetWebhook(context.Background(), hook.Id)
+	if saved.Secret != "secret-0123456789" || len(saved.Events) != 2 || !saved.CreatedAt.Equal(hook.CreatedAt) {
+		t.Fatalf("expected events replaced and secret kept, got %+v", saved)
+	}
+	w = doJSON(t, r, http.MethodPut, "/admin/webhooks/"+hook.Id, webhookInput("another-secret-0123", "invite.viewed"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if saved, _ = s.GetWebhook(context.Background(), hook.Id); saved.Secret != "another-secret-0123" {
+		t.Fatalf("expected the secret replaced, got %+v", saved)
+	}
+
+	if w := doJSON(t, r, http.MethodDelete, "/admin/webhooks/"+hook.Id, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w := doJSON(t, r, method, "/admin/webhooks/"+hook.Id, nil)
+		if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "webhook not found") {
+			t.Fatalf("%s: expected 404 webhook not found, got %d %s", method, w.Code, w.Body.String())
+		}
+	}
+	w = doJSON(t, r, http.MethodPut, "/admin/webhooks/"+hook.Id, webhookInput("", "invite.viewed"))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 updating a deleted webhook, got %d", w.Code)
+	}
+}
+
+func TestHandler_Webhooks_Invalid(t *testing.T) {
+	r, _ := setupWebhookRouter(t)
+
+	tests := []struct {
+		name    string
+		body    map[string]any
+		pointer string
+	}{
+		{"no secret", webhookInput("", "invite.accepted"), "/secret"},
+		{"short secret", webhookInput("short", "invite.accepted"), "/secret"},
+		{"no events", webhookInput("secret-0123456789"), "/events"},
+		{"unknown event", webhookInput("secret-0123456789", "invite.deleted"), "/events/0"},
+		{"repeated event", webhookInput("secret-0123456789", "invite.viewed", "invite.viewed"), "/events/1"},
+		{"ftp url", map[string]any{"url": "ftp://example.com", "secret": "secret-0123456789", "events": []string{"invite.viewed"}}, "/url"},
+		{"relative url", map[string]any{"url": "/hooks", "secret": "secret-0123456789", "events": []string{"invite.viewed"}}, "/url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doJSON(t, r, http.MethodPost, "/admin/webhooks", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp Error
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			if resp.Code != ValidationFailed || resp.Fields == nil || (*resp.Fields)[0].Pointer != tt.pointer {
+				t.Fatalf("expected a field error at %s, got %+v", tt.pointer, resp)
+			}
+		})
+	}
+}
+
+func TestHandler_WebhookDeliveries(t *testing.T) {
+	r, s := setupWebhookRouter(t)
+	hook := createWebhook(t, r)
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := s.AddDeliveries(context.Background(), []store.Delivery{
+		{ID: "d1", WebhookID: hook.Id, EventID: "e1", EventType: "invite.accepted", Payload: []byte(`{"id":"e1"}`),
+			State: store.DeliveryFailed, Attempts: 8, LastStatus: 500, LastError: "unexpected status 500",
+			NextAttemptAt: now, CreatedAt: now, UpdatedAt: now},
+		{ID: "d2", WebhookID: hook.Id, EventID: "e2", EventType: "invite.accepted", Payload: []byte(`{"id":"e2"}`),
+			State: store.DeliveryPending, NextAttemptAt: now, CreatedAt: now, UpdatedAt: now},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := doJSON(t, r, http.MethodGet, "/admin/webhooks/"+hook.Id+"/deliveries", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list WebhookDeliveryList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(list.Items) != 2 || list.Items[0].Id != "d2" || list.Items[1].State != Failed {
+		t.Fatalf("expected d2 then the failed d1, got %+v", list.Items)
+	}
+	if got := list.Items[1]; got.Payload["id"] != "e1" || got.LastStatus == nil || *got.LastStatus != 500 {
+		t.Fatalf("expected the payload and last status, got %+v", got)
+	}
+
+	w = doJSON(t, r, http.MethodGet, "/admin/webhooks/"+hook.Id+"/deliveries?limit=1", nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Items) != 1 {
+		t.Fatalf("expected one delivery with limit=1, got %s", w.Body.String())
+	}
+	if w := doJSON(t, r, http.MethodGet, "/admin/webhooks/"+hook.Id+"/deliveries?limit=0", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for limit=0, got %d", w.Code)
+	}
+	if w := doJSON(t, r, http.MethodGet, "/admin/webhooks/missing/deliveries", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing webhook, got %d", w.Code)
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func TestHandler_PutInvite_ErrorsInInviteLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"validation", `{"isAccepted":false}`, i18n.T(language.English, i18n.MsgAcceptedOnly)},
+		{"name rules", `{"isAccepted":true,"additional":["   "]}`, i18n.T(language.English, i18n.MsgFieldTooShort)},
+		{"too many guests", `{"isAccepted":true,"additional":["Джейн Смит","Том Смит"]}`, i18n.T(language.English, i18n.MsgTooManyGuests, 2, 1)},
+		{"duplicate", `{"isAccepted":true,"additional":["Джон Смит"]}`, i18n.T(language.English, i18n.MsgAlreadyInvited)},
+	}
+
+	r := setupTestRouter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440002", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", "bg")
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Language"); got != "en" {
+				t.Fatalf("expected Content-Language en, got %q", got)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Fatalf("expected %q in the error, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_NotFound_Localized(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "поканата не е намерена"},
+		{"bg", "поканата не е намерена"},
+		{"en", "invite not found"},
+	}
+
+	r := setupTestRouter(t)
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/invites/00000000-0000-0000-0000-000000000000", nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		r.ServeHTTP(w, req)
+
+		var resp Error
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if resp.Message != tt.want {
+			t.Fatalf("Accept-Language %q: expected %q, got %q", tt.acceptLanguage, tt.want, resp.Message)
+		}
+	}
+}
+
+func TestHandler_PutInvite_DeadlinePassed(t *testing.T) {
+	s := seedTestStore(t, store.WithRSVPDeadline(time.Now().Add(-time.Hour)))
+	r := setupTestRouterWithStore(t, names.DefaultRules(), s)
+
+	body, _ := json.Marshal(InviteUpdate{IsAccepted: true})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440000", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp Error
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if resp.Code != DeadlinePassed || resp.Message != "срокът за потвърждение е изтекъл" {
+		t.Fatalf("expected deadline_passed with the localized message, got %+v", resp)
+	}
+}
+
+// failingStore fails every call with err.
+type failingStore struct{ err error }
+
+func (s failingStore) GetInvite(context.Context, string) (*store.InviteRecord, error) {
+	return nil, s.err
+}
+
+func (s failingStore) RecordView(context.Context, string, store.View) error { return s.err }
+
+func (s failingStore) UpdateInvite(context.Context, string, bool, []string) (*store.InviteRecord, error) {
+	return nil, s.err
+}
+
+func (failingStore) Close() error { return nil }
+
+func TestHandler_StorageErrorsAreNotLeaked(t *testing.T) {
+	secret := errors.New("open /var/lib/wedding/db: input/output error")
+	r := setupTestRouterWithStore(t, names.DefaultRules(), failingStore{secret})
+
+	body, _ := json.Marshal(InviteUpdate{IsAccepted: true})
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/invites/550e8400-e29b-41d4-a716-446655440000", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("%s: expected 500, got %d: %s", method, w.Code, w.Body.String())
+		}
+		var resp Error
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if resp.Code != InternalError || strings.Contains(w.Body.String(), "input/output") {
+			t.Fatalf("%s: expected internal_error without the cause, got %s", method, w.Body.String())
+		}
+	}
+}
+
+func TestHandler_Timeout(t *testing.T) {
+	err := fmt.Errorf("getting invite: %w", context.DeadlineExceeded)
+	r := setupTestRouterWithStore(t, names.DefaultRules(), failingStore{err})
+
+	body, _ := json.Marshal(InviteUpdate{IsAccepted: true})
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/invites/550e8400-e29b-41d4-a716-446655440000", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected 503, got %d: %s", method, w.Code, w.Body.String())
+		}
+		var resp Error
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if resp.Code != Timeout {
+			t.Fatalf("%s: expected timeout, got %+v", method, resp)
+		}
+	}
+}
+
+// countingReads counts the GetInvite calls made through it.
+type countingReads struct {
+	store.InviteStore
+	reads int
+}
+
+func (s *countingReads) GetInvite(ctx context.Context, id string) (*store.InviteRecord, error) {
+	s.reads++
+	return s.InviteStore.GetInvite(ctx, id)
+}
+
+func TestHandler_PutInvite_ReadsInviteOnlyOnError(t *testing.T) {
+	s := &countingReads{InviteStore: seedTestStore(t)}
+	r := setupTestRouterWithStore(t, names.DefaultRules(), s)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440002", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := put(`{"isAccepted":true}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if s.reads != 0 {
+		t.Fatalf("expected a successful update not to read the invite, got %d reads", s.reads)
+	}
+	if w := put(`{"isAccepted":false}`); w.Code != http.StatusBadRequest || s.reads != 1 {
+		t.Fatalf("expected a 400 after one read, got %d after %d reads", w.Code, s.reads)
+	}
+}
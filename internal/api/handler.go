@@ -1,22 +1,35 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
 	openapi_types "github.com/oapi-codegen/runtime/types"
-	log "github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
 
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/logging"
+	"github.com/dimitarkovachev/wedding/internal/names"
 	"github.com/dimitarkovachev/wedding/internal/store"
+	"github.com/dimitarkovachev/wedding/internal/useragent"
 )
 
 // Handler implements the generated ServerInterface.
 type Handler struct {
 	store store.InviteStore
+	rules names.Rules
+	spec  *openapi3.T
 }
 
-func NewHandler(s store.InviteStore) *Handler {
-	return &Handler{store: s}
+// NewHandler creates a Handler validating guest names with rules. spec is
+// served as the published API specification and should already carry rules.
+func NewHandler(s store.InviteStore, rules names.Rules, spec *openapi3.T) *Handler {
+	return &Handler{store: s, rules: rules, spec: spec}
 }
 
 var _ ServerInterface = (*Handler)(nil)
@@ -25,66 +38,221 @@ func (h *Handler) GetHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
 }
 
+func (h *Handler) GetOpenAPISpec(c *gin.Context) {
+	c.JSON(http.StatusOK, h.spec)
+}
+
 func (h *Handler) GetInvite(c *gin.Context, id openapi_types.UUID) {
 	idStr := id.String()
-	logger := log.WithField("invite_id", idStr)
+	logger := logging.FromContext(c.Request.Context()).WithField("invite_id", idStr)
+
+	lang := i18n.FromContext(c.Request.Context())
 
 	rec, err := h.store.GetInvite(c.Request.Context(), idStr)
 	if err != nil {
-		logger.WithError(err).Error("failed to get invite")
-		c.JSON(http.StatusInternalServerError, Error{Message: "internal error"})
+		status, body := storeError(lang, err)
+		switch status {
+		case http.StatusInternalServerError:
+			logger.WithError(err).Error("failed to get invite")
+		case http.StatusServiceUnavailable:
+			logger.WithError(err).Warn("failed to get invite")
+		}
+		c.JSON(status, body)
 		return
 	}
-	if rec == nil {
-		c.JSON(http.StatusNotFound, Error{Message: "invite not found"})
-		return
+
+	// Reason: the response reflects the invite as it was before this view,
+	// so a guest's first GET still reports isOpened=false
+	view := store.View{At: time.Now().UTC(), Bot: useragent.IsBot(c.Request.UserAgent())}
+	if err := h.store.RecordView(c.Request.Context(), idStr, view); err != nil && !store.IsDroppedView(err) {
+		logger.WithError(err).Error("failed to record view")
 	}
 
-	logger.Info("invite viewed")
-	c.JSON(http.StatusOK, recordToInvite(rec))
+	logger.WithField("bot", view.Bot).Info("invite viewed")
+	c.JSON(http.StatusOK, recordToInvite(rec, inviteLanguage(c, rec)))
 }
 
-func (h *Handler) PutInvite(c *gin.Context, id openapi_types.UUID) {
+// PutInvite records the guests' reply. Retries carrying the same
+// Idempotency-Key are answered by middleware.Idempotency before reaching it,
+// so params.IdempotencyKey is not used here.
+func (h *Handler) PutInvite(c *gin.Context, id openapi_types.UUID, _ PutInviteParams) {
 	idStr := id.String()
-	logger := log.WithField("invite_id", idStr)
+	logger := logging.FromContext(c.Request.Context()).WithField("invite_id", idStr)
 
 	var body InviteUpdate
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, Error{Message: "invalid request body"})
+		lang := h.errorLanguage(c, idStr)
+		c.JSON(http.StatusBadRequest, Error{Code: InvalidBody, Message: i18n.T(lang, i18n.MsgInvalidBody)})
 		return
 	}
 
 	if !body.IsAccepted {
-		c.JSON(http.StatusBadRequest, Error{Message: "only accepted=true updates are allowed"})
+		lang := h.errorLanguage(c, idStr)
+		c.JSON(http.StatusBadRequest, fieldError(ValidationFailed, FieldError{
+			Location:   Body,
+			Pointer:    "/isAccepted",
+			Constraint: "enum",
+			Message:    i18n.T(lang, i18n.MsgAcceptedOnly),
+		}))
 		return
 	}
 
+	// Reason: names are checked as they will be stored, so one made only of
+	// spaces is rejected as empty rather than saved as ""
 	var additional []string
 	if body.Additional != nil {
-		additional = *body.Additional
+		additional = make([]string, len(*body.Additional))
+		for i, name := range *body.Additional {
+			additional[i] = names.Normalize(name)
+		}
+	}
+
+	if violations := h.rules.CheckAll(additional); len(violations) > 0 {
+		c.JSON(http.StatusBadRequest, nameErrors(h.errorLanguage(c, idStr), violations))
+		return
 	}
 
 	rec, err := h.store.UpdateInvite(c.Request.Context(), idStr, body.IsAccepted, additional)
 	if err != nil {
-		logger.WithError(err).Error("failed to update invite")
-		c.JSON(http.StatusBadRequest, Error{Message: err.Error()})
+		h.updateError(c, idStr, err)
+		return
+	}
+
+	logger.Info("invite accepted")
+	c.JSON(http.StatusOK, recordToInvite(rec, inviteLanguage(c, rec)))
+}
+
+// updateError answers a failed UpdateInvite of invite id in the invite's
+// own language.
+func (h *Handler) updateError(c *gin.Context, id string, err error) {
+	logger := logging.FromContext(c.Request.Context()).WithField("invite_id", id)
+	lang := h.errorLanguage(c, id)
+
+	var tooMany *store.TooManyGuestsError
+	if errors.As(err, &tooMany) {
+		logger.WithError(err).Warn("invite update rejected")
+		c.JSON(http.StatusBadRequest, fieldError(TooManyGuests, FieldError{
+			Location: Body,
+			// Reason: point at the first guest over the limit so the frontend
+			// can highlight exactly which entries have to go
+			Pointer:    "/additional/" + strconv.Itoa(tooMany.Max),
+			Constraint: "maxAdditional",
+			Message:    i18n.T(lang, i18n.MsgTooManyGuests, tooMany.Got, tooMany.Max),
+		}))
+		return
+	}
+	var dup *store.DuplicateGuestError
+	if errors.As(err, &dup) {
+		logger.WithError(err).Warn("invite update rejected")
+		key := i18n.MsgDuplicateGuest
+		if dup.InPeople {
+			key = i18n.MsgAlreadyInvited
+		}
+		c.JSON(http.StatusBadRequest, fieldError(DuplicateGuest, FieldError{
+			Location:   Body,
+			Pointer:    "/additional/" + strconv.Itoa(dup.Index),
+			Constraint: "unique",
+			Message:    i18n.T(lang, key),
+		}))
 		return
 	}
-	if rec == nil {
-		c.JSON(http.StatusNotFound, Error{Message: "invite not found"})
+	var blank *store.BlankGuestError
+	if errors.As(err, &blank) {
+		logger.WithError(err).Warn("invite update rejected")
+		c.JSON(http.StatusBadRequest, fieldError(ValidationFailed, FieldError{
+			Location:   Body,
+			Pointer:    "/additional/" + strconv.Itoa(blank.Index),
+			Constraint: names.ConstraintMinLength,
+			Message:    i18n.T(lang, i18n.MsgFieldTooShort),
+		}))
 		return
 	}
+	status, body := storeError(lang, err)
+	if status == http.StatusInternalServerError {
+		logger.WithError(err).Error("failed to update invite")
+	} else {
+		logger.WithError(err).Warn("invite update rejected")
+	}
+	c.JSON(status, body)
+}
 
-	logger.Info("invite accepted")
-	c.JSON(http.StatusOK, recordToInvite(rec))
+// storeError maps a store error other than a guest list problem to its
+// status and body. Only the store's sentinel errors and an expired request
+// context get their own code; anything else is reported as an internal error
+// without its text.
+func storeError(lang language.Tag, err error) (int, Error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, Error{Code: Timeout, Message: i18n.T(lang, i18n.MsgTimeout)}
+	case errors.Is(err, store.ErrNotFound):
+		return http.StatusNotFound, Error{Code: NotFound, Message: i18n.T(lang, i18n.MsgInviteNotFound)}
+	case errors.Is(err, store.ErrDeadlinePassed):
+		return http.StatusConflict, Error{Code: DeadlinePassed, Message: i18n.T(lang, i18n.MsgDeadlinePassed)}
+	case errors.Is(err, store.ErrInvalidTransition):
+		return http.StatusConflict, Error{Code: InvalidTransition, Message: i18n.T(lang, i18n.MsgAcceptedOnly)}
+	default:
+		return http.StatusInternalServerError, Error{Code: InternalError, Message: i18n.T(lang, i18n.MsgInternalError)}
+	}
+}
+
+// nameErrors builds an Error body listing each additional guest name that
+// breaks the name rules.
+func nameErrors(lang language.Tag, violations []names.Violation) Error {
+	fields := make([]FieldError, 0, len(violations))
+	for _, v := range violations {
+		key := i18n.MsgFieldPattern
+		switch v.Constraint {
+		case names.ConstraintMinLength:
+			key = i18n.MsgFieldTooShort
+		case names.ConstraintMaxLength:
+			key = i18n.MsgFieldTooLong
+		}
+		fields = append(fields, FieldError{
+			Location:   Body,
+			Pointer:    "/additional/" + strconv.Itoa(v.Index),
+			Constraint: v.Constraint,
+			Message:    i18n.T(lang, key),
+		})
+	}
+	return Error{Code: ValidationFailed, Message: i18n.T(lang, i18n.MsgValidationFailed), Fields: &fields}
+}
+
+// fieldError builds an Error body for a single offending field.
+func fieldError(code ErrorCode, f FieldError) Error {
+	return Error{Code: code, Message: f.Message, Fields: &[]FieldError{f}}
+}
+
+// inviteLanguage returns the language to answer in for rec: its preferred
+// language when set, otherwise the one negotiated from Accept-Language.
+func inviteLanguage(c *gin.Context, rec *store.InviteRecord) language.Tag {
+	lang, ok := i18n.Parse(rec.Language)
+	if !ok {
+		return i18n.FromContext(c.Request.Context())
+	}
+	c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+	c.Header("Content-Language", lang.String())
+	return lang
+}
+
+// errorLanguage returns the language to report a failed update of invite id
+// in: its own when it has one, as inviteLanguage does, otherwise the
+// request's. It reads the invite, so it is only called once the update has
+// failed; a missing or unreadable invite falls back to the request's.
+func (h *Handler) errorLanguage(c *gin.Context, id string) language.Tag {
+	rec, err := h.store.GetInvite(c.Request.Context(), id)
+	if err != nil {
+		return i18n.FromContext(c.Request.Context())
+	}
+	return inviteLanguage(c, rec)
 }
 
-func recordToInvite(r *store.InviteRecord) Invite {
+func recordToInvite(r *store.InviteRecord, lang language.Tag) Invite {
 	inv := Invite{
 		People:          r.People,
 		AdditionalCount: r.AdditionalCount,
 		IsAccepted:      r.Accepted,
-		IsOpened:        len(r.ViewedAt) > 0,
+		IsOpened:        r.Views.Opened(),
+		Language:        InviteLanguage(lang.String()),
 	}
 	if len(r.Additional) > 0 {
 		inv.Additional = &r.Additional
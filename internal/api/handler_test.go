@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"path/filepath"
 	"testing"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
 
+	"github.com/dimitarkovachev/wedding/internal/middleware"
+	"github.com/dimitarkovachev/wedding/internal/names"
 	"github.com/dimitarkovachev/wedding/internal/store"
 )
 
@@ -17,17 +19,61 @@ func init() {
 	gin.SetMode(gin.TestMode)
 }
 
-func setupTestRouter(t *testing.T) *gin.Engine {
+// testSpec returns the embedded spec with rules applied.
+func testSpec(t *testing.T, rules names.Rules) *openapi3.T {
+	t.Helper()
+
+	spec, err := GetSwagger()
+	if err != nil {
+		t.Fatalf("failed to load embedded spec: %v", err)
+	}
+	if err := rules.ApplyToSpec(spec); err != nil {
+		t.Fatalf("failed to apply name rules: %v", err)
+	}
+	return spec
+}
+
+// newTestEngine returns an engine that fails any response violating spec, so
+// every handler test also checks the response schema.
+func newTestEngine(t *testing.T, spec *openapi3.T) *gin.Engine {
 	t.Helper()
 
-	dbPath := filepath.Join(t.TempDir(), "test.db")
-	s, err := store.NewBBoltStore(dbPath)
+	mw, err := middleware.NewOpenAPIResponseValidator(spec, middleware.ResponseValidationFail)
 	if err != nil {
-		t.Fatalf("failed to create store: %v", err)
+		t.Fatalf("failed to create response validator: %v", err)
 	}
-	t.Cleanup(func() { s.Close() })
 
-	err = s.Seed(map[string]store.InviteRecord{
+	r := gin.New()
+	r.Use(middleware.NewLanguageDetector())
+	r.Use(mw)
+	return r
+}
+
+func setupTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	return setupTestRouterWithRules(t, names.DefaultRules())
+}
+
+func setupTestRouterWithRules(t *testing.T, rules names.Rules) *gin.Engine {
+	t.Helper()
+	return setupTestRouterWithStore(t, rules, seedTestStore(t))
+}
+
+func setupTestRouterWithStore(t *testing.T, rules names.Rules, s store.InviteStore) *gin.Engine {
+	t.Helper()
+
+	spec := testSpec(t, rules)
+	h := NewHandler(s, rules, spec)
+	r := newTestEngine(t, spec)
+	RegisterHandlers(r, h)
+	return r
+}
+
+func seedTestStore(t *testing.T, opts ...store.Option) *store.MemoryStore {
+	t.Helper()
+
+	s := store.NewMemoryStore(opts...)
+	err := s.Seed(t.Context(), map[string]store.InviteRecord{
 		"550e8400-e29b-41d4-a716-446655440000": {
 			People:          []string{"Иван Петров", "Мария Петрова"},
 			AdditionalCount: 2,
@@ -38,15 +84,16 @@ func setupTestRouter(t *testing.T) *gin.Engine {
 			AdditionalCount: 0,
 			Accepted:        false,
 		},
+		"550e8400-e29b-41d4-a716-446655440002": {
+			People:          []string{"Джон Смит"},
+			AdditionalCount: 1,
+			Language:        "en",
+		},
 	})
 	if err != nil {
 		t.Fatalf("failed to seed: %v", err)
 	}
-
-	h := NewHandler(s)
-	r := gin.New()
-	RegisterHandlers(r, h)
-	return r
+	return s
 }
 
 func TestHandler_GetHealth(t *testing.T) {
@@ -95,6 +142,35 @@ func TestHandler_GetInvite_Found(t *testing.T) {
 	}
 }
 
+func TestHandler_GetInvite_BotViewsDoNotOpen(t *testing.T) {
+	r := setupTestRouter(t)
+	const path = "/invites/550e8400-e29b-41d4-a716-446655440000"
+
+	get := func(userAgent string) Invite {
+		t.Helper()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", userAgent)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var inv Invite
+		if err := json.NewDecoder(w.Body).Decode(&inv); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		return inv
+	}
+
+	get("facebookexternalhit/1.1")
+	if get("Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0").IsOpened {
+		t.Fatal("expected a bot view not to open the invite")
+	}
+	if !get("Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0").IsOpened {
+		t.Fatal("expected the browser view to open the invite")
+	}
+}
+
 func TestHandler_GetInvite_NotFound(t *testing.T) {
 	r := setupTestRouter(t)
 
@@ -107,6 +183,43 @@ func TestHandler_GetInvite_NotFound(t *testing.T) {
 	}
 }
 
+func TestHandler_GetInvite_Language(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		acceptLanguage string
+		want           InviteLanguage
+	}{
+		{"default is Bulgarian", "550e8400-e29b-41d4-a716-446655440000", "", Bg},
+		{"Accept-Language", "550e8400-e29b-41d4-a716-446655440000", "en-US,en;q=0.9", En},
+		{"invite preference wins", "550e8400-e29b-41d4-a716-446655440002", "bg", En},
+	}
+
+	r := setupTestRouter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/invites/"+tt.id, nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Language"); got != string(tt.want) {
+				t.Fatalf("expected Content-Language %s, got %q", tt.want, got)
+			}
+			var inv Invite
+			if err := json.NewDecoder(w.Body).Decode(&inv); err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			if inv.Language != tt.want {
+				t.Fatalf("expected language %s, got %s", tt.want, inv.Language)
+			}
+		})
+	}
+}
+
 func TestHandler_PutInvite_AcceptNoAdditionals(t *testing.T) {
 	r := setupTestRouter(t)
 
@@ -164,6 +277,7 @@ func TestHandler_PutInvite_AcceptedFalse(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
 	}
+	assertFieldError(t, w.Body.Bytes(), ValidationFailed, "/isAccepted")
 }
 
 func TestHandler_PutInvite_NotFound(t *testing.T) {
@@ -194,4 +308,119 @@ func TestHandler_PutInvite_TooManyAdditionals(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
 	}
+	assertFieldError(t, w.Body.Bytes(), TooManyGuests, "/additional/0")
+}
+
+// assertFieldError checks that body is an Error with the given code and a
+// single field error at pointer.
+func assertFieldError(t *testing.T, body []byte, code ErrorCode, pointer string) {
+	t.Helper()
+
+	var resp Error
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if resp.Code != code {
+		t.Fatalf("expected code %q, got %q", code, resp.Code)
+	}
+	if resp.Fields == nil || len(*resp.Fields) != 1 {
+		t.Fatalf("expected 1 field error, got %v", resp.Fields)
+	}
+	f := (*resp.Fields)[0]
+	if f.Location != Body || f.Pointer != pointer {
+		t.Fatalf("expected body field at %q, got %s %q", pointer, f.Location, f.Pointer)
+	}
+}
+
+func TestHandler_PutInvite_NameRules(t *testing.T) {
+	mixed, err := names.NewRules([]string{"Cyrillic", "Latin"}, " -'", 20)
+	if err != nil {
+		t.Fatalf("failed to build rules: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		rules      names.Rules
+		additional []string
+		want       int
+		pointer    string
+	}{
+		{"default accepts cyrillic", names.DefaultRules(), []string{"Георги Иванов"}, http.StatusOK, ""},
+		{"default rejects latin", names.DefaultRules(), []string{"Иван", "John Smith"}, http.StatusBadRequest, "/additional/1"},
+		{"mixed accepts latin and apostrophes", mixed, []string{"John O'Brien", "Мария"}, http.StatusOK, ""},
+		{"mixed rejects long names", mixed, []string{"Максимилиан Александров"}, http.StatusBadRequest, "/additional/0"},
+		{"rejects empty names", names.DefaultRules(), []string{""}, http.StatusBadRequest, "/additional/0"},
+		{"rejects names of only spaces", names.DefaultRules(), []string{"Иван", "   "}, http.StatusBadRequest, "/additional/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupTestRouterWithRules(t, tt.rules)
+
+			body, _ := json.Marshal(InviteUpdate{IsAccepted: true, Additional: &tt.additional})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440000", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if tt.pointer != "" {
+				assertFieldError(t, w.Body.Bytes(), ValidationFailed, tt.pointer)
+			}
+		})
+	}
+}
+
+func TestHandler_GetOpenAPISpec_ReflectsRules(t *testing.T) {
+	rules, err := names.NewRules([]string{"Cyrillic", "Latin"}, " '", 50)
+	if err != nil {
+		t.Fatalf("failed to build rules: %v", err)
+	}
+	r := setupTestRouterWithRules(t, rules)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	spec, err := openapi3.NewLoader().LoadFromData(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse served spec: %v", err)
+	}
+	schema := spec.Components.Schemas[names.SchemaName].Value
+	if schema.Pattern != rules.Pattern() {
+		t.Fatalf("expected pattern %s, got %s", rules.Pattern(), schema.Pattern)
+	}
+	if schema.MaxLength == nil || *schema.MaxLength != 50 {
+		t.Fatalf("expected maxLength 50, got %v", schema.MaxLength)
+	}
+}
+
+func TestHandler_PutInvite_DuplicateGuest(t *testing.T) {
+	tests := []struct {
+		name       string
+		additional []string
+		pointer    string
+	}{
+		{"repeated plus-one", []string{"Георги Димитров", "георги димитров"}, "/additional/1"},
+		{"plus-one already invited", []string{"иван  петров"}, "/additional/0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupTestRouter(t)
+
+			body, _ := json.Marshal(InviteUpdate{IsAccepted: true, Additional: &tt.additional})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440000", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			assertFieldError(t, w.Body.Bytes(), DuplicateGuest, tt.pointer)
+		})
+	}
 }
@@ -19,11 +19,72 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for ErrorCode.
+const (
+	DeadlinePassed       ErrorCode = "deadline_passed"
+	DuplicateGuest       ErrorCode = "duplicate_guest"
+	IdempotencyKeyReused ErrorCode = "idempotency_key_reused"
+	InternalError        ErrorCode = "internal_error"
+	InvalidBody          ErrorCode = "invalid_body"
+	InvalidTransition    ErrorCode = "invalid_transition"
+	NotFound             ErrorCode = "not_found"
+	RateLimited          ErrorCode = "rate_limited"
+	RouteNotFound        ErrorCode = "route_not_found"
+	Timeout              ErrorCode = "timeout"
+	TooManyGuests        ErrorCode = "too_many_guests"
+	ValidationFailed     ErrorCode = "validation_failed"
+)
+
+// Defines values for FieldErrorLocation.
+const (
+	Body   FieldErrorLocation = "body"
+	Header FieldErrorLocation = "header"
+	Path   FieldErrorLocation = "path"
+	Query  FieldErrorLocation = "query"
+)
+
+// Defines values for InviteLanguage.
+const (
+	Bg InviteLanguage = "bg"
+	En InviteLanguage = "en"
+)
+
 // Error defines model for Error.
 type Error struct {
+	// Code Machine readable error category
+	Code ErrorCode `json:"code"`
+
+	// Fields Individual violations, present when the error concerns specific fields
+	Fields *[]FieldError `json:"fields,omitempty"`
+
+	// Message Human readable summary of the error
+	Message string `json:"message"`
+}
+
+// ErrorCode Machine readable error category
+type ErrorCode string
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Constraint Violated constraint, usually the OpenAPI keyword such as pattern, minLength, maxItems or required
+	Constraint string `json:"constraint"`
+
+	// Location Part of the request the field belongs to
+	Location FieldErrorLocation `json:"location"`
+
+	// Message Human readable description of the violation
 	Message string `json:"message"`
+
+	// Pointer JSON pointer (RFC 6901) to the offending value within its location, e.g. /additional/2 for the third additional guest
+	Pointer string `json:"pointer"`
 }
 
+// FieldErrorLocation Part of the request the field belongs to
+type FieldErrorLocation string
+
+// GuestName Name of an additional guest. The pattern and maxLength below are the defaults; the server replaces them with its configured name rules and publishes the active spec at /openapi.json.
+type GuestName = string
+
 // HealthResponse defines model for HealthResponse.
 type HealthResponse struct {
 	Status string `json:"status"`
@@ -31,17 +92,38 @@ type HealthResponse struct {
 
 // Invite defines model for Invite.
 type Invite struct {
-	Additional      *[]string `json:"additional,omitempty"`
-	AdditionalCount int       `json:"additionalCount"`
-	IsAccepted      bool      `json:"isAccepted"`
-	IsOpened        bool      `json:"isOpened"`
-	People          []string  `json:"people"`
+	Additional      *[]GuestName `json:"additional,omitempty"`
+	AdditionalCount int          `json:"additionalCount"`
+	IsAccepted      bool         `json:"isAccepted"`
+	IsOpened        bool         `json:"isOpened"`
+
+	// Language Language messages for this invite are given in; send it as Accept-Language on follow-up requests
+	Language InviteLanguage `json:"language"`
+	People   []string       `json:"people"`
 }
 
+// InviteLanguage Language messages for this invite are given in; send it as Accept-Language on follow-up requests
+type InviteLanguage string
+
 // InviteUpdate defines model for InviteUpdate.
 type InviteUpdate struct {
-	Additional *[]string `json:"additional,omitempty"`
-	IsAccepted bool      `json:"isAccepted"`
+	Additional *[]GuestName `json:"additional,omitempty"`
+	IsAccepted bool         `json:"isAccepted"`
+}
+
+// ServerError defines model for ServerError.
+type ServerError = Error
+
+// TooManyRequests defines model for TooManyRequests.
+type TooManyRequests = Error
+
+// Unavailable defines model for Unavailable.
+type Unavailable = Error
+
+// PutInviteParams defines parameters for PutInvite.
+type PutInviteParams struct {
+	// IdempotencyKey Makes the request safe to retry. The first response for a key is kept for IDEMPOTENCY_TTL; a retry with the same key and body gets it again without the reply being saved twice.
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
 }
 
 // PutInviteJSONRequestBody defines body for PutInvite for application/json ContentType.
@@ -57,7 +139,10 @@ type ServerInterface interface {
 	GetInvite(c *gin.Context, id openapi_types.UUID)
 	// Accept an invite
 	// (PUT /invites/{id})
-	PutInvite(c *gin.Context, id openapi_types.UUID)
+	PutInvite(c *gin.Context, id openapi_types.UUID, params PutInviteParams)
+	// OpenAPI specification with the active name rules
+	// (GET /openapi.json)
+	GetOpenAPISpec(c *gin.Context)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -120,6 +205,43 @@ func (siw *ServerInterfaceWrapper) PutInvite(c *gin.Context) {
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PutInviteParams
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Idempotency-Key, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Idempotency-Key: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PutInvite(c, id, params)
+}
+
+// GetOpenAPISpec operation middleware
+func (siw *ServerInterfaceWrapper) GetOpenAPISpec(c *gin.Context) {
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -127,7 +249,7 @@ func (siw *ServerInterfaceWrapper) PutInvite(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.PutInvite(c, id)
+	siw.Handler.GetOpenAPISpec(c)
 }
 
 // GinServerOptions provides options for the Gin server.
@@ -160,22 +282,43 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 	router.GET(options.BaseURL+"/health", wrapper.GetHealth)
 	router.GET(options.BaseURL+"/invites/:id", wrapper.GetInvite)
 	router.PUT(options.BaseURL+"/invites/:id", wrapper.PutInvite)
+	router.GET(options.BaseURL+"/openapi.json", wrapper.GetOpenAPISpec)
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xUT2/bPgz9KgZ/vf282F27i29dN3QBBqzYMOzQdIBqMY46W1IlqlsQ+LsPlNw2zp/u",
-	"0nU9mQkp8vHxkSuoTWeNRk0eqhX4eoGdiOZ754xjwzpj0ZHC+HeH3osG2aSlRajAk1O6gb7PweFNUA4l",
-	"VBf3gZf5XaC5usaaoM/hA4qWFp/RW6M9bhfxJCj4P9cY4naVmOpbRTtSCykVKaNFy78UYZfaUvoj6oYW",
-	"UB3mYAUROg0VfL+YzezqdOlU26q6z2azV5f/H0C+CSyHTvyapmxv7r3CObFk50PVUxM0rXWmNGGDjoOU",
-	"P6lrtMS93fuvjGlR6OT/ZFHv81o0tsVRU1sYx7A2yBwSbIMdIVuDsZ/2r1aKl0P+47xusLAWvN0fBys9",
-	"N5xHoq+dstwOVHByPs3mxmWd0KJRusl+opT8VUyI4CjPwBXxjODb4E10ZSfnU8jhFp1P2Q4n5aRk6Mai",
-	"FlZBBUeTcnIEkZ1FJK1YxCVis8GoKGY6VppKqOAMKa0ZcINp0+LD12XJn9powiRFYW2r6vi0uPaM4O4S",
-	"sHXgcA4V/Fc8nIpiuBPFxiJHgsbEfEF3q2rMlM8S4CQ8H7pOuCVUwy3I6gXWP6KriJShL1ZK9o+1N6w4",
-	"c+JEh4TOQ3WxAsV1mSfIQYsubpmE9TGTC5ivtTg3rhMEFYQQIzevzuVfpHDoYgd1gzjmJmjJYjguj5+s",
-	"arru+4tqQ3eFR9M6Q8qETqrG7GqZTd/F4xN2jOg8PPeIbgJ6emvk8omnM9yzfnwsGGL/L5URIqxBG+Wz",
-	"aEO0SmYD0S9Fk+lgP8iSU/S/AwAA//+7LRHC2ggAAA==",
+	"H4sIAAAAAAAC/9RYf2/cuBH9KgP2gCaovLtJLgWy+Svn8+W2jRPXdq4o4nTBFUcSzxSpI6ndE4z97sWQ",
+	"lPZnnABNfLj/JJEaDt88vpnhHctN3RiN2js2vWMWXWO0w/ByhXaJ9sxaY+k1N9qj9vTIm0bJnHtp9PhX",
+	"ZzR9c3mFNaen7ywWbMr+Mt7YHsdRN47W1ut1xgS63MqGjLApm2mPVnMFSDNGcF0h1OgcLxE0LtGC1Llq",
+	"BTrwFUKrBVrVSV1CzluHGawqmVcgHRitOlCmLFGM2Dpj18acc91d4m8tOu++/VYuuUdQspYe8PccUaBg",
+	"GauQC7Rh+Uv0tjt5VXgMwO7+fYW50cJBq71UwMFGv6HmHSwQLHorg8GNl75rkE2Z1B5LDC6tM/Ze8yWX",
+	"ii8UfvstU7R6Ry3X0HDn4fLsX+/Prq7n17Pzs3fvr+GRlzWa1j9mZCDZpCUHijXWNGi9xBQlgV/k0SlN",
+	"XGeskKiEO4R0poVcStFyBUtpVNi5y6Cx6FB7WFWoA6kC9SA3OkerHbgGc1nIHJLdjEmPtfucSz/R7IRU",
+	"1seGW8s7ek+cPnTy57bmGixyQSED19Y1tx2YYuMaG8w5b6UuA44Eu7Qo2PRDRGyzxsdhvln8irmn9TeA",
+	"HXhwzvNKatz4kPDgHktjO5Yx1G1N6yy5kiLAOC+4VIGOUoev84URNFUbPy9Mq2nImtbjfPuLN2Zec93N",
+	"y3gmMybaSEqMn7YMesu1k8FHoh0XSmqcN9y5sK6lf8JpC6+JY/S/wLoxHnXezW+xm1tsXfI0Ss08gvrx",
+	"ANWMbcXwCC2185ZL7Q8h/CXQCwVsJmXQupYr1YU4vmtQv7qYwS12K2MFuDavgDtouCenMqilfoO69FUG",
+	"Nf99RoQDY2GI8hFnlYmH+dCdC259z6D+eNJzIDQsUBldOvBmK7QpfA33FcvYby3abhCvo1B9KaG3BnuX",
+	"hsN4bFeNCXE6tPuPq3dvIY3Co8ufTuHvLyZPHoM3wagpCtSCEsOSqxZhJX0lNUjvoAcqAxyVIxhzIQKv",
+	"uBo/hcLY8L+vpBWwGYKej/cfvSEIG8+zbarcfypf0xpveX0ERvpKgHF94FTMkok6wLUgykT2hOCugFsM",
+	"mxJY8FZ59zK8uZDYwWKjeB4Tah1wCijlRheybC0K0LS2bRW6YL1pF0q6Kv4BPPdyiUElgXsYmwY1b+SI",
+	"UsqI9tv7wqZPJpOMDcxm0ycZS16zKfvvh5ub5u60s1Ipma/h5ubk49++O0aJn5ErX12mGuXwZDrPfeu2",
+	"MuInYpXmHQvETC+lP2J6Az29fVEe2MR0nbH+LLPp88OcsDF+atooK/spPWPSvcpzbDyKrfGFMQq5juOk",
+	"LZ8aVVyX7dFj+iaN9NWWSydBOpABjECiUi5Rg9QvwaEWID2JVnToZLBgNBRGKbM6aZteb9y2tpTh5aiM",
+	"NGgahTvgHszZxW0vqMnAIZo70G3htAXKp5nwvhH8wflwf6j39r01+XAbNFnqwhyGnZIQBbrmmpeklisU",
+	"QTVD0GOJNILznhNSp2pg6BB2aAE/tKrkVnJNyepMl6QTGeSVcaihsKYOkhFs418dNBYLtCQxaiBPlN99",
+	"TsXMA482Cyy6Xs4eRwEcTFB+pw7Aom+tpudY153G2nff6CiUC55Yx/6ddh+jDq8uZixjS7QuovVkNBlN",
+	"KDRJ5NiUPRtNRs9SpgyxH1dBnuixxHCIiTAByZlgU/YafRQwlu32WU8nk69Woe9J5JFSnbo6mSPhFB2O",
+	"RynVm5S3w1fIK8xvw9A4hs2N76RY37e9JJ6EieU1+tDufLhjktZNFYUOSY5JwbZp7G2L2x1NYWzNPZuy",
+	"tpVHap71x28IYdrF0R6VRiAWseuMff8VV72nMQ5lcC/Gsx/jyt8/yMq0ojZ+a8tPX3zK3hCQ8X7Hvc7Y",
+	"88nk8/9tXziEf559/p/tNneXx6/RU9GUcFt0CbqmPULei/YByZsdNl63qabqi3THC6SC1qK3XVS5Qlrn",
+	"B/kN2s2pjaCDfIuND19mP56dX7y7Pnt7+p/59fWbl+H+wNsuVne0gqOSjn6jgo6KfSjRu5DQSy51mGha",
+	"n7xpFF06kDA6vkQBfiVzHIUeik37rmDAZbbpuE7+id3OHcVWNfj0+fP9avDo+Q5Q/ED9yNc92imnr9fr",
+	"/Siu/0hZaYNbe1dFA6T+5JLK9S7WBLsGbhg5f8M2NxkDTWTkldGYIti3OXuxAst9hTREfQZoXCV/jl00",
+	"DWFaP7QIJlL8sRI4efFQF2p0/HKuafkFNVyxzuvLmqGayltrUXtwnntMzS1NuLz65QL6GxOowjWDcyjg",
+	"0d41yuMo7U8fZl/71FtxB1xZ5KKLJVzUNiGLAsO+elV8dPxK5/GfLDHFEneTm8LwTvt8X5GVbpCuGsz/",
+	"30LySLOwHy25uQYNVqjtowuYvS0lp/bmDkknXRVsrhOCdvxvAHmxtQd9GAAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
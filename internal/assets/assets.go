@@ -0,0 +1,246 @@
+// Package assets serves UI files with content-hash ETags and cache headers.
+// Pages are rendered as html/template with an asset function that links to
+// files by content hash, so browsers may cache those links forever.
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hashLen is how many hex digits of the SHA-256 appear in ETags and URLs.
+const hashLen = 16
+
+const (
+	cacheImmutable   = "public, max-age=31536000, immutable"
+	cacheRevalidate  = "no-cache"
+	versionParameter = "v"
+)
+
+type file struct {
+	body        []byte
+	hash        string
+	contentType string
+}
+
+// Server serves the files of one directory tree under a URL prefix.
+type Server struct {
+	fsys     fs.FS
+	prefix   string
+	required []string
+	dev      bool
+
+	mu    sync.RWMutex
+	files map[string]file
+}
+
+// New loads every file in fsys and fails if any required path is missing.
+// Files are served under prefix, e.g. "/ui/". In dev mode the tree is
+// reloaded on every request so edits show up without a restart.
+func New(fsys fs.FS, prefix string, required []string, dev bool) (*Server, error) {
+	s := &Server{fsys: fsys, prefix: prefix, required: required, dev: dev}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) load() error {
+	files := make(map[string]file)
+	err := fs.WalkDir(s.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(s.fsys, p)
+		if err != nil {
+			return fmt.Errorf("reading asset %s: %w", p, err)
+		}
+		sum := sha256.Sum256(body)
+		contentType := mime.TypeByExtension(path.Ext(p))
+		if contentType == "" {
+			contentType = http.DetectContentType(body)
+		}
+		files[p] = file{body: body, hash: hex.EncodeToString(sum[:])[:hashLen], contentType: contentType}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("loading assets: %w", err)
+	}
+
+	var missing []string
+	for _, name := range s.required {
+		if _, ok := files[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required assets: %s", strings.Join(missing, ", "))
+	}
+
+	s.mu.Lock()
+	s.files = files
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Server) lookup(name string) (file, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.files[name]
+	return f, ok
+}
+
+// Dev reports whether files are reloaded on every request.
+func (s *Server) Dev() bool {
+	return s.dev
+}
+
+// refresh reloads the tree in dev mode; otherwise it does nothing.
+func (s *Server) refresh() error {
+	if !s.dev {
+		return nil
+	}
+	return s.load()
+}
+
+// URL returns the versioned URL of a file, e.g. "/ui/app.js?v=1a2b...".
+func (s *Server) URL(name string) (string, error) {
+	f, ok := s.lookup(name)
+	if !ok {
+		return "", fmt.Errorf("unknown asset %q", name)
+	}
+	return s.prefix + name + "?" + versionParameter + "=" + f.hash, nil
+}
+
+// File serves the file named by the "filepath" route parameter. Requests
+// carrying the current content hash are cacheable forever; others must
+// revalidate with the ETag.
+func (s *Server) File(c *gin.Context) {
+	if err := s.refresh(); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	f, ok := s.lookup(strings.TrimPrefix(c.Param("filepath"), "/"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	cache := cacheRevalidate
+	if c.Query(versionParameter) == f.hash && !s.dev {
+		cache = cacheImmutable
+	}
+	serve(c, f.body, f.hash, f.contentType, cache)
+}
+
+// Page returns a handler rendering the named file as an html/template with
+// an asset function for versioned file URLs, e.g. {{asset "app.js"}}.
+func (s *Server) Page(name string) (gin.HandlerFunc, error) {
+	// Reason: render once up front so template errors fail startup, not requests
+	body, err := s.render(name)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])[:hashLen]
+
+	return func(c *gin.Context) {
+		body, hash := body, hash
+		if s.dev {
+			if err := s.refresh(); err != nil {
+				c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+			b, err := s.render(name)
+			if err != nil {
+				c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+			sum := sha256.Sum256(b)
+			body, hash = b, hex.EncodeToString(sum[:])[:hashLen]
+		}
+		serve(c, body, hash, "text/html; charset=utf-8", cacheRevalidate)
+	}, nil
+}
+
+func (s *Server) render(name string) ([]byte, error) {
+	tmpl, err := s.parse(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return nil, fmt.Errorf("rendering page %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Template parses the named file as an html/template with the asset
+// function and funcs, for pages rendered per request. In dev mode the tree
+// is reloaded first.
+func (s *Server) Template(name string, funcs template.FuncMap) (*template.Template, error) {
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s.parse(name, funcs)
+}
+
+func (s *Server) parse(name string, funcs template.FuncMap) (*template.Template, error) {
+	f, ok := s.lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown page %q", name)
+	}
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"asset": s.URL}).Funcs(funcs).Parse(string(f.body))
+	if err != nil {
+		return nil, fmt.Errorf("parsing page %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// serve writes body with a strong ETag, answering matching conditional
+// requests with 304.
+func serve(c *gin.Context, body []byte, hash, contentType, cache string) {
+	etag := `"` + hash + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cache)
+	if matchesETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag || candidate == "*" || candidate == "W/"+etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNoAssets is returned by Sub when dir does not exist in fsys.
+var ErrNoAssets = errors.New("asset directory not found")
+
+// Sub returns the dir subtree of fsys, failing clearly when it is absent,
+// e.g. because a development override directory is wrong.
+func Sub(fsys fs.FS, dir string) (fs.FS, error) {
+	info, err := fs.Stat(fsys, dir)
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNoAssets)
+	}
+	return fs.Sub(fsys, dir)
+}
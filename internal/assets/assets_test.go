@@ -0,0 +1,173 @@
+package assets
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/web"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html": {Data: []byte(`<script src="{{asset "app.js"}}"></script>`)},
+		"app.js":     {Data: []byte("console.log('hi');")},
+	}
+}
+
+func newTestRouter(t *testing.T, s *Server) *gin.Engine {
+	t.Helper()
+
+	page, err := s.Page("index.html")
+	if err != nil {
+		t.Fatalf("failed to render page: %v", err)
+	}
+	r := gin.New()
+	r.GET("/", page)
+	r.GET("/ui/*filepath", s.File)
+	return r
+}
+
+func get(r *gin.Engine, target, ifNoneMatch string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestNew_MissingRequired(t *testing.T) {
+	_, err := New(testFS(), "/ui/", []string{"index.html", "editor.js"}, false)
+	if err == nil || !strings.Contains(err.Error(), "editor.js") {
+		t.Fatalf("expected missing editor.js error, got %v", err)
+	}
+}
+
+func TestSub_MissingDir(t *testing.T) {
+	if _, err := Sub(testFS(), "admin"); !errors.Is(err, ErrNoAssets) {
+		t.Fatalf("expected ErrNoAssets, got %v", err)
+	}
+}
+
+func TestServer_CachingHeaders(t *testing.T) {
+	s, err := New(testFS(), "/ui/", []string{"index.html", "app.js"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := newTestRouter(t, s)
+
+	url, err := s.URL("app.js")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := get(r, "/", "")
+	if !strings.Contains(w.Body.String(), url) {
+		t.Fatalf("expected page to link %s, got %s", url, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != cacheRevalidate {
+		t.Fatalf("expected page Cache-Control %q, got %q", cacheRevalidate, cc)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		cache  string
+	}{
+		{"versioned", url, http.StatusOK, cacheImmutable},
+		{"unversioned", "/ui/app.js", http.StatusOK, cacheRevalidate},
+		{"stale version", "/ui/app.js?v=old", http.StatusOK, cacheRevalidate},
+		{"missing", "/ui/nope.js", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := get(r, tt.target, "")
+		if w.Code != tt.status {
+			t.Fatalf("%s: expected %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != tt.cache {
+			t.Fatalf("%s: expected Cache-Control %q, got %q", tt.name, tt.cache, cc)
+		}
+	}
+
+	w = get(r, "/ui/app.js", "")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+		t.Fatalf("expected javascript content type, got %q", ct)
+	}
+	etag := w.Header().Get("ETag")
+	if w = get(r, "/ui/app.js", etag); w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", w.Code)
+	}
+	if w = get(r, "/", `"other"`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for other ETag, got %d", w.Code)
+	}
+}
+
+func TestServer_DevReloads(t *testing.T) {
+	fsys := testFS()
+	s, err := New(fsys, "/ui/", nil, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := newTestRouter(t, s)
+
+	before := get(r, "/ui/app.js", "").Header().Get("ETag")
+	fsys["app.js"] = &fstest.MapFile{Data: []byte("console.log('changed');")}
+
+	w := get(r, "/ui/app.js", before)
+	if w.Code != http.StatusOK || w.Body.String() != "console.log('changed');" {
+		t.Fatalf("expected changed file, got %d %q", w.Code, w.Body.String())
+	}
+	if url, _ := s.URL("app.js"); !strings.Contains(get(r, "/", "").Body.String(), url) {
+		t.Fatalf("expected page to link the new version %s", url)
+	}
+}
+
+func TestEmbeddedAdminUI(t *testing.T) {
+	fsys, err := Sub(web.FS, "admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, err := New(fsys, "/ui/", []string{"index.html", "app.js", "editor.js"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Page("index.html"); err != nil {
+		t.Fatalf("expected admin index to render, got %v", err)
+	}
+}
+
+func TestServer_Template(t *testing.T) {
+	s, err := New(testFS(), "/ui/", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tmpl, err := s.Template("index.html", template.FuncMap{"upper": strings.ToUpper})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url, _ := s.URL("app.js"); !strings.Contains(b.String(), url) {
+		t.Fatalf("expected template to link %s, got %s", url, b.String())
+	}
+
+	if _, err := s.Template("missing.html", nil); err == nil {
+		t.Fatal("expected error for unknown template")
+	}
+}
@@ -3,30 +3,138 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
-	Port           string
-	AdminPort      string
-	DBPath         string
-	SeedFile       string
-	WebDir         string
-	RateLimitRPS   float64
-	RateLimitBurst int
-	GinMode        string
+	Port                 string
+	AdminPort            string
+	StoreDriver          string
+	DBPath               string
+	SeedFile             string
+	WebDir               string
+	PublicURL            string
+	ReadHeaderTimeout    time.Duration
+	ReadTimeout          time.Duration
+	WriteTimeout         time.Duration
+	IdleTimeout          time.Duration
+	RequestTimeout       time.Duration
+	ShutdownTimeout      time.Duration
+	IdempotencyTTL       time.Duration
+	RateLimitRPS         float64
+	RateLimitBurst       int
+	RateLimitInviteRPS   float64
+	RateLimitInviteBurst int
+	RateLimitPolicyFile  string
+	RateLimitMaxVisitors int
+	GinMode              string
+	TraceExporter        string
+	TraceFile            string
+	ServiceName          string
+	ResponseValidation   string
+	NameAllowedScripts   string
+	NameExtraChars       string
+	NameMaxLength        int
+	WeddingCouple        string
+	WeddingDate          string
+	WeddingVenue         string
+	WeddingAddress       string
+	WeddingMapURL        string
+	RSVPDeadline         string
+	ViewDedupeWindow     time.Duration
+	ViewHistoryLimit     int
+	ViewQueueSize        int
+	ViewBatchSize        int
+	ViewFlushInterval    time.Duration
+	WebhookTimeout       time.Duration
+	WebhookMaxAttempts   int
+	WebhookRetryBase     time.Duration
+	WebhookPollInterval  time.Duration
+	WebhookLogRetention  time.Duration
+	SMTPHost             string
+	SMTPPort             int
+	SMTPUsername         string
+	SMTPPassword         string
+	SMTPFrom             string
+	SMTPStartTLS         bool
+	SMTPTimeout          time.Duration
+	NotifyEmails         string
+	NotifyLanguage       string
+	NotifyInstant        bool
+	NotifyDigestTime     string
+	NotifyTimezone       string
 }
 
 func Load() *Config {
+	ginMode := envOrDefault("GIN_MODE", "release")
+
 	return &Config{
-		Port:           envOrDefault("PORT", "8080"),
-		AdminPort:      envOrDefault("ADMIN_PORT", "9090"),
-		DBPath:         envOrDefault("DB_PATH", "/data/wedding.db"),
-		SeedFile:       os.Getenv("SEED_FILE"),
-		WebDir:         envOrDefault("WEB_DIR", "web"),
-		RateLimitRPS:   envOrDefaultFloat("RATE_LIMIT_RPS", 1),
-		RateLimitBurst: envOrDefaultInt("RATE_LIMIT_BURST", 10),
-		GinMode:        envOrDefault("GIN_MODE", "release"),
+		Port:                 envOrDefault("PORT", "8080"),
+		AdminPort:            envOrDefault("ADMIN_PORT", "9090"),
+		StoreDriver:          os.Getenv("STORE_DRIVER"),
+		DBPath:               envOrDefault("DB_PATH", "/data/wedding.db"),
+		SeedFile:             os.Getenv("SEED_FILE"),
+		WebDir:               os.Getenv("WEB_DIR"),
+		PublicURL:            os.Getenv("PUBLIC_URL"),
+		ReadHeaderTimeout:    envOrDefaultDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:          envOrDefaultDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:         envOrDefaultDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:          envOrDefaultDuration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		RequestTimeout:       envOrDefaultDuration("REQUEST_TIMEOUT", 10*time.Second),
+		ShutdownTimeout:      envOrDefaultDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		IdempotencyTTL:       envOrDefaultDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		RateLimitRPS:         envOrDefaultFloat("RATE_LIMIT_RPS", 1),
+		RateLimitBurst:       envOrDefaultInt("RATE_LIMIT_BURST", 10),
+		RateLimitInviteRPS:   envOrDefaultFloat("RATE_LIMIT_INVITE_RPS", 0.2),
+		RateLimitInviteBurst: envOrDefaultInt("RATE_LIMIT_INVITE_BURST", 5),
+		RateLimitPolicyFile:  os.Getenv("RATE_LIMIT_POLICY_FILE"),
+		RateLimitMaxVisitors: envOrDefaultInt("RATE_LIMIT_MAX_VISITORS", 10000),
+		GinMode:              ginMode,
+		TraceExporter:        envOrDefault("TRACE_EXPORTER", "none"),
+		TraceFile:            envOrDefault("TRACE_FILE", "traces.json"),
+		ServiceName:          envOrDefault("OTEL_SERVICE_NAME", "wedding"),
+		ResponseValidation:   envOrDefault("RESPONSE_VALIDATION", defaultResponseValidation(ginMode)),
+		NameAllowedScripts:   envOrDefault("NAME_ALLOWED_SCRIPTS", "Cyrillic"),
+		NameExtraChars:       envOrDefault("NAME_EXTRA_CHARS", " -"),
+		NameMaxLength:        envOrDefaultInt("NAME_MAX_LENGTH", 100),
+		WeddingCouple:        os.Getenv("WEDDING_COUPLE"),
+		WeddingDate:          os.Getenv("WEDDING_DATE"),
+		WeddingVenue:         os.Getenv("WEDDING_VENUE"),
+		WeddingAddress:       os.Getenv("WEDDING_ADDRESS"),
+		WeddingMapURL:        os.Getenv("WEDDING_MAP_URL"),
+		RSVPDeadline:         os.Getenv("RSVP_DEADLINE"),
+		ViewDedupeWindow:     envOrDefaultDuration("VIEW_DEDUPE_WINDOW", 30*time.Minute),
+		ViewHistoryLimit:     envOrDefaultInt("VIEW_HISTORY_LIMIT", 20),
+		ViewQueueSize:        envOrDefaultInt("VIEW_QUEUE_SIZE", 1024),
+		ViewBatchSize:        envOrDefaultInt("VIEW_BATCH_SIZE", 256),
+		ViewFlushInterval:    envOrDefaultDuration("VIEW_FLUSH_INTERVAL", time.Second),
+		WebhookTimeout:       envOrDefaultDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookMaxAttempts:   envOrDefaultInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetryBase:     envOrDefaultDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+		WebhookPollInterval:  envOrDefaultDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+		WebhookLogRetention:  envOrDefaultDuration("WEBHOOK_LOG_RETENTION", 7*24*time.Hour),
+		SMTPHost:             os.Getenv("SMTP_HOST"),
+		SMTPPort:             envOrDefaultInt("SMTP_PORT", 587),
+		SMTPUsername:         os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:         os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:             os.Getenv("SMTP_FROM"),
+		SMTPStartTLS:         envOrDefaultBool("SMTP_STARTTLS", true),
+		SMTPTimeout:          envOrDefaultDuration("SMTP_TIMEOUT", 10*time.Second),
+		NotifyEmails:         os.Getenv("NOTIFY_EMAILS"),
+		NotifyLanguage:       envOrDefault("NOTIFY_LANGUAGE", "bg"),
+		NotifyInstant:        envOrDefaultBool("NOTIFY_INSTANT", true),
+		NotifyDigestTime:     envOrDefault("NOTIFY_DIGEST_TIME", "08:00"),
+		NotifyTimezone:       envOrDefault("NOTIFY_TIMEZONE", "Europe/Sofia"),
+	}
+}
+
+// defaultResponseValidation turns response validation off in production and
+// logs violations in every other Gin mode.
+func defaultResponseValidation(ginMode string) string {
+	if ginMode == "release" {
+		return "off"
 	}
+	return "log"
 }
 
 func envOrDefault(key, fallback string) string {
@@ -59,3 +167,27 @@ func envOrDefaultFloat(key string, fallback float64) float64 {
 	}
 	return f
 }
+
+func envOrDefaultBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+func envOrDefaultDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
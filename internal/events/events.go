@@ -0,0 +1,95 @@
+// Package events describes what happens to invites and hands it to
+// notifiers, such as webhooks, once the change is saved.
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// Event types.
+const (
+	// InviteViewed is a guest opening their invite, counted by the store's
+	// view policy. Bots are left out.
+	InviteViewed = "invite.viewed"
+	// InviteAccepted is an invite becoming accepted, by the guests or an admin.
+	InviteAccepted = "invite.accepted"
+	// InviteDeclined is an admin marking an invite declined.
+	InviteDeclined = "invite.declined"
+	// InviteUpdated is any other change to an invite's guests or answer, such
+	// as guests changing their additional guests after accepting.
+	InviteUpdated = "invite.updated"
+)
+
+// Types lists every event type.
+var Types = []string{InviteViewed, InviteAccepted, InviteDeclined, InviteUpdated}
+
+// Event is one thing that happened to an invite.
+type Event struct {
+	// ID is unique per event and sorts by creation.
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      Data      `json:"data"`
+}
+
+// Data is what an event is about.
+type Data struct {
+	InviteID string `json:"invite_id"`
+	// Invite is the invite as saved by the change; it is left out of
+	// invite.viewed events, which only carry ViewedAt.
+	Invite   *store.InviteRecord `json:"invite,omitempty"`
+	ViewedAt *time.Time          `json:"viewed_at,omitempty"`
+}
+
+// New returns an event of typ about the invite with ID, created at now.
+func New(typ, inviteID string, now time.Time) Event {
+	return Event{
+		ID:        NewID(),
+		Type:      typ,
+		CreatedAt: now,
+		Data:      Data{InviteID: inviteID},
+	}
+}
+
+// NewID returns a UUIDv7, so IDs made later sort after earlier ones.
+func NewID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// Publisher is handed events once the change they describe is saved.
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Outbox is a Publisher that can also save the deliveries for an invite
+// change's events in the same store transaction as the change, so none are
+// lost when the process stops right after it.
+type Outbox interface {
+	Publisher
+	// Prepare reads what the outbox needs before a change is saved and
+	// returns the function that turns the change's events into deliveries.
+	// That function runs inside the store's write, so it must not call the
+	// store.
+	Prepare(ctx context.Context) (func(events []Event) ([]store.Delivery, error), error)
+	// Saved is called once deliveries made by Prepare are committed.
+	Saved()
+}
+
+// Publishers hands every event to each publisher in turn.
+type Publishers []Publisher
+
+// Publish calls every publisher, even after one fails, and returns their
+// errors joined.
+func (ps Publishers) Publish(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, p := range ps {
+		errs = append(errs, p.Publish(ctx, events...))
+	}
+	return errors.Join(errs...)
+}
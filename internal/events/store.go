@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// eventStore is a store.Store that publishes an event for every RSVP change
+// and counted view it saves.
+type eventStore struct {
+	store.Store
+	outbox Outbox
+	pub    Publisher
+	now    func() time.Time
+}
+
+// WithEvents returns s with its invite changes and views published to
+// outbox, which may be nil, and pub. The deliveries for an invite change
+// are saved in the same transaction as the change, so a failure to prepare
+// them fails it. Other publishing failures are logged and do not fail the
+// change, which is already saved.
+func WithEvents(s store.Store, outbox Outbox, pub Publisher) store.Store {
+	return &eventStore{Store: s, outbox: outbox, pub: pub, now: func() time.Time { return time.Now().UTC() }}
+}
+
+func (s *eventStore) UpdateInvite(ctx context.Context, id string, accepted bool, additional []string) (*store.InviteRecord, error) {
+	return s.change(ctx, func(outbox store.OutboxFunc) (*store.InviteRecord, error) {
+		return s.Store.UpdateInviteWithOutbox(ctx, id, accepted, additional, outbox)
+	})
+}
+
+func (s *eventStore) EditInvite(ctx context.Context, id string, edit func(*store.InviteRecord) error) (*store.InviteRecord, error) {
+	return s.change(ctx, func(outbox store.OutboxFunc) (*store.InviteRecord, error) {
+		return s.Store.EditInviteWithOutbox(ctx, id, edit, outbox)
+	})
+}
+
+// change saves an invite change with write, which is given the function
+// that finds the change's event and its deliveries inside the store's
+// write, and then publishes the event.
+func (s *eventStore) change(ctx context.Context, write func(store.OutboxFunc) (*store.InviteRecord, error)) (*store.InviteRecord, error) {
+	var deliveries func([]Event) ([]store.Delivery, error)
+	if s.outbox != nil {
+		var err error
+		if deliveries, err = s.outbox.Prepare(ctx); err != nil {
+			return nil, fmt.Errorf("preparing outbox: %w", err)
+		}
+	}
+
+	var evs []Event
+	after, err := write(func(id string, before, after store.InviteRecord) ([]store.Delivery, error) {
+		// Reason: before is read in the same write, so concurrent changes
+		// each see the one saved ahead of them and publish their own event
+		evs = s.changeEvents(id, before, after)
+		if deliveries == nil || len(evs) == 0 {
+			return nil, nil
+		}
+		return deliveries(evs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if deliveries != nil && len(evs) > 0 {
+		s.outbox.Saved()
+	}
+	s.publish(ctx, s.pub, evs)
+	return after, nil
+}
+
+// RecordView records view through RecordViews, which reports whether it
+// was counted.
+func (s *eventStore) RecordView(ctx context.Context, id string, view store.View) error {
+	_, err := s.RecordViews(ctx, []store.ViewEvent{{ID: id, View: view}})
+	return err
+}
+
+func (s *eventStore) RecordViews(ctx context.Context, views []store.ViewEvent) ([]store.ViewEvent, error) {
+	counted, err := s.Store.RecordViews(ctx, views)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var evs []Event
+	for _, v := range counted {
+		if v.View.Bot {
+			continue
+		}
+		e := New(InviteViewed, v.ID, now)
+		at := v.View.At
+		e.Data.ViewedAt = &at
+		evs = append(evs, e)
+	}
+	if s.outbox != nil {
+		s.publish(ctx, s.outbox, evs)
+	}
+	s.publish(ctx, s.pub, evs)
+	return counted, nil
+}
+
+// changeEvents returns the event for the invite changing from before to
+// after, if the change is one.
+func (s *eventStore) changeEvents(id string, before, after store.InviteRecord) []Event {
+	typ := changeType(before, after)
+	if typ == "" {
+		return nil
+	}
+	e := New(typ, id, s.now())
+	e.Data.Invite = &after
+	return []Event{e}
+}
+
+func (s *eventStore) publish(ctx context.Context, pub Publisher, evs []Event) {
+	if len(evs) == 0 || pub == nil {
+		return
+	}
+	// Reason: the change is saved, so its events are published even when
+	// the request that made it has just been cancelled
+	if err := pub.Publish(context.WithoutCancel(ctx), evs...); err != nil {
+		log.WithError(err).WithField("events", len(evs)).Error("failed to publish invite events")
+	}
+}
+
+// changeType returns the event type for an invite changing from before to
+// after, or "" when nothing guests or admins care about changed. Views and
+// the acceptance time alone are not a change.
+func changeType(before, after store.InviteRecord) string {
+	switch {
+	case after.Accepted && !before.Accepted:
+		return InviteAccepted
+	case after.Declined && !before.Declined:
+		return InviteDeclined
+	case after.Accepted != before.Accepted,
+		after.Declined != before.Declined,
+		after.AdditionalCount != before.AdditionalCount,
+		after.Language != before.Language,
+		!slices.Equal(after.People, before.People),
+		!slices.Equal(after.Additional, before.Additional):
+		return InviteUpdated
+	}
+	return ""
+}
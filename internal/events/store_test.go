@@ -0,0 +1,280 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// recordingPublisher keeps the events it is given; err, when set, is
+// returned from every Publish.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, evs ...Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, evs...)
+	return p.err
+}
+
+// take returns the types of the events published since the last call.
+func (p *recordingPublisher) take() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	types := make([]string, len(p.events))
+	for i, e := range p.events {
+		types[i] = e.Type
+	}
+	p.events = nil
+	return types
+}
+
+func setupEventStore(t *testing.T) (store.Store, *recordingPublisher) {
+	t.Helper()
+	s := store.NewMemoryStore()
+	err := s.Seed(context.Background(), map[string]store.InviteRecord{
+		"aaa-001": {People: []string{"Иван Петров"}, AdditionalCount: 2},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	pub := &recordingPublisher{}
+	return WithEvents(s, nil, pub), pub
+}
+
+func expectTypes(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+}
+
+func TestWithEvents_UpdateInvite(t *testing.T) {
+	s, pub := setupEventStore(t)
+	ctx := context.Background()
+
+	if _, err := s.UpdateInvite(ctx, "aaa-001", true, []string{"Мария Петрова"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pub.mu.Lock()
+	first := pub.events[0]
+	pub.mu.Unlock()
+	if first.Data.InviteID != "aaa-001" || first.Data.Invite == nil || !first.Data.Invite.Accepted || first.ID == "" {
+		t.Fatalf("expected the accepted invite in the event, got %+v", first)
+	}
+	expectTypes(t, pub.take(), InviteAccepted)
+
+	if _, err := s.UpdateInvite(ctx, "aaa-001", true, []string{"Мария Петрова"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectTypes(t, pub.take())
+
+	if _, err := s.UpdateInvite(ctx, "aaa-001", true, []string{"Мария Петрова", "Петър Петров"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectTypes(t, pub.take(), InviteUpdated)
+
+	if _, err := s.UpdateInvite(ctx, "missing", true, nil); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.UpdateInvite(ctx, "aaa-001", true, []string{"a", "b", "c"}); err == nil {
+		t.Fatal("expected too many guests to fail")
+	}
+	expectTypes(t, pub.take())
+}
+
+func TestWithEvents_EditInvite(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*store.InviteRecord)
+		want []string
+	}{
+		{"accept", func(r *store.InviteRecord) { r.Accepted = true }, []string{InviteAccepted}},
+		{"decline", func(r *store.InviteRecord) { r.Declined = true }, []string{InviteDeclined}},
+		{"rename", func(r *store.InviteRecord) { r.People[0] = "Иван Иванов" }, []string{InviteUpdated}},
+		{"language", func(r *store.InviteRecord) { r.Language = "en" }, []string{InviteUpdated}},
+		{"no change", func(r *store.InviteRecord) {}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, pub := setupEventStore(t)
+			_, err := s.EditInvite(context.Background(), "aaa-001", func(r *store.InviteRecord) error {
+				tt.edit(r)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectTypes(t, pub.take(), tt.want...)
+		})
+	}
+}
+
+func TestWithEvents_EditInviteFails(t *testing.T) {
+	s, pub := setupEventStore(t)
+	_, err := s.EditInvite(context.Background(), "aaa-001", func(r *store.InviteRecord) error {
+		r.Accepted = true
+		return errors.New("rejected")
+	})
+	if err == nil {
+		t.Fatal("expected the edit error")
+	}
+	expectTypes(t, pub.take())
+}
+
+func TestWithEvents_RecordViews(t *testing.T) {
+	s, pub := setupEventStore(t)
+	ctx := context.Background()
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := s.RecordViews(ctx, []store.ViewEvent{
+		{ID: "aaa-001", View: store.View{At: at}},
+		{ID: "aaa-001", View: store.View{At: at.Add(time.Minute)}},
+		{ID: "aaa-001", View: store.View{At: at, Bot: true}},
+		{ID: "missing", View: store.View{At: at}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pub.mu.Lock()
+	evs := append([]Event(nil), pub.events...)
+	pub.mu.Unlock()
+	expectTypes(t, pub.take(), InviteViewed)
+	if evs[0].Data.ViewedAt == nil || !evs[0].Data.ViewedAt.Equal(at) || evs[0].Data.Invite != nil {
+		t.Fatalf("expected only the view time in the event, got %+v", evs[0].Data)
+	}
+
+	if err := s.RecordView(ctx, "aaa-001", store.View{At: at.Add(time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectTypes(t, pub.take(), InviteViewed)
+}
+
+func TestWithEvents_PublishFailure(t *testing.T) {
+	s, pub := setupEventStore(t)
+	pub.err = errors.New("outbox down")
+
+	rec, err := s.UpdateInvite(context.Background(), "aaa-001", true, nil)
+	if err != nil {
+		t.Fatalf("expected the saved change to succeed, got %v", err)
+	}
+	if !rec.Accepted {
+		t.Fatalf("expected the invite accepted, got %+v", rec)
+	}
+}
+
+// recordingOutbox turns events into deliveries to w1 and counts the saves.
+type recordingOutbox struct {
+	recordingPublisher
+	prepareErr error
+	saved      int
+}
+
+func (o *recordingOutbox) Prepare(context.Context) (func([]Event) ([]store.Delivery, error), error) {
+	if o.prepareErr != nil {
+		return nil, o.prepareErr
+	}
+	return func(evs []Event) ([]store.Delivery, error) {
+		var deliveries []store.Delivery
+		for _, e := range evs {
+			now := time.Now().UTC()
+			deliveries = append(deliveries, store.Delivery{
+				ID:            NewID(),
+				WebhookID:     "w1",
+				EventID:       e.ID,
+				EventType:     e.Type,
+				State:         store.DeliveryPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			})
+		}
+		return deliveries, nil
+	}, nil
+}
+
+func (o *recordingOutbox) Saved() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.saved++
+}
+
+func TestWithEvents_Outbox(t *testing.T) {
+	base := store.NewMemoryStore()
+	ctx := context.Background()
+	if err := base.Seed(ctx, map[string]store.InviteRecord{"aaa-001": {People: []string{"Иван Петров"}, AdditionalCount: 2}}); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	if err := base.PutWebhook(ctx, store.Webhook{ID: "w1", Events: Types}); err != nil {
+		t.Fatalf("failed to save webhook: %v", err)
+	}
+	outbox, pub := &recordingOutbox{}, &recordingPublisher{}
+	s := WithEvents(base, outbox, pub)
+
+	// Reason: each accept reads the invite inside its own write, so only
+	// the first of many concurrent ones is an acceptance
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.UpdateInvite(ctx, "aaa-001", true, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	expectTypes(t, pub.take(), InviteAccepted)
+	due, err := base.DueDeliveries(ctx, time.Now().Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(due) != 1 || due[0].EventType != InviteAccepted || outbox.saved != 1 {
+		t.Fatalf("expected one delivery saved with the acceptance, got %+v after %d saves", due, outbox.saved)
+	}
+	// Invite changes go to the outbox only through Prepare
+	expectTypes(t, outbox.take())
+
+	outbox.prepareErr = errors.New("store down")
+	_, err = s.EditInvite(ctx, "aaa-001", func(r *store.InviteRecord) error {
+		r.Declined = true
+		return nil
+	})
+	if !errors.Is(err, outbox.prepareErr) {
+		t.Fatalf("expected the outbox error, got %v", err)
+	}
+	if rec, _ := base.GetInvite(ctx, "aaa-001"); rec.Declined {
+		t.Fatal("expected the change not saved without its deliveries")
+	}
+
+	// Views are published to the outbox as they are counted
+	if err := s.RecordView(ctx, "aaa-001", store.View{At: time.Now().UTC()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectTypes(t, outbox.take(), InviteViewed)
+}
+
+func TestPublishers(t *testing.T) {
+	failing := &recordingPublisher{err: errors.New("down")}
+	ok := &recordingPublisher{}
+
+	err := Publishers{failing, ok}.Publish(context.Background(), New(InviteAccepted, "aaa-001", time.Now()))
+	if err == nil {
+		t.Fatal("expected the failure returned")
+	}
+	expectTypes(t, ok.take(), InviteAccepted)
+}
@@ -0,0 +1,59 @@
+package guest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+)
+
+var scriptNames = map[string]i18n.Key{
+	"Cyrillic": i18n.MsgScriptCyrillic,
+	"Latin":    i18n.MsgScriptLatin,
+}
+
+// namesHint tells guests which alphabets names may use. Scripts without a
+// translation are shown by their Unicode name.
+func namesHint(lang language.Tag, scripts []string) string {
+	if len(scripts) == 0 {
+		return ""
+	}
+	shown := make([]string, len(scripts))
+	for i, s := range scripts {
+		shown[i] = s
+		if key, ok := scriptNames[s]; ok {
+			shown[i] = i18n.T(lang, key)
+		}
+	}
+	return i18n.T(lang, i18n.MsgPageNamesHint, strings.Join(shown, " / "))
+}
+
+// joinPeople lists names as "A, B and C" in lang.
+func joinPeople(lang language.Tag, people []string) string {
+	if len(people) < 2 {
+		return strings.Join(people, "")
+	}
+	last := len(people) - 1
+	return strings.Join(people[:last], ", ") + " " + i18n.T(lang, i18n.MsgPageAnd) + " " + people[last]
+}
+
+var (
+	bgWeekdays = [...]string{"неделя", "понеделник", "вторник", "сряда", "четвъртък", "петък", "събота"}
+	bgMonths   = [...]string{"януари", "февруари", "март", "април", "май", "юни", "юли", "август", "септември", "октомври", "ноември", "декември"}
+)
+
+// formatDate renders t for the page, in the location it was configured
+// with; the zero time gives "".
+func formatDate(lang language.Tag, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if base, _ := lang.Base(); base.String() == "bg" {
+		return fmt.Sprintf("%s, %d %s %d г., %s",
+			bgWeekdays[t.Weekday()], t.Day(), bgMonths[t.Month()-1], t.Year(), t.Format("15:04"))
+	}
+	return t.Format("Monday, 2 January 2006, 15:04")
+}
@@ -0,0 +1,333 @@
+// Package guest serves the RSVP page guests open from their invitation link.
+// It is plain server-rendered HTML: the form posts back to the same URL and
+// is answered with a redirect, so it works without JavaScript.
+package guest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/assets"
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/logging"
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+	"github.com/dimitarkovachev/wedding/internal/useragent"
+)
+
+// PageFile is the template rendered for /i/{id}.
+const PageFile = "invite.html"
+
+// Store is the invite storage the RSVP page needs.
+type Store interface {
+	GetInvite(ctx context.Context, id string) (*store.InviteRecord, error)
+	RecordView(ctx context.Context, id string, view store.View) error
+	UpdateInvite(ctx context.Context, id string, accepted bool, additional []string) (*store.InviteRecord, error)
+}
+
+// Info describes the wedding; empty fields are left off the page.
+type Info struct {
+	Couple  string
+	Date    time.Time
+	Venue   string
+	Address string
+	MapURL  string
+}
+
+// Handler serves the RSVP page for one invite at /i/{id}.
+type Handler struct {
+	store     Store
+	rules     names.Rules
+	site      *assets.Server
+	info      Info
+	publicURL string
+	tmpl      *template.Template
+	image     previewImage
+}
+
+var funcs = template.FuncMap{
+	"t": func(lang, key string, args ...any) string {
+		tag, _ := i18n.Parse(lang)
+		return i18n.T(tag, i18n.Key(key), args...)
+	},
+}
+
+// NewHandler parses the page template from site, failing if it is missing
+// or broken. publicURL is the server's external base URL used in link
+// previews, e.g. "https://wedding.example.com"; when empty the previews
+// carry no page or image URL.
+func NewHandler(s Store, rules names.Rules, site *assets.Server, info Info, publicURL string) (*Handler, error) {
+	tmpl, err := site.Template(PageFile, funcs)
+	if err != nil {
+		return nil, err
+	}
+	image, err := newPreviewImage()
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		store:     s,
+		rules:     rules,
+		site:      site,
+		info:      info,
+		publicURL: publicURL,
+		tmpl:      tmpl,
+		image:     image,
+	}, nil
+}
+
+// slot is one plus-one input on the form.
+type slot struct {
+	Number int
+	Value  string
+	Error  string
+}
+
+type pageData struct {
+	Lang      string
+	Found     bool
+	ID        string
+	People    string
+	Accepted  bool
+	Saved     bool
+	Error     string
+	Slots     []slot
+	Pattern   string
+	MaxLength int
+	NamesHint string
+	Info      Info
+	When      string
+	Preview   preview
+}
+
+// Show renders the invite and records the view. Link-preview bots get the
+// same page, whose Open Graph tags they read; their views are recorded as
+// bot views, which do not mark the invite opened.
+func (h *Handler) Show(c *gin.Context) {
+	id := c.Param("id")
+	rec, ok := h.load(c, id)
+	if !ok {
+		return
+	}
+
+	data := h.page(c, id, rec)
+	data.Saved = c.Query("saved") == "1"
+	h.render(c, http.StatusOK, data)
+
+	view := store.View{At: time.Now().UTC(), Bot: useragent.IsBot(c.Request.UserAgent())}
+	if err := h.store.RecordView(c.Request.Context(), id, view); err != nil && !store.IsDroppedView(err) {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("failed to record view")
+	}
+}
+
+// Submit accepts the invite with the posted plus-one names. Success is
+// answered with a redirect back to the page (post/redirect/get); rejected
+// names re-render the form with the guest's input and an error per field.
+func (h *Handler) Submit(c *gin.Context) {
+	id := c.Param("id")
+	ctx := c.Request.Context()
+	logger := logging.FromContext(ctx).WithField("invite_id", id)
+
+	rec, ok := h.load(c, id)
+	if !ok {
+		return
+	}
+
+	data := h.page(c, id, rec)
+	tag, _ := i18n.Parse(data.Lang)
+	// Reason: storeError below renders in the request's language, which has
+	// to be the invite's own like the rest of the page
+	c.Request = c.Request.WithContext(i18n.WithLanguage(ctx, tag))
+	submitted := c.PostFormArray("additional")
+	for i := range data.Slots {
+		data.Slots[i].Value = ""
+		if i < len(submitted) {
+			data.Slots[i].Value = submitted[i]
+		}
+	}
+
+	// Reason: empty inputs mean "no guest"; positions maps each submitted
+	// name back to its input so errors land on the right field
+	var additional []string
+	var positions []int
+	for i, v := range submitted {
+		if v = names.Normalize(v); v != "" {
+			additional = append(additional, v)
+			positions = append(positions, i)
+		}
+	}
+	fieldError := func(pos int, msg string) {
+		if pos < len(data.Slots) {
+			data.Slots[pos].Error = msg
+		} else {
+			data.Error = msg
+		}
+	}
+
+	for _, v := range h.rules.CheckAll(additional) {
+		fieldError(positions[v.Index], i18n.T(tag, nameMessage(v.Constraint)))
+	}
+	if data.hasErrors() {
+		data.Error = i18n.T(tag, i18n.MsgPageFixErrors)
+		h.render(c, http.StatusBadRequest, data)
+		return
+	}
+
+	_, err := h.store.UpdateInvite(ctx, id, true, additional)
+	var tooMany *store.TooManyGuestsError
+	var dup *store.DuplicateGuestError
+	var blank *store.BlankGuestError
+	switch {
+	case errors.As(err, &tooMany):
+		logger.WithError(err).Warn("invite update rejected")
+		data.Error = i18n.T(tag, i18n.MsgTooManyGuests, tooMany.Got, tooMany.Max)
+	case errors.As(err, &dup):
+		logger.WithError(err).Warn("invite update rejected")
+		key := i18n.MsgDuplicateGuest
+		if dup.InPeople {
+			key = i18n.MsgAlreadyInvited
+		}
+		fieldError(positions[dup.Index], i18n.T(tag, key))
+		data.Error = i18n.T(tag, i18n.MsgPageFixErrors)
+	case errors.As(err, &blank):
+		logger.WithError(err).Warn("invite update rejected")
+		fieldError(positions[blank.Index], i18n.T(tag, i18n.MsgFieldTooShort))
+		data.Error = i18n.T(tag, i18n.MsgPageFixErrors)
+	case errors.Is(err, store.ErrDeadlinePassed):
+		logger.WithError(err).Warn("invite update rejected")
+		data.Error = i18n.T(tag, i18n.MsgDeadlinePassed)
+		h.render(c, http.StatusConflict, data)
+		return
+	case err != nil:
+		h.storeError(c, err, "failed to update invite")
+		return
+	}
+	if data.hasErrors() {
+		h.render(c, http.StatusBadRequest, data)
+		return
+	}
+
+	logger.Info("invite accepted")
+	c.Redirect(http.StatusSeeOther, "/i/"+id+"?saved=1")
+}
+
+// load reads the invite, rendering the not found or error page itself when
+// it cannot be shown.
+func (h *Handler) load(c *gin.Context, id string) (*store.InviteRecord, bool) {
+	rec, err := h.store.GetInvite(c.Request.Context(), id)
+	if err != nil {
+		h.storeError(c, err, "failed to get invite")
+		return nil, false
+	}
+	return rec, true
+}
+
+// storeError renders the error page for a store error. Only a missing invite
+// and an expired request context get their own message; the text of any
+// other error is logged with msg, never shown.
+func (h *Handler) storeError(c *gin.Context, err error, msg string) {
+	logger := logging.FromContext(c.Request.Context()).WithField("invite_id", c.Param("id")).WithError(err)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		h.renderError(c, http.StatusNotFound, i18n.MsgInviteNotFound)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		logger.Warn(msg)
+		h.renderError(c, http.StatusServiceUnavailable, i18n.MsgTimeout)
+	default:
+		logger.Error(msg)
+		h.renderError(c, http.StatusInternalServerError, i18n.MsgInternalError)
+	}
+}
+
+func (d pageData) hasErrors() bool {
+	if d.Error != "" {
+		return true
+	}
+	for _, s := range d.Slots {
+		if s.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// page fills the data shared by every render of an existing invite.
+func (h *Handler) page(c *gin.Context, id string, rec *store.InviteRecord) pageData {
+	lang := h.language(c, rec)
+	data := pageData{
+		Lang:      lang.String(),
+		Found:     true,
+		ID:        id,
+		People:    joinPeople(lang, rec.People),
+		Accepted:  rec.Accepted,
+		Pattern:   h.rules.HTMLPattern(),
+		MaxLength: h.rules.MaxLength,
+		NamesHint: namesHint(lang, h.rules.Scripts),
+		Info:      h.info,
+		When:      formatDate(lang, h.info.Date),
+		Preview:   h.preview(lang, id, rec),
+	}
+	for i := 0; i < rec.AdditionalCount; i++ {
+		s := slot{Number: i + 1}
+		if i < len(rec.Additional) {
+			s.Value = rec.Additional[i]
+		}
+		data.Slots = append(data.Slots, s)
+	}
+	return data
+}
+
+// language prefers the invite's own language over Accept-Language.
+func (h *Handler) language(c *gin.Context, rec *store.InviteRecord) language.Tag {
+	if lang, ok := i18n.Parse(rec.Language); ok {
+		return lang
+	}
+	return i18n.FromContext(c.Request.Context())
+}
+
+// renderError renders the page without an invite, showing only the message.
+func (h *Handler) renderError(c *gin.Context, status int, key i18n.Key) {
+	lang := i18n.FromContext(c.Request.Context())
+	data := pageData{Lang: lang.String(), Error: i18n.T(lang, key), Info: h.info}
+	h.render(c, status, data)
+}
+
+func (h *Handler) render(c *gin.Context, status int, data pageData) {
+	tmpl := h.tmpl
+	if h.site.Dev() {
+		var err error
+		if tmpl, err = h.site.Template(PageFile, funcs); err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Error("failed to render invite page")
+		c.String(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	// Reason: the page is personal to one invite and changes once answered
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Language", data.Lang)
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func nameMessage(constraint string) i18n.Key {
+	switch constraint {
+	case names.ConstraintMinLength:
+		return i18n.MsgFieldTooShort
+	case names.ConstraintMaxLength:
+		return i18n.MsgFieldTooLong
+	default:
+		return i18n.MsgFieldPattern
+	}
+}
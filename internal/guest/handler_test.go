@@ -0,0 +1,299 @@
+package guest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/assets"
+	"github.com/dimitarkovachev/wedding/internal/middleware"
+	"github.com/dimitarkovachev/wedding/internal/names"
+	"github.com/dimitarkovachev/wedding/internal/store"
+	"github.com/dimitarkovachev/wedding/web"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+const testID = "550e8400-e29b-41d4-a716-446655440000"
+
+func setupGuestRouter(t *testing.T, opts ...store.Option) (*gin.Engine, *store.MemoryStore) {
+	t.Helper()
+
+	s := store.NewMemoryStore(opts...)
+	err := s.Seed(t.Context(), map[string]store.InviteRecord{
+		testID: {People: []string{"Иван Петров", "Мария Петрова"}, AdditionalCount: 2},
+		"en":   {People: []string{"John Smith"}, Language: "en"},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	return newGuestRouter(t, s, ""), s
+}
+
+// newGuestRouter serves the guest pages for the invites in s, with link
+// previews pointing at publicURL.
+func newGuestRouter(t *testing.T, s Store, publicURL string) *gin.Engine {
+	t.Helper()
+
+	fsys, err := assets.Sub(web.FS, "guest")
+	if err != nil {
+		t.Fatalf("failed to find guest assets: %v", err)
+	}
+	site, err := assets.New(fsys, "/static/", []string{PageFile, "style.css"}, false)
+	if err != nil {
+		t.Fatalf("failed to load guest assets: %v", err)
+	}
+	info := Info{
+		Couple: "Ана и Борис",
+		Date:   time.Date(2027, time.June, 12, 16, 0, 0, 0, time.UTC),
+		Venue:  "Хотел Рила",
+	}
+	h, err := NewHandler(s, names.DefaultRules(), site, info, publicURL)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.NewLanguageDetector())
+	r.GET("/i/:id", h.Show)
+	r.POST("/i/:id", h.Submit)
+	r.GET(PreviewImagePath, h.PreviewImage)
+	return r
+}
+
+func postForm(r *gin.Engine, id string, additional ...string) *httptest.ResponseRecorder {
+	form := url.Values{"additional": additional}
+	req := httptest.NewRequest(http.MethodPost, "/i/"+id, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Show(t *testing.T) {
+	r, s := setupGuestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/i/"+testID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Fatalf("expected Cache-Control no-store, got %q", got)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"Иван Петров и Мария Петрова",
+		"събота, 12 юни 2027 г., 16:00",
+		"Хотел Рила",
+		`id="guest-2"`,
+		`pattern="[\p{Script=Cyrillic} \-]&#43;"`,
+		`href="/static/style.css?v=`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected page to contain %q, got %s", want, body)
+		}
+	}
+	if strings.Contains(body, `id="guest-3"`) {
+		t.Fatal("expected only 2 guest inputs")
+	}
+
+	rec, err := s.GetInvite(t.Context(), testID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rec.Views.Opened() || rec.Views.Browser.Count != 1 {
+		t.Fatalf("expected 1 recorded browser view, got %+v", rec.Views)
+	}
+}
+
+func TestHandler_Show_InviteLanguage(t *testing.T) {
+	r, _ := setupGuestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/i/en", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Language"); got != "en" {
+		t.Fatalf("expected Content-Language en, got %q", got)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Dear John Smith,") || !strings.Contains(body, "Saturday, 12 June 2027, 16:00") {
+		t.Fatalf("expected English page, got %s", body)
+	}
+	if strings.Contains(body, "<fieldset>") {
+		t.Fatal("expected no guest inputs for an invite without plus-ones")
+	}
+}
+
+func TestHandler_Show_NotFound(t *testing.T) {
+	r, _ := setupGuestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/i/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "<form") {
+		t.Fatal("expected no form for a missing invite")
+	}
+}
+
+func TestHandler_Submit(t *testing.T) {
+	r, s := setupGuestRouter(t)
+
+	// An input of only spaces is an empty slot, not a guest named ""
+	w := postForm(r, testID, "   ", "Георги Иванов")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Location"); got != "/i/"+testID+"?saved=1" {
+		t.Fatalf("expected redirect back to the page, got %q", got)
+	}
+
+	rec, err := s.GetInvite(t.Context(), testID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rec.Accepted || len(rec.Additional) != 1 || rec.Additional[0] != "Георги Иванов" {
+		t.Fatalf("expected accepted invite with one guest, got %+v", rec)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/i/"+testID+"?saved=1", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "Благодарим!") || !strings.Contains(body, `value="Георги Иванов"`) {
+		t.Fatalf("expected confirmation with the saved guest, got %s", body)
+	}
+}
+
+func TestHandler_Submit_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		additional []string
+		wantStatus int
+		want       string
+	}{
+		{"pattern", testID, []string{"John Smith"}, http.StatusBadRequest, `id="guest-1-error"`},
+		{"error on second input", testID, []string{"", "John"}, http.StatusBadRequest, `id="guest-2-error"`},
+		{"already invited", testID, []string{"Иван Петров"}, http.StatusBadRequest, "вече е в поканата"},
+		{"too many", testID, []string{"Аа", "Бб", "Вв"}, http.StatusBadRequest, `role="alert"`},
+		{"missing invite", "missing", nil, http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, s := setupGuestRouter(t)
+
+			w := postForm(r, tt.id, tt.additional...)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Fatalf("expected page to contain %q, got %s", tt.want, w.Body.String())
+			}
+			// Reason: the form only has AdditionalCount inputs to refill
+			for i, v := range tt.additional {
+				if i < 2 && v != "" && !strings.Contains(w.Body.String(), v) {
+					t.Fatalf("expected submitted value %q to be kept", v)
+				}
+			}
+
+			rec, err := s.GetInvite(t.Context(), testID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Accepted {
+				t.Fatal("expected invite to stay unanswered")
+			}
+		})
+	}
+}
+
+func TestHandler_Submit_DeadlinePassed(t *testing.T) {
+	r, s := setupGuestRouter(t, store.WithRSVPDeadline(time.Now().Add(-time.Hour)))
+
+	w := postForm(r, testID, "Георги")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "срокът за потвърждение е изтекъл") {
+		t.Fatalf("expected the deadline message, got %s", w.Body.String())
+	}
+	rec, err := s.GetInvite(t.Context(), testID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Accepted {
+		t.Fatal("expected invite to stay unanswered")
+	}
+}
+
+// failingUpdates is a store whose invites can be read but not answered.
+type failingUpdates struct{ *store.MemoryStore }
+
+func (failingUpdates) UpdateInvite(context.Context, string, bool, []string) (*store.InviteRecord, error) {
+	return nil, errors.New("disk full")
+}
+
+func TestHandler_Submit_StoreErrorInInviteLanguage(t *testing.T) {
+	_, s := setupGuestRouter(t)
+	r := newGuestRouter(t, failingUpdates{s}, "")
+
+	w := postForm(r, "en")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); !strings.Contains(body, "internal error") || strings.Contains(body, "disk full") {
+		t.Fatalf("expected the English error page without the cause, got %s", body)
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2027, time.June, 12, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		lang language.Tag
+		date time.Time
+		want string
+	}{
+		{language.Bulgarian, date, "събота, 12 юни 2027 г., 16:00"},
+		{language.English, date, "Saturday, 12 June 2027, 16:00"},
+		{language.English, time.Time{}, ""},
+	}
+	for _, tt := range tests {
+		if got := formatDate(tt.lang, tt.date); got != tt.want {
+			t.Fatalf("%s: expected %q, got %q", tt.lang, tt.want, got)
+		}
+	}
+}
+
+func TestJoinPeople(t *testing.T) {
+	tests := []struct {
+		people []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"Ана"}, "Ана"},
+		{[]string{"Ана", "Борис"}, "Ана и Борис"},
+		{[]string{"Ана", "Борис", "Вера"}, "Ана, Борис и Вера"},
+	}
+	for _, tt := range tests {
+		if got := joinPeople(language.Bulgarian, tt.people); got != tt.want {
+			t.Fatalf("%v: expected %q, got %q", tt.people, tt.want, got)
+		}
+	}
+}
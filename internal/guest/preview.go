@@ -0,0 +1,129 @@
+package guest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// PreviewImagePath is where the link preview image is served.
+const PreviewImagePath = "/preview.png"
+
+// Preview image size recommended for Open Graph and Twitter large cards.
+const (
+	previewWidth  = 1200
+	previewHeight = 630
+)
+
+// preview holds the Open Graph and Twitter card fields for one invite.
+type preview struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	ImageWidth  int
+	ImageHeight int
+	Locale      string
+}
+
+var ogLocales = map[string]string{"bg": "bg_BG", "en": "en_US"}
+
+// preview builds the link preview for rec. Crawlers need absolute URLs, and
+// the request's Host and X-Forwarded-Proto are whatever the client sent, so
+// the page and image URLs are only set when a public URL is configured.
+func (h *Handler) preview(lang language.Tag, id string, rec *store.InviteRecord) preview {
+	description := i18n.T(lang, i18n.MsgPageInvitation)
+	if h.info.Couple != "" {
+		description = i18n.T(lang, i18n.MsgPreviewDescription, h.info.Couple)
+	}
+	if when := formatDate(lang, h.info.Date); when != "" {
+		description += " " + when
+	}
+	if h.info.Venue != "" {
+		description += ", " + h.info.Venue
+	}
+
+	p := preview{
+		Title:       i18n.T(lang, i18n.MsgPreviewTitle, joinPeople(lang, rec.People)),
+		Description: description,
+		ImageWidth:  previewWidth,
+		ImageHeight: previewHeight,
+		Locale:      ogLocales[lang.String()],
+	}
+	if base := strings.TrimSuffix(h.publicURL, "/"); base != "" {
+		p.URL = base + "/i/" + id
+		p.Image = base + PreviewImagePath + "?v=" + h.image.hash
+	}
+	return p
+}
+
+// previewImage is the encoded preview PNG and its content hash.
+type previewImage struct {
+	body []byte
+	hash string
+}
+
+// PreviewImage serves the link preview image. It is generated at startup and
+// never changes while the server runs.
+func (h *Handler) PreviewImage(c *gin.Context) {
+	etag := `"` + h.image.hash + `"`
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "image/png", h.image.body)
+}
+
+// newPreviewImage draws two interlocking gold rings on a cream background.
+// Reason: the standard library cannot render Cyrillic text without a font
+// package, so the image stays text-free and the names go in og:title instead.
+func newPreviewImage() (previewImage, error) {
+	img := image.NewRGBA(image.Rect(0, 0, previewWidth, previewHeight))
+	background := color.RGBA{0xfa, 0xf7, 0xf2, 0xff}
+	gold := color.RGBA{0xc9, 0xa2, 0x4d, 0xff}
+
+	const radius, thickness = 120.0, 14.0
+	cy := previewHeight / 2.0
+	centers := []float64{previewWidth/2.0 - radius*0.6, previewWidth/2.0 + radius*0.6}
+
+	for y := 0; y < previewHeight; y++ {
+		for x := 0; x < previewWidth; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			// Reason: coverage from the distance to the ring's centre line gives
+			// smooth, anti-aliased edges without a rasterizer
+			coverage := 0.0
+			for _, cx := range centers {
+				d := math.Abs(math.Hypot(px-cx, py-cy)-radius) - thickness/2
+				coverage = math.Max(coverage, math.Min(math.Max(0.5-d, 0), 1))
+			}
+			img.SetRGBA(x, y, blend(background, gold, coverage))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return previewImage{}, fmt.Errorf("encoding preview image: %w", err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return previewImage{body: buf.Bytes(), hash: hex.EncodeToString(sum[:8])}, nil
+}
+
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xff}
+}
@@ -0,0 +1,100 @@
+package guest
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getPreview fetches the invite page as a link-preview crawler would,
+// through a proxy claiming the host evil.example.
+func getPreview(t *testing.T, r *gin.Engine) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/i/"+testID, nil)
+	req.Host = "evil.example"
+	req.Header.Set("User-Agent", "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)")
+	req.Header.Set("X-Forwarded-Proto", "javascript")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+func TestHandler_Show_LinkPreview(t *testing.T) {
+	_, s := setupGuestRouter(t)
+	r := newGuestRouter(t, s, "https://wedding.example.com/")
+
+	body := getPreview(t, r)
+	for _, want := range []string{
+		`<meta property="og:title" content="Покана за Иван Петров и Мария Петрова">`,
+		`<meta property="og:description" content="Ана и Борис ви канят на своята сватба. събота, 12 юни 2027 г., 16:00, Хотел Рила">`,
+		`<meta property="og:url" content="https://wedding.example.com/i/` + testID + `">`,
+		`<meta property="og:image" content="https://wedding.example.com/preview.png?v=`,
+		`<meta property="og:locale" content="bg_BG">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected page to contain %q, got %s", want, body)
+		}
+	}
+
+	rec, err := s.GetInvite(t.Context(), testID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Views.Opened() || rec.Views.Bot.Count != 1 {
+		t.Fatalf("expected the fetch to count as a bot view only, got %+v", rec.Views)
+	}
+}
+
+func TestHandler_Show_LinkPreviewWithoutPublicURL(t *testing.T) {
+	r, _ := setupGuestRouter(t)
+
+	body := getPreview(t, r)
+	if !strings.Contains(body, `<meta property="og:title" content="Покана за Иван Петров и Мария Петрова">`) {
+		t.Fatalf("expected the preview title, got %s", body)
+	}
+	for _, unwanted := range []string{"og:url", "og:image", "twitter:image", "evil.example", "javascript"} {
+		if strings.Contains(body, unwanted) {
+			t.Fatalf("expected no %q without PUBLIC_URL, got %s", unwanted, body)
+		}
+	}
+	if !strings.Contains(body, `<meta name="twitter:card" content="summary">`) {
+		t.Fatalf("expected a small card without an image, got %s", body)
+	}
+}
+
+func TestHandler_PreviewImage(t *testing.T) {
+	r, _ := setupGuestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PreviewImagePath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/png" {
+		t.Fatalf("expected image/png, got %q", got)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to decode png: %v", err)
+	}
+	if cfg.Width != previewWidth || cfg.Height != previewHeight {
+		t.Fatalf("expected %dx%d, got %dx%d", previewWidth, previewHeight, cfg.Width, cfg.Height)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, PreviewImagePath, nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w.Code)
+	}
+}
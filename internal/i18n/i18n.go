@@ -0,0 +1,59 @@
+// Package i18n selects the language guests are answered in and translates
+// user-facing messages into Bulgarian or English.
+package i18n
+
+import (
+	"context"
+
+	"golang.org/x/text/language"
+)
+
+// Supported lists the languages messages are translated into. The first
+// entry is the fallback used when nothing else matches.
+var Supported = []language.Tag{language.Bulgarian, language.English}
+
+// Default is the language used when a request expresses no usable preference.
+var Default = Supported[0]
+
+var matcher = language.NewMatcher(Supported)
+
+// Match picks the supported language best matching an Accept-Language header
+// value, falling back to Default.
+func Match(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, idx, conf := matcher.Match(tags...)
+	if conf == language.No {
+		return Default
+	}
+	// Reason: return the canonical tag rather than the matcher's result, which
+	// carries region extensions such as "en-u-rg-gbzzzz"
+	return Supported[idx]
+}
+
+// Parse returns the supported language named by code ("bg" or "en").
+func Parse(code string) (language.Tag, bool) {
+	for _, tag := range Supported {
+		if tag.String() == code {
+			return tag, true
+		}
+	}
+	return language.Und, false
+}
+
+type ctxKey struct{}
+
+// WithLanguage returns a copy of ctx carrying lang.
+func WithLanguage(ctx context.Context, lang language.Tag) context.Context {
+	return context.WithValue(ctx, ctxKey{}, lang)
+}
+
+// FromContext returns the language stored by WithLanguage, or Default.
+func FromContext(ctx context.Context) language.Tag {
+	if lang, ok := ctx.Value(ctxKey{}).(language.Tag); ok {
+		return lang
+	}
+	return Default
+}
@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   language.Tag
+	}{
+		{"", language.Bulgarian},
+		{"bg", language.Bulgarian},
+		{"bg-BG,bg;q=0.9", language.Bulgarian},
+		{"en", language.English},
+		{"en-GB,en;q=0.9", language.English},
+		{"de-DE,en;q=0.5", language.English},
+		{"en;q=0.4,bg;q=0.8", language.Bulgarian},
+		{"fr", language.Bulgarian},
+		{"not a header;;", language.Bulgarian},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.header); got != tt.want {
+			t.Fatalf("Match(%q): expected %s, got %s", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if lang, ok := Parse("en"); !ok || lang != language.English {
+		t.Fatalf("expected en, got %s (%v)", lang, ok)
+	}
+	if lang, ok := Parse("bg"); !ok || lang != language.Bulgarian {
+		t.Fatalf("expected bg, got %s (%v)", lang, ok)
+	}
+	if _, ok := Parse("fr"); ok {
+		t.Fatal("expected fr to be unsupported")
+	}
+}
+
+func TestFromContext_Default(t *testing.T) {
+	if got := FromContext(context.Background()); got != Default {
+		t.Fatalf("expected %s, got %s", Default, got)
+	}
+	ctx := WithLanguage(context.Background(), language.English)
+	if got := FromContext(ctx); got != language.English {
+		t.Fatalf("expected en, got %s", got)
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(language.English, MsgTooManyGuests, 3, 1); got != "too many additional guests: got 3, max allowed 1" {
+		t.Fatalf("unexpected English message %q", got)
+	}
+	if got := T(language.Bulgarian, MsgInviteNotFound); got != "поканата не е намерена" {
+		t.Fatalf("unexpected Bulgarian message %q", got)
+	}
+	if got := T(language.German, MsgInviteNotFound); got != "поканата не е намерена" {
+		t.Fatalf("expected fallback to Bulgarian, got %q", got)
+	}
+	if got := T(language.English, Key("missing")); got != "missing" {
+		t.Fatalf("expected unknown key to be returned as is, got %q", got)
+	}
+}
+
+func TestCatalogue_Complete(t *testing.T) {
+	for key, translations := range catalogue {
+		var verbs []int
+		for _, lang := range Supported {
+			msg, ok := translations[lang]
+			if !ok || msg == "" {
+				t.Fatalf("%s: missing %s translation", key, lang)
+			}
+			verbs = append(verbs, strings.Count(msg, "%"))
+		}
+		for _, n := range verbs[1:] {
+			if n != verbs[0] {
+				t.Fatalf("%s: translations take different numbers of arguments", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package i18n
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// Key identifies a translatable message.
+type Key string
+
+// Message keys. Messages taking arguments note them in order.
+const (
+	MsgInternalError    Key = "internal_error"
+	MsgInviteNotFound   Key = "invite_not_found"
+	MsgInvalidBody      Key = "invalid_body"
+	MsgInvalidJSON      Key = "invalid_json"
+	MsgValidationFailed Key = "validation_failed"
+	MsgAcceptedOnly     Key = "accepted_only"
+	MsgTooManyGuests    Key = "too_many_guests" // got, max
+	MsgDuplicateGuest   Key = "duplicate_guest"
+	MsgAlreadyInvited   Key = "already_invited"
+	MsgDeadlinePassed   Key = "deadline_passed"
+	MsgRateLimited      Key = "rate_limited"
+	MsgTimeout          Key = "timeout"
+	MsgIdempotencyReuse Key = "idempotency_key_reused"
+	MsgRouteNotFound    Key = "route_not_found"
+	MsgResponseInvalid  Key = "response_invalid"
+
+	MsgFieldPattern  Key = "field_pattern"
+	MsgFieldTooShort Key = "field_too_short"
+	MsgFieldTooLong  Key = "field_too_long"
+	MsgFieldMaxItems Key = "field_max_items" // max
+	MsgFieldTooMany  Key = "field_too_many"
+	MsgFieldRequired Key = "field_required"
+	MsgFieldType     Key = "field_type"
+	MsgFieldFormat   Key = "field_format"
+	MsgFieldEnum     Key = "field_enum"
+	MsgFieldInvalid  Key = "field_invalid"
+
+	// Guest RSVP page
+	MsgPageTitle      Key = "page_title"
+	MsgPageGreeting   Key = "page_greeting" // people
+	MsgPageAnd        Key = "page_and"
+	MsgPageInvitation Key = "page_invitation"
+	MsgPageWhen       Key = "page_when"
+	MsgPageWhere      Key = "page_where"
+	MsgPageMap        Key = "page_map"
+	MsgPagePlusOnes   Key = "page_plus_ones"  // max
+	MsgPageNamesHint  Key = "page_names_hint" // scripts
+	MsgPageGuest      Key = "page_guest"      // number
+	MsgPageSubmit     Key = "page_submit"
+	MsgPageUpdate     Key = "page_update"
+	MsgPageSaved      Key = "page_saved"
+	MsgPageAccepted   Key = "page_accepted"
+	MsgPageFixErrors  Key = "page_fix_errors"
+	MsgScriptCyrillic Key = "script_cyrillic"
+	MsgScriptLatin    Key = "script_latin"
+
+	// Link previews (Open Graph)
+	MsgPreviewTitle       Key = "preview_title"       // people
+	MsgPreviewDescription Key = "preview_description" // couple
+)
+
+var catalogue = map[Key]map[language.Tag]string{
+	MsgInternalError: {
+		language.Bulgarian: "вътрешна грешка",
+		language.English:   "internal error",
+	},
+	MsgInviteNotFound: {
+		language.Bulgarian: "поканата не е намерена",
+		language.English:   "invite not found",
+	},
+	MsgInvalidBody: {
+		language.Bulgarian: "невалидно съдържание на заявката",
+		language.English:   "invalid request body",
+	},
+	MsgInvalidJSON: {
+		language.Bulgarian: "съдържанието на заявката не е валиден JSON",
+		language.English:   "request body is not valid JSON",
+	},
+	MsgValidationFailed: {
+		language.Bulgarian: "заявката съдържа невалидни данни",
+		language.English:   "request does not match the API specification",
+	},
+	MsgAcceptedOnly: {
+		language.Bulgarian: "поканата може само да бъде потвърдена",
+		language.English:   "only accepted=true updates are allowed",
+	},
+	MsgTooManyGuests: {
+		language.Bulgarian: "твърде много допълнителни гости: подадени %d, позволени най-много %d",
+		language.English:   "too many additional guests: got %d, max allowed %d",
+	},
+	MsgDuplicateGuest: {
+		language.Bulgarian: "гостът е посочен повече от веднъж",
+		language.English:   "guest is listed more than once",
+	},
+	MsgAlreadyInvited: {
+		language.Bulgarian: "гостът вече е в поканата",
+		language.English:   "guest is already on the invite",
+	},
+	MsgDeadlinePassed: {
+		language.Bulgarian: "срокът за потвърждение е изтекъл",
+		language.English:   "the RSVP deadline has passed",
+	},
+	MsgRateLimited: {
+		language.Bulgarian: "твърде много заявки, моля опитайте отново по-късно",
+		language.English:   "too many requests, please try again later",
+	},
+	MsgTimeout: {
+		language.Bulgarian: "заявката отне твърде дълго, моля опитайте отново",
+		language.English:   "the request took too long, please try again",
+	},
+	MsgIdempotencyReuse: {
+		language.Bulgarian: "ключът Idempotency-Key вече е използван за друга заявка",
+		language.English:   "the Idempotency-Key was already used for a different request",
+	},
+	MsgRouteNotFound: {
+		language.Bulgarian: "адресът не съществува",
+		language.English:   "route not found in API specification",
+	},
+	MsgResponseInvalid: {
+		language.Bulgarian: "вътрешна грешка при формиране на отговора",
+		language.English:   "response does not match API specification",
+	},
+	MsgFieldPattern: {
+		language.Bulgarian: "съдържа непозволени символи",
+		language.English:   "contains characters that are not allowed",
+	},
+	MsgFieldTooShort: {
+		language.Bulgarian: "е твърде кратко",
+		language.English:   "is too short",
+	},
+	MsgFieldTooLong: {
+		language.Bulgarian: "е твърде дълго",
+		language.English:   "is too long",
+	},
+	MsgFieldMaxItems: {
+		language.Bulgarian: "трябва да съдържа най-много %d елемента",
+		language.English:   "must contain at most %d items",
+	},
+	MsgFieldTooMany: {
+		language.Bulgarian: "съдържа твърде много елементи",
+		language.English:   "has too many items",
+	},
+	MsgFieldRequired: {
+		language.Bulgarian: "е задължително",
+		language.English:   "is required",
+	},
+	MsgFieldType: {
+		language.Bulgarian: "е от грешен тип",
+		language.English:   "has the wrong type",
+	},
+	MsgFieldFormat: {
+		language.Bulgarian: "е в невалиден формат",
+		language.English:   "has an invalid format",
+	},
+	MsgFieldEnum: {
+		language.Bulgarian: "не е сред позволените стойности",
+		language.English:   "is not one of the allowed values",
+	},
+	MsgFieldInvalid: {
+		language.Bulgarian: "е невалидно",
+		language.English:   "is invalid",
+	},
+	MsgPageTitle: {
+		language.Bulgarian: "Покана за сватба",
+		language.English:   "Wedding invitation",
+	},
+	MsgPageGreeting: {
+		language.Bulgarian: "Скъпи %s,",
+		language.English:   "Dear %s,",
+	},
+	MsgPageAnd: {
+		language.Bulgarian: "и",
+		language.English:   "and",
+	},
+	MsgPageInvitation: {
+		language.Bulgarian: "С радост ви каним на нашата сватба.",
+		language.English:   "We would be delighted to have you at our wedding.",
+	},
+	MsgPageWhen: {
+		language.Bulgarian: "Кога",
+		language.English:   "When",
+	},
+	MsgPageWhere: {
+		language.Bulgarian: "Къде",
+		language.English:   "Where",
+	},
+	MsgPageMap: {
+		language.Bulgarian: "Виж на картата",
+		language.English:   "View on map",
+	},
+	MsgPagePlusOnes: {
+		language.Bulgarian: "Можете да доведете до %d гости. Оставете полето празно, ако идвате без придружител.",
+		language.English:   "You may bring up to %d guests. Leave a field empty if you are coming without them.",
+	},
+	MsgPageNamesHint: {
+		language.Bulgarian: "Моля, изписвайте имената на %s.",
+		language.English:   "Please write the names in %s.",
+	},
+	MsgPageGuest: {
+		language.Bulgarian: "Гост %d",
+		language.English:   "Guest %d",
+	},
+	MsgPageSubmit: {
+		language.Bulgarian: "Потвърждавам присъствие",
+		language.English:   "Confirm attendance",
+	},
+	MsgPageUpdate: {
+		language.Bulgarian: "Запази промените",
+		language.English:   "Save changes",
+	},
+	MsgPageSaved: {
+		language.Bulgarian: "Благодарим! Отговорът ви е записан.",
+		language.English:   "Thank you! Your reply has been saved.",
+	},
+	MsgPageAccepted: {
+		language.Bulgarian: "Потвърдихте присъствието си.",
+		language.English:   "You have confirmed your attendance.",
+	},
+	MsgPageFixErrors: {
+		language.Bulgarian: "Моля, коригирайте отбелязаните полета.",
+		language.English:   "Please correct the highlighted fields.",
+	},
+	MsgScriptCyrillic: {
+		language.Bulgarian: "кирилица",
+		language.English:   "Cyrillic",
+	},
+	MsgScriptLatin: {
+		language.Bulgarian: "латиница",
+		language.English:   "Latin",
+	},
+	MsgPreviewTitle: {
+		language.Bulgarian: "Покана за %s",
+		language.English:   "Invitation for %s",
+	},
+	MsgPreviewDescription: {
+		language.Bulgarian: "%s ви канят на своята сватба.",
+		language.English:   "%s invite you to their wedding.",
+	},
+}
+
+// T returns the message for key in lang, formatted with args. Languages
+// without a translation fall back to Default; unknown keys are returned as is.
+func T(lang language.Tag, key Key, args ...any) string {
+	translations, ok := catalogue[key]
+	if !ok {
+		return string(key)
+	}
+	msg, ok := translations[lang]
+	if !ok {
+		msg = translations[Default]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
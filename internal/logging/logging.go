@@ -0,0 +1,25 @@
+// Package logging carries request-scoped logrus entries through contexts and
+// keeps guest names out of log output.
+package logging
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type ctxKey struct{}
+
+// WithLogger returns a copy of ctx carrying entry.
+func WithLogger(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// FromContext returns the entry stored by WithLogger, or a plain entry on the
+// standard logger when ctx carries none.
+func FromContext(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(ctxKey{}).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
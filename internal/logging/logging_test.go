@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestFromContext_ReturnsStoredEntry(t *testing.T) {
+	entry := log.WithField("request_id", "abc")
+	ctx := WithLogger(context.Background(), entry)
+
+	if got := FromContext(ctx); got != entry {
+		t.Fatal("expected the stored entry")
+	}
+}
+
+func TestFromContext_FallsBackToStandardLogger(t *testing.T) {
+	got := FromContext(context.Background())
+	if got == nil {
+		t.Fatal("expected a fallback entry")
+	}
+	if got.Logger != log.StandardLogger() {
+		t.Fatal("expected fallback entry on the standard logger")
+	}
+	if len(got.Data) != 0 {
+		t.Fatalf("expected no fields, got %v", got.Data)
+	}
+}
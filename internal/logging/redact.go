@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// nameFields are log field keys whose values hold guest names.
+var nameFields = map[string]bool{
+	"people":     true,
+	"additional": true,
+	"name":       true,
+	"names":      true,
+}
+
+// RedactingFormatter wraps another formatter and masks guest names: values of
+// the known name fields are reduced to initials, and the offending value that
+// kin-openapi appends to validation errors is dropped.
+type RedactingFormatter struct {
+	log.Formatter
+}
+
+func (f *RedactingFormatter) Format(e *log.Entry) ([]byte, error) {
+	data := make(log.Fields, len(e.Data))
+	for k, v := range e.Data {
+		switch {
+		case nameFields[k]:
+			data[k] = redactValue(v)
+		case k == log.ErrorKey:
+			data[k] = redactError(v)
+		default:
+			data[k] = v
+		}
+	}
+
+	clone := *e
+	clone.Data = data
+	return f.Formatter.Format(&clone)
+}
+
+// RedactName keeps only the first letter of each word: "Иван Петров" becomes "И*** П***".
+func RedactName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		for _, r := range w {
+			words[i] = string(unicode.ToUpper(r)) + "***"
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return RedactName(val)
+	case []string:
+		out := make([]string, len(val))
+		for i, s := range val {
+			out[i] = RedactName(s)
+		}
+		return out
+	default:
+		return "[redacted]"
+	}
+}
+
+func redactError(v any) any {
+	var msg string
+	switch val := v.(type) {
+	case error:
+		msg = val.Error()
+	case string:
+		msg = val
+	default:
+		return v
+	}
+
+	// Reason: kin-openapi schema errors end with a "Value:" section echoing the
+	// rejected input, which for RSVP bodies contains guest names
+	if idx := strings.Index(msg, "Value:"); idx >= 0 {
+		msg = strings.TrimSpace(msg[:idx]) + " Value: [redacted]"
+	}
+	return msg
+}
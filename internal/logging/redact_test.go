@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestRedactName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Иван Петров", "И*** П***"},
+		{"мария", "М***"},
+		{"  John   Smith ", "J*** S***"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := RedactName(tt.in); got != tt.want {
+				t.Fatalf("RedactName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func formatEntry(t *testing.T, fields log.Fields) map[string]any {
+	t.Helper()
+	f := &RedactingFormatter{Formatter: &log.JSONFormatter{}}
+	entry := log.NewEntry(log.StandardLogger()).WithFields(fields)
+	entry.Message = "test"
+
+	out, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("invalid json output %q: %v", out, err)
+	}
+	return decoded
+}
+
+func TestRedactingFormatter_MasksNameFields(t *testing.T) {
+	entry := log.Fields{
+		"people":     []string{"Иван Петров"},
+		"name":       "Мария Петрова",
+		"additional": 42,
+		"invite_id":  "aaa-001",
+	}
+	got := formatEntry(t, entry)
+
+	if people := got["people"].([]any); people[0] != "И*** П***" {
+		t.Fatalf("expected redacted people, got %v", people)
+	}
+	if got["name"] != "М*** П***" {
+		t.Fatalf("expected redacted name, got %v", got["name"])
+	}
+	if got["additional"] != "[redacted]" {
+		t.Fatalf("expected unknown value type to be fully redacted, got %v", got["additional"])
+	}
+	if got["invite_id"] != "aaa-001" {
+		t.Fatalf("expected other fields untouched, got %v", got["invite_id"])
+	}
+	if _, ok := entry["name"].(string); !ok || entry["name"] != "Мария Петрова" {
+		t.Fatal("expected original entry data to be left untouched")
+	}
+}
+
+func TestRedactingFormatter_StripsValidationValue(t *testing.T) {
+	err := errors.New("doesn't match the regular expression\nSchema:\n  {}\n\nValue:\n  \"John Doe\"\n")
+	got := formatEntry(t, log.Fields{log.ErrorKey: err})
+
+	msg := got[log.ErrorKey].(string)
+	if strings.Contains(msg, "John") {
+		t.Fatalf("expected guest name to be removed, got %q", msg)
+	}
+	if !strings.Contains(msg, "regular expression") {
+		t.Fatalf("expected the rest of the error to be kept, got %q", msg)
+	}
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+)
+
+// Error codes written by the middlewares. They mirror the ErrorCode enum
+// declared in the OpenAPI specs.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidBody      = "invalid_body"
+	CodeRouteNotFound    = "route_not_found"
+	CodeRateLimited      = "rate_limited"
+	CodeTimeout          = "timeout"
+	CodeIdempotencyReuse = "idempotency_key_reused"
+	CodeInternalError    = "internal_error"
+)
+
+// Field locations, matching the FieldError.location enum.
+const (
+	LocationBody   = "body"
+	LocationPath   = "path"
+	LocationQuery  = "query"
+	LocationHeader = "header"
+)
+
+// ErrorResponse is the JSON error body shared by both APIs.
+type ErrorResponse struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError describes a single violation. Pointer is a JSON pointer
+// (RFC 6901) into the part of the request named by Location.
+type FieldError struct {
+	Location   string `json:"location"`
+	Pointer    string `json:"pointer"`
+	Constraint string `json:"constraint"`
+	Message    string `json:"message"`
+}
+
+// JSONPointer builds an RFC 6901 pointer from path segments.
+func JSONPointer(segments ...string) string {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteByte('/')
+		// Reason: "~" must be escaped before "/" so "~1" is not double-escaped
+		s = strings.ReplaceAll(s, "~", "~0")
+		b.WriteString(strings.ReplaceAll(s, "/", "~1"))
+	}
+	return b.String()
+}
+
+// validationErrorResponse converts a kin-openapi request validation error
+// (validated with MultiError enabled) into an ErrorResponse in lang.
+func validationErrorResponse(err error, lang language.Tag) ErrorResponse {
+	var parseErr *openapi3filter.ParseError
+	if errors.As(err, &parseErr) {
+		return ErrorResponse{Code: CodeInvalidBody, Message: i18n.T(lang, i18n.MsgInvalidJSON)}
+	}
+
+	var fields []FieldError
+	collectFieldErrors(err, lang, "", "", &fields)
+	return ErrorResponse{
+		Code:    CodeValidationFailed,
+		Message: i18n.T(lang, i18n.MsgValidationFailed),
+		Fields:  fields,
+	}
+}
+
+// collectFieldErrors walks the error tree kin-openapi produces: MultiErrors
+// of RequestErrors, each wrapping schema errors (possibly nested MultiErrors)
+// for one parameter or the request body.
+func collectFieldErrors(err error, lang language.Tag, location, prefix string, out *[]FieldError) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			collectFieldErrors(inner, lang, location, prefix, out)
+		}
+
+	case *openapi3filter.RequestError:
+		location, prefix = LocationBody, ""
+		if e.Parameter != nil {
+			location, prefix = e.Parameter.In, JSONPointer(e.Parameter.Name)
+		}
+		if e.Err == nil {
+			*out = append(*out, FieldError{Location: location, Pointer: prefix, Constraint: "invalid", Message: i18n.T(lang, i18n.MsgFieldInvalid)})
+			return
+		}
+		if !hasSchemaError(e.Err) {
+			// Reason: a missing body or parameter surfaces as a plain error
+			// rather than a schema violation
+			*out = append(*out, FieldError{Location: location, Pointer: prefix, Constraint: "required", Message: i18n.T(lang, i18n.MsgFieldRequired)})
+			return
+		}
+		collectFieldErrors(e.Err, lang, location, prefix, out)
+
+	case *openapi3.SchemaError:
+		if location == "" {
+			location = LocationBody
+		}
+		*out = append(*out, FieldError{
+			Location:   location,
+			Pointer:    prefix + JSONPointer(e.JSONPointer()...),
+			Constraint: e.SchemaField,
+			Message:    schemaErrorMessage(e, lang),
+		})
+
+	default:
+		if u, ok := err.(interface{ Unwrap() error }); ok && u.Unwrap() != nil {
+			collectFieldErrors(u.Unwrap(), lang, location, prefix, out)
+			return
+		}
+		*out = append(*out, FieldError{Location: location, Pointer: prefix, Constraint: "invalid", Message: i18n.T(lang, i18n.MsgFieldInvalid)})
+	}
+}
+
+func hasSchemaError(err error) bool {
+	var schemaErr *openapi3.SchemaError
+	var multi openapi3.MultiError
+	return errors.As(err, &schemaErr) || errors.As(err, &multi)
+}
+
+// schemaErrorMessage returns a short message in lang for the violated keyword
+// without echoing the submitted value back.
+func schemaErrorMessage(e *openapi3.SchemaError, lang language.Tag) string {
+	switch e.SchemaField {
+	case "pattern":
+		return i18n.T(lang, i18n.MsgFieldPattern)
+	case "minLength":
+		return i18n.T(lang, i18n.MsgFieldTooShort)
+	case "maxLength":
+		return i18n.T(lang, i18n.MsgFieldTooLong)
+	case "maxItems":
+		if e.Schema != nil && e.Schema.MaxItems != nil {
+			return i18n.T(lang, i18n.MsgFieldMaxItems, *e.Schema.MaxItems)
+		}
+		return i18n.T(lang, i18n.MsgFieldTooMany)
+	case "required":
+		return i18n.T(lang, i18n.MsgFieldRequired)
+	case "type":
+		return i18n.T(lang, i18n.MsgFieldType)
+	case "format":
+		return i18n.T(lang, i18n.MsgFieldFormat)
+	case "enum":
+		return i18n.T(lang, i18n.MsgFieldEnum)
+	default:
+		return i18n.T(lang, i18n.MsgFieldInvalid)
+	}
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestJSONPointer(t *testing.T) {
+	tests := []struct {
+		segments []string
+		want     string
+	}{
+		{nil, ""},
+		{[]string{"additional", "2"}, "/additional/2"},
+		{[]string{"a/b", "m~n"}, "/a~1b/m~0n"},
+		{[]string{"~1"}, "/~01"},
+	}
+	for _, tt := range tests {
+		if got := JSONPointer(tt.segments...); got != tt.want {
+			t.Fatalf("JSONPointer(%q): expected %q, got %q", tt.segments, tt.want, got)
+		}
+	}
+}
+
+func TestValidation_FieldErrors(t *testing.T) {
+	const invite = "/invites/550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   string
+		fields []FieldError
+	}{
+		{
+			name:   "latin name in second slot",
+			method: http.MethodPut,
+			path:   invite,
+			body:   `{"isAccepted":true,"additional":["Иван Петров","John Doe"]}`,
+			code:   CodeValidationFailed,
+			fields: []FieldError{{Location: LocationBody, Pointer: "/additional/1", Constraint: "pattern"}},
+		},
+		{
+			name:   "several invalid names",
+			method: http.MethodPut,
+			path:   invite,
+			body:   `{"isAccepted":true,"additional":["John","Иван","Иван123"]}`,
+			code:   CodeValidationFailed,
+			fields: []FieldError{
+				{Location: LocationBody, Pointer: "/additional/0", Constraint: "pattern"},
+				{Location: LocationBody, Pointer: "/additional/2", Constraint: "pattern"},
+			},
+		},
+		{
+			name:   "too many items",
+			method: http.MethodPut,
+			path:   invite,
+			body:   `{"isAccepted":true,"additional":["А","Б","В","Г","Д","Е"]}`,
+			code:   CodeValidationFailed,
+			fields: []FieldError{{Location: LocationBody, Pointer: "/additional", Constraint: "maxItems"}},
+		},
+		{
+			name:   "missing isAccepted",
+			method: http.MethodPut,
+			path:   invite,
+			body:   `{"additional":["Иван"]}`,
+			code:   CodeValidationFailed,
+			fields: []FieldError{{Location: LocationBody, Pointer: "/isAccepted", Constraint: "required"}},
+		},
+		{
+			name:   "wrong type",
+			method: http.MethodPut,
+			path:   invite,
+			body:   `{"isAccepted":"yes"}`,
+			code:   CodeValidationFailed,
+			fields: []FieldError{{Location: LocationBody, Pointer: "/isAccepted", Constraint: "type"}},
+		},
+		{
+			name:   "empty body",
+			method: http.MethodPut,
+			path:   invite,
+			body:   "",
+			code:   CodeValidationFailed,
+			fields: []FieldError{{Location: LocationBody, Pointer: "", Constraint: "required"}},
+		},
+		{
+			name:   "malformed json",
+			method: http.MethodPut,
+			path:   invite,
+			body:   `{"isAccepted":`,
+			code:   CodeInvalidBody,
+		},
+		{
+			name:   "invalid invite id",
+			method: http.MethodGet,
+			path:   "/invites/not-a-uuid",
+			code:   CodeValidationFailed,
+			fields: []FieldError{{Location: LocationPath, Pointer: "/id", Constraint: "format"}},
+		},
+	}
+
+	r := setupValidationRouter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Code != tt.code {
+				t.Fatalf("expected code %q, got %q", tt.code, resp.Code)
+			}
+			if resp.Message == "" {
+				t.Fatal("expected a message")
+			}
+			if len(resp.Fields) != len(tt.fields) {
+				t.Fatalf("expected %d field errors, got %+v", len(tt.fields), resp.Fields)
+			}
+			for i, want := range tt.fields {
+				got := resp.Fields[i]
+				if got.Location != want.Location || got.Pointer != want.Pointer || got.Constraint != want.Constraint {
+					t.Fatalf("field %d: expected %+v, got %+v", i, want, got)
+				}
+				if got.Message == "" {
+					t.Fatalf("field %d: expected a message", i)
+				}
+			}
+		})
+	}
+}
+
+func TestValidation_FieldErrorsDoNotEchoInput(t *testing.T) {
+	r := setupValidationRouter(t)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440000",
+		strings.NewReader(`{"isAccepted":true,"additional":["Secret Name"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if strings.Contains(w.Body.String(), "Secret Name") {
+		t.Fatalf("expected submitted names to be left out of the response, got %s", w.Body.String())
+	}
+}
+
+func TestValidation_RouteNotFoundCode(t *testing.T) {
+	r := setupValidationRouter(t)
+	r.GET("/unknown", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Code != CodeRouteNotFound {
+		t.Fatalf("expected code %q, got %q", CodeRouteNotFound, resp.Code)
+	}
+}
@@ -0,0 +1,273 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/logging"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+const (
+	// IdempotencyKeyHeader names the request header that makes a mutation
+	// safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on replayed responses.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyOptions configures an Idempotency. Zero values fall back to the
+// defaults noted on each field.
+type IdempotencyOptions struct {
+	// TTL is how long a key's response is kept and replayed (default 24h).
+	TTL time.Duration
+	// CleanupInterval is how often expired keys are deleted (default 1h).
+	CleanupInterval time.Duration
+}
+
+func (o IdempotencyOptions) withDefaults() IdempotencyOptions {
+	if o.TTL <= 0 {
+		o.TTL = 24 * time.Hour
+	}
+	if o.CleanupInterval <= 0 {
+		o.CleanupInterval = time.Hour
+	}
+	return o
+}
+
+// Idempotency makes mutations sent with an Idempotency-Key safe to retry.
+// The first response for a key is saved with a fingerprint of the request
+// (method, path and body); a retry with the same key and request gets that
+// response replayed without running the handler again, while reuse of the
+// key for a different request is refused with 422. Server errors (5xx) are
+// not saved, so such a request can be retried for real.
+type Idempotency struct {
+	store store.IdempotencyStore
+	opts  IdempotencyOptions
+	now   func() time.Time
+
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewIdempotency creates an Idempotency keeping responses in s. Expired keys
+// are deleted in the background until ctx is cancelled or Stop is called.
+func NewIdempotency(ctx context.Context, s store.IdempotencyStore, opts IdempotencyOptions) *Idempotency {
+	m := &Idempotency{
+		store:    s,
+		opts:     opts.withDefaults(),
+		now:      time.Now,
+		inflight: make(map[string]chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go m.cleanupLoop(ctx)
+	return m
+}
+
+// Handler returns the Gin middleware. Requests without the header, and
+// methods that are safe to repeat anyway, pass straight through.
+func (m *Idempotency) Handler() gin.HandlerFunc {
+	return m.handle
+}
+
+// Stop ends the background cleanup and waits for it to exit. It is safe to
+// call more than once.
+func (m *Idempotency) Stop() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+}
+
+func (m *Idempotency) handle(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if key == "" || !isMutation(c.Request.Method) {
+		c.Next()
+		return
+	}
+
+	ctx := c.Request.Context()
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		abortIdempotency(c, err)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+	// Reason: a retry sent while the first request is still running waits
+	// for it, then replays its response instead of running a second time
+	release, err := m.acquire(ctx, key)
+	if err != nil {
+		abortIdempotency(c, err)
+		return
+	}
+	defer release()
+
+	rec, err := m.store.GetIdempotencyRecord(ctx, key)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		// A new key: run the request and save its response below
+	case err != nil:
+		abortIdempotency(c, err)
+		return
+	case rec.CreatedAt.Before(m.now().Add(-m.opts.TTL)):
+		// Expired but not yet cleaned up: treat the key as new
+	case rec.Fingerprint != fingerprint:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Code:    CodeIdempotencyReuse,
+			Message: i18n.T(i18n.FromContext(ctx), i18n.MsgIdempotencyReuse),
+		})
+		return
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		if rec.ContentLanguage != "" {
+			c.Header("Content-Language", rec.ContentLanguage)
+		}
+		c.Data(rec.Status, rec.ContentType, rec.Body)
+		c.Abort()
+		return
+	}
+
+	recorder := &recordingResponseWriter{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+	c.Writer = recorder.ResponseWriter
+
+	status := recorder.Status()
+	if status >= http.StatusInternalServerError {
+		return
+	}
+	rec = &store.IdempotencyRecord{
+		Fingerprint:     fingerprint,
+		Status:          status,
+		ContentType:     recorder.Header().Get("Content-Type"),
+		ContentLanguage: recorder.Header().Get("Content-Language"),
+		Body:            recorder.body.Bytes(),
+		CreatedAt:       m.now().UTC(),
+	}
+	// Reason: the change is already made, so the key must be saved even if
+	// the request deadline ran out meanwhile, or a retry would repeat it
+	if err := m.store.PutIdempotencyRecord(context.WithoutCancel(ctx), key, *rec); err != nil {
+		logging.FromContext(ctx).WithError(err).Error("failed to save idempotency key")
+	}
+}
+
+// acquire waits until no other request holds key, then holds it until the
+// returned release is called.
+func (m *Idempotency) acquire(ctx context.Context, key string) (release func(), err error) {
+	for {
+		m.mu.Lock()
+		wait, busy := m.inflight[key]
+		if !busy {
+			done := make(chan struct{})
+			m.inflight[key] = done
+			m.mu.Unlock()
+			return func() {
+				m.mu.Lock()
+				delete(m.inflight, key)
+				m.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// abortIdempotency answers a request whose key could not be checked. It is
+// not passed on to the handler, since it might then run twice.
+func abortIdempotency(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	lang := i18n.FromContext(ctx)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrorResponse{
+			Code:    CodeTimeout,
+			Message: i18n.T(lang, i18n.MsgTimeout),
+		})
+		return
+	}
+	logging.FromContext(ctx).WithError(err).Error("failed to check idempotency key")
+	c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{
+		Code:    CodeInternalError,
+		Message: i18n.T(lang, i18n.MsgInternalError),
+	})
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint hashes what makes two requests the same one.
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cleanupLoop periodically deletes keys older than TTL.
+func (m *Idempotency) cleanupLoop(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.opts.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			n, err := m.store.DeleteIdempotencyRecords(ctx, now.Add(-m.opts.TTL))
+			if err != nil {
+				log.WithError(err).Warn("failed to delete expired idempotency keys")
+				continue
+			}
+			if n > 0 {
+				log.WithField("count", n).Debug("deleted expired idempotency keys")
+			}
+		}
+	}
+}
+
+// recordingResponseWriter passes the response through while keeping a copy
+// of the body to save.
+type recordingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
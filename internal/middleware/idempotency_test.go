@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// setupIdempotencyRouter serves PUT /invites/:id, answering with how many
+// times the handler ran; a body of "fail" makes it answer 500 instead.
+func setupIdempotencyRouter(t *testing.T, opts IdempotencyOptions) (*gin.Engine, *Idempotency, *store.MemoryStore, *atomic.Int32) {
+	t.Helper()
+	s := store.NewMemoryStore()
+	m := NewIdempotency(context.Background(), s, opts)
+	t.Cleanup(m.Stop)
+
+	var calls atomic.Int32
+	r := gin.New()
+	r.Use(NewLanguageDetector())
+	r.Use(m.Handler())
+	r.PUT("/invites/:id", func(c *gin.Context) {
+		n := calls.Add(1)
+		body, _ := c.GetRawData()
+		if string(body) == "fail" {
+			c.JSON(http.StatusInternalServerError, gin.H{"call": n})
+			return
+		}
+		// Reason: like PutInvite answering in the invite's own language
+		c.Header("Content-Language", "bg")
+		c.JSON(http.StatusOK, gin.H{"call": n, "body": string(body)})
+	})
+	r.GET("/invites/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"call": calls.Add(1)})
+	})
+	return r, m, s, &calls
+}
+
+func doIdempotent(r *gin.Engine, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Accept-Language", "en")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	r, _, _, calls := setupIdempotencyRouter(t, IdempotencyOptions{})
+
+	first := doIdempotent(r, http.MethodPut, "/invites/a", "k1", `{"isAccepted":true}`)
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", first.Code)
+	}
+	if got := first.Header().Get(IdempotentReplayedHeader); got != "" {
+		t.Fatalf("expected no replay header on the first response, got %q", got)
+	}
+
+	retry := doIdempotent(r, http.MethodPut, "/invites/a", "k1", `{"isAccepted":true}`)
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected the first response replayed, got %d %s", retry.Code, retry.Body.String())
+	}
+	if got := retry.Header().Get(IdempotentReplayedHeader); got != "true" {
+		t.Fatalf("expected %s: true, got %q", IdempotentReplayedHeader, got)
+	}
+	if got := retry.Header().Get("Content-Type"); got != first.Header().Get("Content-Type") {
+		t.Fatalf("expected content type %q replayed, got %q", first.Header().Get("Content-Type"), got)
+	}
+	if got := retry.Header().Get("Content-Language"); got != "bg" {
+		t.Fatalf("expected Content-Language bg replayed, got %q", got)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", n)
+	}
+}
+
+func TestIdempotency_KeyReused(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"different body", "/invites/a", `{"isAccepted":false}`},
+		{"different invite", "/invites/b", `{"isAccepted":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _, _, calls := setupIdempotencyRouter(t, IdempotencyOptions{})
+			doIdempotent(r, http.MethodPut, "/invites/a", "k1", `{"isAccepted":true}`)
+
+			w := doIdempotent(r, http.MethodPut, tt.path, "k1", tt.body)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d", w.Code)
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			if resp.Code != CodeIdempotencyReuse || resp.Message == "" {
+				t.Fatalf("expected %s error, got %+v", CodeIdempotencyReuse, resp)
+			}
+			if n := calls.Load(); n != 1 {
+				t.Fatalf("expected the handler to run once, ran %d times", n)
+			}
+		})
+	}
+}
+
+func TestIdempotency_PassThrough(t *testing.T) {
+	r, _, _, calls := setupIdempotencyRouter(t, IdempotencyOptions{})
+
+	doIdempotent(r, http.MethodPut, "/invites/a", "", "{}")
+	doIdempotent(r, http.MethodPut, "/invites/a", "", "{}")
+	doIdempotent(r, http.MethodGet, "/invites/a", "k1", "")
+	doIdempotent(r, http.MethodGet, "/invites/a", "k1", "")
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("expected every request without a key, or safe method, to run, ran %d", n)
+	}
+}
+
+func TestIdempotency_ServerErrorsNotSaved(t *testing.T) {
+	r, _, s, calls := setupIdempotencyRouter(t, IdempotencyOptions{})
+
+	for i := range 2 {
+		if w := doIdempotent(r, http.MethodPut, "/invites/a", "k1", "fail"); w.Code != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: expected 500, got %d", i, w.Code)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected a failed request to run again on retry, ran %d", n)
+	}
+	if _, err := s.GetIdempotencyRecord(context.Background(), "k1"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected no saved key, got %v", err)
+	}
+}
+
+func TestIdempotency_Expired(t *testing.T) {
+	r, m, _, calls := setupIdempotencyRouter(t, IdempotencyOptions{TTL: time.Hour})
+
+	doIdempotent(r, http.MethodPut, "/invites/a", "k1", "{}")
+	m.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	w := doIdempotent(r, http.MethodPut, "/invites/a", "k1", `{"changed":true}`)
+	if w.Code != http.StatusOK || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("expected an expired key to be treated as new, got %d", w.Code)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected the handler to run twice, ran %d", n)
+	}
+}
+
+func TestIdempotency_ConcurrentRetries(t *testing.T) {
+	r, _, _, calls := setupIdempotencyRouter(t, IdempotencyOptions{})
+
+	const n = 10
+	bodies := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bodies[i] = doIdempotent(r, http.MethodPut, "/invites/a", "k1", "{}").Body.String()
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", got)
+	}
+	for i, b := range bodies {
+		if b != bodies[0] {
+			t.Fatalf("request %d: expected %s, got %s", i, bodies[0], b)
+		}
+	}
+}
+
+func TestIdempotency_Cleanup(t *testing.T) {
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+	for key, age := range map[string]time.Duration{"old": time.Hour, "new": 0} {
+		rec := store.IdempotencyRecord{Fingerprint: key, Status: 200, CreatedAt: time.Now().Add(-age)}
+		if err := s.PutIdempotencyRecord(ctx, key, rec); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	m := NewIdempotency(ctx, s, IdempotencyOptions{TTL: time.Minute, CleanupInterval: 10 * time.Millisecond})
+	t.Cleanup(m.Stop)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, err := s.GetIdempotencyRecord(ctx, "old")
+		if errors.Is(err, store.ErrNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the expired key to be deleted, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := s.GetIdempotencyRecord(ctx, "new"); err != nil {
+		t.Fatalf("expected the fresh key kept, got %v", err)
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	base := requestFingerprint(http.MethodPut, "/invites/a", []byte("{}"))
+	for _, other := range []string{
+		requestFingerprint(http.MethodPost, "/invites/a", []byte("{}")),
+		requestFingerprint(http.MethodPut, "/invites/b", []byte("{}")),
+		requestFingerprint(http.MethodPut, "/invites/a", []byte(`{"a":1}`)),
+	} {
+		if other == base {
+			t.Fatalf("expected a different fingerprint than %s", base)
+		}
+	}
+	if requestFingerprint(http.MethodPut, "/invites/a", []byte("{}")) != base {
+		t.Fatal("expected the same request to give the same fingerprint")
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+)
+
+// NewLanguageDetector returns a middleware that picks the response language
+// from Accept-Language, stores it in the request context for i18n.FromContext
+// and announces it with Content-Language. Handlers may override the choice,
+// e.g. with an invite's preferred language.
+func NewLanguageDetector() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Match(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+
+		c.Header("Content-Language", lang.String())
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+)
+
+func TestLanguageDetector_SetsContextAndHeaders(t *testing.T) {
+	r := gin.New()
+	r.Use(NewLanguageDetector())
+	r.GET("/lang", func(c *gin.Context) {
+		c.String(http.StatusOK, i18n.FromContext(c.Request.Context()).String())
+	})
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "bg"},
+		{"en-GB,en;q=0.8", "en"},
+		{"fr-FR", "bg"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/lang", nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		r.ServeHTTP(w, req)
+
+		if w.Body.String() != tt.want {
+			t.Fatalf("Accept-Language %q: expected %s in context, got %s", tt.acceptLanguage, tt.want, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Language"); got != tt.want {
+			t.Fatalf("expected Content-Language %s, got %q", tt.want, got)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Language" {
+			t.Fatalf("expected Vary: Accept-Language, got %q", got)
+		}
+	}
+}
+
+func TestLanguageDetector_LocalizesMiddlewareErrors(t *testing.T) {
+	spec := loadTestSpec(t)
+	validator, err := NewOpenAPIValidator(spec)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	rl, err := NewRateLimiter(t.Context(), catchAllPolicies(1, 1), RateLimiterOptions{})
+	if err != nil {
+		t.Fatalf("failed to create rate limiter: %v", err)
+	}
+	t.Cleanup(rl.Stop)
+
+	r := gin.New()
+	r.Use(NewLanguageDetector(), rl.Handler(), validator)
+	r.PUT("/invites/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(acceptLanguage string) ErrorResponse {
+		body := []byte(`{"isAccepted":true,"additional":["John"]}`)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440000", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", acceptLanguage)
+		r.ServeHTTP(w, req)
+
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	resp := send("en")
+	if resp.Code != CodeValidationFailed || resp.Fields[0].Message != "contains characters that are not allowed" {
+		t.Fatalf("expected English validation error, got %+v", resp)
+	}
+
+	resp = send("bg")
+	if resp.Code != CodeRateLimited || resp.Message != "твърде много заявки, моля опитайте отново по-късно" {
+		t.Fatalf("expected Bulgarian rate limit error, got %+v", resp)
+	}
+}
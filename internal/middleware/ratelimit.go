@@ -1,74 +1,181 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/time/rate"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
 )
 
-type visitor struct {
-	limiter  *rate.Limiter
-	lastSeen time.Time
+// RateLimiterOptions bounds the memory and bookkeeping of a RateLimiter.
+// Zero values fall back to the defaults noted on each field.
+type RateLimiterOptions struct {
+	// MaxVisitors caps the number of tracked buckets (default 10000).
+	MaxVisitors int
+	// IdleTimeout is how long an unused bucket is kept (default 3m).
+	IdleTimeout time.Duration
+	// CleanupInterval is how often idle buckets are evicted (default 1m).
+	CleanupInterval time.Duration
 }
 
-// RateLimiter tracks per-IP token bucket limiters.
-type RateLimiter struct {
-	visitors sync.Map
-	rps      rate.Limit
-	burst    int
+func (o RateLimiterOptions) withDefaults() RateLimiterOptions {
+	if o.MaxVisitors <= 0 {
+		o.MaxVisitors = 10000
+	}
+	if o.IdleTimeout <= 0 {
+		o.IdleTimeout = 3 * time.Minute
+	}
+	if o.CleanupInterval <= 0 {
+		o.CleanupInterval = 1 * time.Minute
+	}
+	return o
 }
 
-// NewRateLimiter creates a Gin middleware that applies per-IP rate limiting.
-// rps controls the steady-state rate (requests per second), burst is the
-// maximum number of tokens that can be consumed in a single burst.
-func NewRateLimiter(rps rate.Limit, burst int) gin.HandlerFunc {
-	rl := &RateLimiter{rps: rps, burst: burst}
-	go rl.cleanupLoop()
-	return rl.handle
+// RateLimiter enforces a RateLimitPolicies table with token bucket limiters
+// tracked per policy, rule and key (client IP or invite ID).
+type RateLimiter struct {
+	visitors *visitorCache
+	policies RateLimitPolicies
+	opts     RateLimiterOptions
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
 }
 
-func (rl *RateLimiter) getVisitor(ip string) *rate.Limiter {
-	val, ok := rl.visitors.Load(ip)
-	if ok {
-		v := val.(*visitor)
-		v.lastSeen = time.Now()
-		return v.limiter
+// NewRateLimiter creates a rate limiter that applies the given policy table.
+// Each request is matched against the table by method and route; the first
+// matching policy decides whether it is exempt or which limits apply.
+// The background cleanup runs until ctx is cancelled or Stop is called.
+func NewRateLimiter(ctx context.Context, policies RateLimitPolicies, opts RateLimiterOptions) (*RateLimiter, error) {
+	if err := policies.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit policies: %w", err)
 	}
 
-	limiter := rate.NewLimiter(rl.rps, rl.burst)
-	rl.visitors.Store(ip, &visitor{limiter: limiter, lastSeen: time.Now()})
-	return limiter
+	opts = opts.withDefaults()
+	rl := &RateLimiter{
+		visitors: newVisitorCache(opts.MaxVisitors),
+		policies: policies.normalize(),
+		opts:     opts,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go rl.cleanupLoop(ctx)
+	return rl, nil
+}
+
+// Handler returns the Gin middleware enforcing the policies.
+func (rl *RateLimiter) Handler() gin.HandlerFunc {
+	return rl.handle
+}
+
+// Stop ends the background cleanup and waits for it to exit. It is safe to
+// call more than once.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+	<-rl.done
 }
 
 func (rl *RateLimiter) handle(c *gin.Context) {
-	ip := c.ClientIP()
-	limiter := rl.getVisitor(ip)
+	idx := rl.policies.match(c.Request.Method, c.FullPath())
+	if idx < 0 || rl.policies[idx].Exempt {
+		c.Next()
+		return
+	}
+
+	_, span := otel.Tracer(tracerName).Start(c.Request.Context(), "ratelimit.Check")
+	span.SetAttributes(
+		attribute.Int("ratelimit.policy", idx),
+		attribute.String("ratelimit.route", rl.policies[idx].Route),
+	)
 
-	if !limiter.Allow() {
-		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-			"message": "too many requests, please try again later",
+	now := time.Now()
+	reservations := make([]*rate.Reservation, 0, len(rl.policies[idx].Limits))
+	for j, rule := range rl.policies[idx].Limits {
+		key, ok := ruleKey(c, rule.Key)
+		if !ok {
+			continue
+		}
+
+		limiter := rl.visitors.get(fmt.Sprintf("%d/%d/%s", idx, j, key), now, func() *rate.Limiter {
+			return rate.NewLimiter(rate.Limit(rule.RPS), rule.Burst)
 		})
-		return
+		res := limiter.ReserveN(now, 1)
+		if delay := res.DelayFrom(now); delay > 0 {
+			// Reason: give back tokens taken from earlier rules so a request
+			// rejected by one limit does not drain the others
+			res.CancelAt(now)
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			span.SetAttributes(
+				attribute.Bool("ratelimit.allowed", false),
+				attribute.String("ratelimit.key", string(rule.Key)),
+			)
+			span.End()
+			rejectRateLimited(c, rule, delay)
+			return
+		}
+		reservations = append(reservations, res)
 	}
 
+	span.SetAttributes(attribute.Bool("ratelimit.allowed", true))
+	span.End()
 	c.Next()
 }
 
-// cleanupLoop removes visitors that haven't been seen for 3 minutes.
-func (rl *RateLimiter) cleanupLoop() {
-	ticker := time.NewTicker(1 * time.Minute)
+// ruleKey extracts the value a rule is tracked by. Rules keyed by invite ID
+// are skipped on routes without an ":id" parameter.
+func ruleKey(c *gin.Context, key RateLimitKey) (string, bool) {
+	switch key {
+	case KeyByInviteID:
+		id := c.Param("id")
+		return id, id != ""
+	default:
+		return c.ClientIP(), true
+	}
+}
+
+// rejectRateLimited aborts with 429 and the Retry-After and RateLimit-* headers
+// describing the limit that was exceeded.
+func rejectRateLimited(c *gin.Context, rule RateLimitRule, delay time.Duration) {
+	seconds := strconv.Itoa(int(math.Ceil(delay.Seconds())))
+
+	c.Header("Retry-After", seconds)
+	c.Header("RateLimit-Limit", strconv.Itoa(rule.Burst))
+	c.Header("RateLimit-Remaining", "0")
+	c.Header("RateLimit-Reset", seconds)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrorResponse{
+		Code:    CodeRateLimited,
+		Message: i18n.T(i18n.FromContext(c.Request.Context()), i18n.MsgRateLimited),
+	})
+}
+
+// cleanupLoop periodically removes visitors idle for longer than IdleTimeout.
+func (rl *RateLimiter) cleanupLoop(ctx context.Context) {
+	defer close(rl.done)
+
+	ticker := time.NewTicker(rl.opts.CleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		rl.visitors.Range(func(key, value any) bool {
-			v := value.(*visitor)
-			if time.Since(v.lastSeen) > 3*time.Minute {
-				rl.visitors.Delete(key)
-			}
-			return true
-		})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rl.stop:
+			return
+		case now := <-ticker.C:
+			rl.visitors.evictIdle(now.Add(-rl.opts.IdleTimeout))
+		}
 	}
 }
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RateLimitKey selects which request attribute a limit is tracked by.
+type RateLimitKey string
+
+const (
+	// KeyByIP tracks a limit per client IP.
+	KeyByIP RateLimitKey = "ip"
+	// KeyByInviteID tracks a limit per invite, taken from the ":id" path parameter.
+	KeyByInviteID RateLimitKey = "invite_id"
+)
+
+// anyMatch is the wildcard accepted for both Method and Route.
+const anyMatch = "*"
+
+// RateLimitRule is a single token bucket applied to requests matching a policy.
+type RateLimitRule struct {
+	Key   RateLimitKey `json:"key"`
+	RPS   float64      `json:"rps"`
+	Burst int          `json:"burst"`
+}
+
+// RateLimitPolicy binds a set of limits to a route and method. Route uses the
+// Gin full path ("/invites/:id"); the OpenAPI form ("/invites/{id}") is
+// accepted as well. Method and Route may be "*" to match anything.
+type RateLimitPolicy struct {
+	Method string          `json:"method"`
+	Route  string          `json:"route"`
+	Exempt bool            `json:"exempt,omitempty"`
+	Limits []RateLimitRule `json:"limits,omitempty"`
+}
+
+// RateLimitPolicies is an ordered policy table; the first matching policy wins.
+type RateLimitPolicies []RateLimitPolicy
+
+var openAPIParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// DefaultRateLimitPolicies returns the built-in table: /health and the RSVP
+// page's static files and preview image are exempt, invite reads are limited per IP, and invite
+// writes through the API or the RSVP form are limited per IP and per invite
+// ID. Any other route falls back to the per-IP limit.
+func DefaultRateLimitPolicies(rps float64, burst int, inviteRPS float64, inviteBurst int) RateLimitPolicies {
+	perIP := RateLimitRule{Key: KeyByIP, RPS: rps, Burst: burst}
+	perInvite := RateLimitRule{Key: KeyByInviteID, RPS: inviteRPS, Burst: inviteBurst}
+	return RateLimitPolicies{
+		{Method: "GET", Route: "/health", Exempt: true},
+		{Method: anyMatch, Route: "/static/*filepath", Exempt: true},
+		{Method: anyMatch, Route: "/preview.png", Exempt: true},
+		{Method: "GET", Route: "/invites/:id", Limits: []RateLimitRule{perIP}},
+		{Method: "PUT", Route: "/invites/:id", Limits: []RateLimitRule{perIP, perInvite}},
+		{Method: "POST", Route: "/i/:id", Limits: []RateLimitRule{perIP, perInvite}},
+		{Method: anyMatch, Route: anyMatch, Limits: []RateLimitRule{perIP}},
+	}
+}
+
+// LoadRateLimitPolicies reads a JSON policy table from path and validates it.
+func LoadRateLimitPolicies(path string) (RateLimitPolicies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rate limit policy file %s: %w", path, err)
+	}
+
+	var policies RateLimitPolicies
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("parsing rate limit policy file %s: %w", path, err)
+	}
+
+	if err := policies.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit policy file %s: %w", path, err)
+	}
+	return policies.normalize(), nil
+}
+
+// Validate reports the first malformed policy in the table.
+func (p RateLimitPolicies) Validate() error {
+	for i, pol := range p {
+		if pol.Route == "" {
+			return fmt.Errorf("policy %d: route is required", i)
+		}
+		if pol.Exempt && len(pol.Limits) > 0 {
+			return fmt.Errorf("policy %d: exempt policies cannot declare limits", i)
+		}
+		if !pol.Exempt && len(pol.Limits) == 0 {
+			return fmt.Errorf("policy %d: at least one limit is required", i)
+		}
+		for j, rule := range pol.Limits {
+			if rule.Key != KeyByIP && rule.Key != KeyByInviteID {
+				return fmt.Errorf("policy %d limit %d: unknown key %q", i, j, rule.Key)
+			}
+			if rule.RPS <= 0 {
+				return fmt.Errorf("policy %d limit %d: rps must be positive", i, j)
+			}
+			if rule.Burst < 1 {
+				return fmt.Errorf("policy %d limit %d: burst must be at least 1", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// normalize upper-cases methods and converts OpenAPI path templates to Gin form.
+func (p RateLimitPolicies) normalize() RateLimitPolicies {
+	out := make(RateLimitPolicies, len(p))
+	for i, pol := range p {
+		pol.Method = strings.ToUpper(pol.Method)
+		if pol.Method == "" {
+			pol.Method = anyMatch
+		}
+		pol.Route = openAPIParam.ReplaceAllString(pol.Route, ":$1")
+		out[i] = pol
+	}
+	return out
+}
+
+// match returns the index of the first policy covering method and route, or -1.
+func (p RateLimitPolicies) match(method, route string) int {
+	for i, pol := range p {
+		if pol.Method != anyMatch && pol.Method != method {
+			continue
+		}
+		if pol.Route != anyMatch && pol.Route != route {
+			continue
+		}
+		return i
+	}
+	return -1
+}
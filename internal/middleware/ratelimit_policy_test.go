@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePolicyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policies.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write policy file: %v", err)
+	}
+	return path
+}
+
+func TestLoadRateLimitPolicies_Expected(t *testing.T) {
+	path := writePolicyFile(t, `[
+		{"method": "get", "route": "/health", "exempt": true},
+		{"method": "PUT", "route": "/invites/{id}", "limits": [
+			{"key": "ip", "rps": 1, "burst": 5},
+			{"key": "invite_id", "rps": 0.1, "burst": 2}
+		]},
+		{"route": "*", "limits": [{"key": "ip", "rps": 2, "burst": 10}]}
+	]`)
+
+	policies, err := LoadRateLimitPolicies(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(policies) != 3 {
+		t.Fatalf("expected 3 policies, got %d", len(policies))
+	}
+	if got := policies.match("GET", "/health"); got != 0 {
+		t.Fatalf("expected lowercase method to match policy 0, got %d", got)
+	}
+	if got := policies.match("PUT", "/invites/:id"); got != 1 {
+		t.Fatalf("expected OpenAPI route to match policy 1, got %d", got)
+	}
+	if got := policies.match("DELETE", "/anything"); got != 2 {
+		t.Fatalf("expected empty method to act as wildcard, got %d", got)
+	}
+}
+
+func TestLoadRateLimitPolicies_MissingFile(t *testing.T) {
+	_, err := LoadRateLimitPolicies(filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestLoadRateLimitPolicies_InvalidJSON(t *testing.T) {
+	_, err := LoadRateLimitPolicies(writePolicyFile(t, "not json"))
+	if err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+}
+
+func TestRateLimitPolicies_Validate(t *testing.T) {
+	ipRule := RateLimitRule{Key: KeyByIP, RPS: 1, Burst: 1}
+
+	tests := []struct {
+		name    string
+		policy  RateLimitPolicy
+		wantErr bool
+	}{
+		{"valid", RateLimitPolicy{Route: "*", Limits: []RateLimitRule{ipRule}}, false},
+		{"valid exempt", RateLimitPolicy{Route: "/health", Exempt: true}, false},
+		{"missing route", RateLimitPolicy{Limits: []RateLimitRule{ipRule}}, true},
+		{"exempt with limits", RateLimitPolicy{Route: "*", Exempt: true, Limits: []RateLimitRule{ipRule}}, true},
+		{"no limits", RateLimitPolicy{Route: "*"}, true},
+		{"unknown key", RateLimitPolicy{Route: "*", Limits: []RateLimitRule{{Key: "cookie", RPS: 1, Burst: 1}}}, true},
+		{"zero rps", RateLimitPolicy{Route: "*", Limits: []RateLimitRule{{Key: KeyByIP, Burst: 1}}}, true},
+		{"zero burst", RateLimitPolicy{Route: "*", Limits: []RateLimitRule{{Key: KeyByIP, RPS: 1}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RateLimitPolicies{tt.policy}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
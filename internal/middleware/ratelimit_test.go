@@ -1,30 +1,65 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
 func init() {
 	gin.SetMode(gin.TestMode)
 }
 
-func setupRateLimitRouter(rps float64, burst int) *gin.Engine {
+func setupRateLimitRouter(t *testing.T, rps float64, burst int) *gin.Engine {
+	t.Helper()
+	return setupPolicyRouter(t, RateLimitPolicies{
+		{Method: "*", Route: "*", Limits: []RateLimitRule{{Key: KeyByIP, RPS: rps, Burst: burst}}},
+	})
+}
+
+func setupPolicyRouter(t *testing.T, policies RateLimitPolicies) *gin.Engine {
+	t.Helper()
+	rl, err := NewRateLimiter(context.Background(), policies, RateLimiterOptions{})
+	if err != nil {
+		t.Fatalf("failed to create rate limiter: %v", err)
+	}
+	t.Cleanup(rl.Stop)
+
 	r := gin.New()
-	r.Use(NewRateLimiter(rate.Limit(rps), burst))
-	r.GET("/test", func(c *gin.Context) {
+	r.Use(rl.Handler())
+	ok := func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"ok": true})
-	})
+	}
+	r.GET("/test", ok)
+	r.GET("/health", ok)
+	r.GET("/invites/:id", ok)
+	r.PUT("/invites/:id", ok)
+	r.GET("/i/:id", ok)
+	r.POST("/i/:id", ok)
+	r.GET("/static/*filepath", ok)
 	return r
 }
 
+func doRequest(r *gin.Engine, method, path, ip string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	if ip != "" {
+		req.RemoteAddr = ip + ":1234"
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
 func TestRateLimiter_AllowsWithinBurst(t *testing.T) {
-	r := setupRateLimitRouter(1, 5)
+	r := setupRateLimitRouter(t, 1, 5)
 
 	for i := 0; i < 5; i++ {
 		w := httptest.NewRecorder()
@@ -37,7 +72,7 @@ func TestRateLimiter_AllowsWithinBurst(t *testing.T) {
 }
 
 func TestRateLimiter_BlocksOverBurst(t *testing.T) {
-	r := setupRateLimitRouter(1, 2)
+	r := setupRateLimitRouter(t, 1, 2)
 
 	// Exhaust the burst
 	for i := 0; i < 2; i++ {
@@ -55,17 +90,20 @@ func TestRateLimiter_BlocksOverBurst(t *testing.T) {
 		t.Fatalf("expected 429, got %d", w.Code)
 	}
 
-	var body map[string]string
+	var body ErrorResponse
 	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
 		t.Fatalf("failed to decode body: %v", err)
 	}
-	if body["message"] == "" {
+	if body.Message == "" {
 		t.Fatal("expected error message in response body")
 	}
+	if body.Code != CodeRateLimited {
+		t.Fatalf("expected code %q, got %q", CodeRateLimited, body.Code)
+	}
 }
 
 func TestRateLimiter_DifferentIPsHaveSeparateLimits(t *testing.T) {
-	r := setupRateLimitRouter(1, 1)
+	r := setupRateLimitRouter(t, 1, 1)
 
 	// First IP exhausts its limit
 	w1 := httptest.NewRecorder()
@@ -90,3 +128,274 @@ func TestRateLimiter_DifferentIPsHaveSeparateLimits(t *testing.T) {
 		t.Fatalf("expected 200 for second IP, got %d", w2.Code)
 	}
 }
+
+func TestRateLimiter_RejectionHeaders(t *testing.T) {
+	r := setupRateLimitRouter(t, 0.5, 1)
+
+	doRequest(r, http.MethodGet, "/test", "")
+	w := doRequest(r, http.MethodGet, "/test", "")
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After=2, got %q", got)
+	}
+	if got := w.Header().Get("RateLimit-Limit"); got != "1" {
+		t.Fatalf("expected RateLimit-Limit=1, got %q", got)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Fatalf("expected RateLimit-Remaining=0, got %q", got)
+	}
+	if got := w.Header().Get("RateLimit-Reset"); got != "2" {
+		t.Fatalf("expected RateLimit-Reset=2, got %q", got)
+	}
+}
+
+func TestRateLimiter_DefaultPolicies(t *testing.T) {
+	const invite = "/invites/550e8400-e29b-41d4-a716-446655440000"
+
+	type call struct{ method, path, ip string }
+
+	tests := []struct {
+		name  string
+		prior []call
+		last  call
+		want  int
+	}{
+		{
+			name:  "health is exempt",
+			prior: []call{{http.MethodGet, "/health", "1.1.1.1"}, {http.MethodGet, "/health", "1.1.1.1"}},
+			last:  call{http.MethodGet, "/health", "1.1.1.1"},
+			want:  http.StatusOK,
+		},
+		{
+			name:  "GET and PUT have separate buckets",
+			prior: []call{{http.MethodGet, invite, "1.1.1.1"}},
+			last:  call{http.MethodPut, invite, "1.1.1.1"},
+			want:  http.StatusOK,
+		},
+		{
+			name:  "GET over per-IP burst",
+			prior: []call{{http.MethodGet, invite, "1.1.1.1"}},
+			last:  call{http.MethodGet, invite, "1.1.1.1"},
+			want:  http.StatusTooManyRequests,
+		},
+		{
+			name:  "PUT limited per invite across IPs",
+			prior: []call{{http.MethodPut, invite, "1.1.1.1"}},
+			last:  call{http.MethodPut, invite, "2.2.2.2"},
+			want:  http.StatusTooManyRequests,
+		},
+		{
+			name:  "RSVP page static files are exempt",
+			prior: []call{{http.MethodGet, "/static/style.css", "1.1.1.1"}},
+			last:  call{http.MethodGet, "/static/style.css", "1.1.1.1"},
+			want:  http.StatusOK,
+		},
+		{
+			name:  "RSVP form limited per invite across IPs",
+			prior: []call{{http.MethodPost, "/i/abc", "1.1.1.1"}},
+			last:  call{http.MethodPost, "/i/abc", "2.2.2.2"},
+			want:  http.StatusTooManyRequests,
+		},
+		{
+			name:  "unlisted route falls back to per-IP limit",
+			prior: []call{{http.MethodGet, "/test", "1.1.1.1"}},
+			last:  call{http.MethodGet, "/test", "1.1.1.1"},
+			want:  http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupPolicyRouter(t, DefaultRateLimitPolicies(1, 1, 1, 1))
+			for _, c := range tt.prior {
+				doRequest(r, c.method, c.path, c.ip)
+			}
+
+			w := doRequest(r, tt.last.method, tt.last.path, tt.last.ip)
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_RejectedRequestRefundsOtherLimits(t *testing.T) {
+	const invite = "/invites/550e8400-e29b-41d4-a716-446655440000"
+	r := setupPolicyRouter(t, DefaultRateLimitPolicies(1, 2, 1, 1))
+
+	// First PUT consumes the only per-invite token; the second is rejected by it
+	doRequest(r, http.MethodPut, invite, "1.1.1.1")
+	if w := doRequest(r, http.MethodPut, invite, "1.1.1.1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+
+	// The per-IP token taken by the rejected request must have been given back
+	other := "/invites/550e8400-e29b-41d4-a716-446655440001"
+	if w := doRequest(r, http.MethodPut, other, "1.1.1.1"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for other invite, got %d", w.Code)
+	}
+}
+
+func TestNewRateLimiter_InvalidPolicies(t *testing.T) {
+	_, err := NewRateLimiter(context.Background(), RateLimitPolicies{{Method: "GET", Route: "/test"}}, RateLimiterOptions{})
+	if err == nil {
+		t.Fatal("expected error for policy without limits")
+	}
+}
+
+func TestRateLimiter_RecordsSpan(t *testing.T) {
+	sr := recordSpans(t)
+	r := setupPolicyRouter(t, DefaultRateLimitPolicies(1, 1, 1, 1))
+
+	doRequest(r, http.MethodGet, "/health", "")
+	doRequest(r, http.MethodGet, "/test", "")
+	doRequest(r, http.MethodGet, "/test", "")
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected spans for the two limited requests only, got %d", len(spans))
+	}
+	allowed := map[bool]bool{}
+	for _, s := range spans {
+		for _, a := range s.Attributes() {
+			if a.Key == "ratelimit.allowed" {
+				allowed[a.Value.AsBool()] = true
+			}
+		}
+	}
+	if !allowed[true] || !allowed[false] {
+		t.Fatalf("expected one allowed and one rejected span, got %v", allowed)
+	}
+}
+
+func catchAllPolicies(rps float64, burst int) RateLimitPolicies {
+	return RateLimitPolicies{
+		{Method: "*", Route: "*", Limits: []RateLimitRule{{Key: KeyByIP, RPS: rps, Burst: burst}}},
+	}
+}
+
+func TestRateLimiter_StopIsIdempotent(t *testing.T) {
+	rl, err := NewRateLimiter(context.Background(), catchAllPolicies(1, 1), RateLimiterOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rl.Stop()
+	rl.Stop()
+
+	select {
+	case <-rl.done:
+	default:
+		t.Fatal("expected cleanup loop to have exited")
+	}
+}
+
+func TestRateLimiter_ContextCancelStopsCleanup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rl, err := NewRateLimiter(ctx, catchAllPolicies(1, 1), RateLimiterOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cancel()
+	select {
+	case <-rl.done:
+	case <-time.After(time.Second):
+		t.Fatal("cleanup loop did not exit after context cancellation")
+	}
+}
+
+func TestRateLimiter_CleanupEvictsIdleVisitors(t *testing.T) {
+	rl, err := NewRateLimiter(context.Background(), catchAllPolicies(1, 1), RateLimiterOptions{
+		IdleTimeout:     time.Millisecond,
+		CleanupInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(rl.Stop)
+
+	r := gin.New()
+	r.Use(rl.Handler())
+	r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	doRequest(r, http.MethodGet, "/test", "1.1.1.1")
+
+	deadline := time.Now().Add(time.Second)
+	for rl.visitors.len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("idle visitor was not evicted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestRateLimiter_ConcurrentHighCardinality is meant to be run with -race: it
+// hammers the limiter from many goroutines with unique IPs while the cleanup
+// loop evicts concurrently.
+func TestRateLimiter_ConcurrentHighCardinality(t *testing.T) {
+	const maxVisitors = 100
+
+	rl, err := NewRateLimiter(context.Background(), catchAllPolicies(1, 1), RateLimiterOptions{
+		MaxVisitors:     maxVisitors,
+		IdleTimeout:     time.Millisecond,
+		CleanupInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(rl.Stop)
+
+	r := gin.New()
+	r.Use(rl.Handler())
+	r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				doRequest(r, http.MethodGet, "/test", fmt.Sprintf("10.%d.%d.%d", g, i/256, i%256))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := rl.visitors.len(); n > maxVisitors {
+		t.Fatalf("expected at most %d tracked visitors, got %d", maxVisitors, n)
+	}
+}
+
+func BenchmarkRateLimiter_SingleIP(b *testing.B) {
+	benchmarkRateLimiter(b, func(int) string { return "1.1.1.1" })
+}
+
+func BenchmarkRateLimiter_HighCardinality(b *testing.B) {
+	benchmarkRateLimiter(b, func(i int) string {
+		return fmt.Sprintf("10.%d.%d.%d", (i>>16)&0xff, (i>>8)&0xff, i&0xff)
+	})
+}
+
+func benchmarkRateLimiter(b *testing.B, ipFor func(int) string) {
+	rl, err := NewRateLimiter(context.Background(), catchAllPolicies(1000, 1000), RateLimiterOptions{MaxVisitors: 10000})
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	b.Cleanup(rl.Stop)
+
+	r := gin.New()
+	r.Use(rl.Handler())
+	r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var counter atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			doRequest(r, http.MethodGet, "/test", ipFor(int(counter.Add(1))))
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dimitarkovachev/wedding/internal/logging"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// Reason: the ID is echoed into logs and headers, so only accept a bounded
+// set of characters from clients to rule out log injection
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewRequestLogger creates a Gin middleware that propagates the client's
+// X-Request-ID (or assigns a new one), stores a request-scoped logger carrying
+// it in the request context and writes one access log entry per request.
+// server identifies the engine ("public" or "admin") in the log.
+func NewRequestLogger(server string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+
+		entry := log.WithFields(log.Fields{
+			"request_id": id,
+			"server":     server,
+		})
+		// Reason: lets log entries be joined with the trace started by otelgin
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			entry = entry.WithField("trace_id", sc.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), entry))
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		access := entry.WithFields(log.Fields{
+			"method":     c.Request.Method,
+			"route":      route,
+			"status":     c.Writer.Status(),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"bytes":      size,
+			"client_ip":  c.ClientIP(),
+		})
+
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			access.Error("request completed")
+		case status >= 400:
+			access.Warn("request completed")
+		default:
+			access.Info("request completed")
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/dimitarkovachev/wedding/internal/logging"
+)
+
+func setupRequestLogRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(NewRequestLogger("public"))
+	r.GET("/invites/:id", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("invite viewed")
+		c.String(http.StatusOK, "hello")
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	return r
+}
+
+func accessEntry(t *testing.T, hook *test.Hook) *log.Entry {
+	t.Helper()
+	for _, e := range hook.AllEntries() {
+		if e.Message == "request completed" {
+			return e
+		}
+	}
+	t.Fatal("no access log entry written")
+	return nil
+}
+
+func TestRequestLogger_AssignsRequestID(t *testing.T) {
+	hook := test.NewGlobal()
+	r := setupRequestLogRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/invites/abc", nil)
+	r.ServeHTTP(w, req)
+
+	id := w.Header().Get(RequestIDHeader)
+	if _, err := uuid.Parse(id); err != nil {
+		t.Fatalf("expected generated uuid request id, got %q", id)
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("expected handler and access entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Data["request_id"] != id {
+			t.Fatalf("entry %q missing request id: %v", e.Message, e.Data)
+		}
+	}
+}
+
+func TestRequestLogger_PropagatesRequestID(t *testing.T) {
+	hook := test.NewGlobal()
+	r := setupRequestLogRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/invites/abc", nil)
+	req.Header.Set(RequestIDHeader, "client-id-123")
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); got != "client-id-123" {
+		t.Fatalf("expected propagated request id, got %q", got)
+	}
+	if got := accessEntry(t, hook).Data["request_id"]; got != "client-id-123" {
+		t.Fatalf("expected propagated request id in log, got %v", got)
+	}
+}
+
+func TestRequestLogger_ReplacesInvalidRequestID(t *testing.T) {
+	test.NewGlobal()
+	r := setupRequestLogRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/invites/abc", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	r.ServeHTTP(w, req)
+
+	if _, err := uuid.Parse(w.Header().Get(RequestIDHeader)); err != nil {
+		t.Fatalf("expected invalid id to be replaced, got %q", w.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestRequestLogger_AccessLogFields(t *testing.T) {
+	hook := test.NewGlobal()
+	r := setupRequestLogRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/invites/abc", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+	r.ServeHTTP(w, req)
+
+	e := accessEntry(t, hook)
+	want := log.Fields{
+		"method":    http.MethodGet,
+		"route":     "/invites/:id",
+		"status":    http.StatusOK,
+		"bytes":     5,
+		"client_ip": "1.2.3.4",
+		"server":    "public",
+	}
+	for k, v := range want {
+		if e.Data[k] != v {
+			t.Fatalf("field %s: expected %v, got %v", k, v, e.Data[k])
+		}
+	}
+	if _, ok := e.Data["latency_ms"].(float64); !ok {
+		t.Fatalf("expected latency_ms, got %v", e.Data["latency_ms"])
+	}
+	if e.Level != log.InfoLevel {
+		t.Fatalf("expected info level, got %v", e.Level)
+	}
+}
+
+func TestRequestLogger_ServerErrorLevel(t *testing.T) {
+	hook := test.NewGlobal()
+	r := setupRequestLogRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	if e := accessEntry(t, hook); e.Level != log.ErrorLevel {
+		t.Fatalf("expected error level for 500, got %v", e.Level)
+	}
+}
+
+func TestRequestLogger_UnmatchedRouteLogsPath(t *testing.T) {
+	hook := test.NewGlobal()
+	r := setupRequestLogRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing?q=secret", nil))
+
+	if got := accessEntry(t, hook).Data["route"]; got != "/missing" {
+		t.Fatalf("expected path without query, got %v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/logging"
+)
+
+// ResponseValidationMode selects what happens to responses that violate the spec.
+type ResponseValidationMode string
+
+const (
+	// ResponseValidationOff skips response validation entirely.
+	ResponseValidationOff ResponseValidationMode = "off"
+	// ResponseValidationLog logs violations and sends the response unchanged.
+	ResponseValidationLog ResponseValidationMode = "log"
+	// ResponseValidationFail logs violations and replaces the response with a 500.
+	ResponseValidationFail ResponseValidationMode = "fail"
+)
+
+// ParseResponseValidationMode converts a config value to a ResponseValidationMode.
+func ParseResponseValidationMode(s string) (ResponseValidationMode, error) {
+	switch mode := ResponseValidationMode(s); mode {
+	case ResponseValidationOff, ResponseValidationLog, ResponseValidationFail:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown response validation mode %q", s)
+	}
+}
+
+// NewOpenAPIResponseValidator creates a Gin middleware that validates outgoing
+// responses against the provided OpenAPI 3 spec. Responses are buffered so a
+// violation can still be turned into a 500 in fail mode. Status codes the spec
+// does not declare, and routes outside the spec, are passed through.
+func NewOpenAPIResponseValidator(spec *openapi3.T, mode ResponseValidationMode) (gin.HandlerFunc, error) {
+	if mode == ResponseValidationOff {
+		return func(c *gin.Context) { c.Next() }, nil
+	}
+
+	router, err := newSpecRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return responseValidatorHandler(router, mode), nil
+}
+
+func responseValidatorHandler(router routers.Router, mode ResponseValidationMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffered := &bufferedResponseWriter{ResponseWriter: original}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Request:    c.Request,
+				PathParams: pathParams,
+				Route:      route,
+			},
+			Status:  original.Status(),
+			Header:  original.Header(),
+			Options: &openapi3filter.Options{},
+		}
+		input.SetBodyBytes(buffered.body.Bytes())
+
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), input); err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).WithFields(log.Fields{
+				"path":   c.Request.URL.Path,
+				"status": original.Status(),
+			}).Error("response validation failed")
+
+			if mode == ResponseValidationFail {
+				body, _ := json.Marshal(ErrorResponse{
+					Code:    CodeInternalError,
+					Message: i18n.T(i18n.FromContext(c.Request.Context()), i18n.MsgResponseInvalid),
+				})
+				original.Header().Set("Content-Type", "application/json; charset=utf-8")
+				original.WriteHeader(http.StatusInternalServerError)
+				_, _ = original.Write(body)
+				return
+			}
+		}
+
+		if buffered.body.Len() > 0 {
+			_, _ = original.Write(buffered.body.Bytes())
+		}
+	}
+}
+
+// bufferedResponseWriter holds the response body back until it has been
+// validated. Status and headers are recorded on the wrapped writer, which Gin
+// does not send until the first body write.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// WriteHeaderNow is a no-op so headers are not flushed before validation.
+func (w *bufferedResponseWriter) WriteHeaderNow() {}
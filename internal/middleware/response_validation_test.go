@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+const validInvitePath = "/invites/550e8400-e29b-41d4-a716-446655440000"
+
+func setupResponseValidationRouter(t *testing.T, mode ResponseValidationMode, invite gin.H) *gin.Engine {
+	t.Helper()
+
+	mw, err := NewOpenAPIResponseValidator(loadTestSpec(t), mode)
+	if err != nil {
+		t.Fatalf("failed to create response validator: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(mw)
+	r.GET("/invites/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, invite)
+	})
+	r.GET("/unlisted", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"anything": true})
+	})
+	return r
+}
+
+func validInvite() gin.H {
+	return gin.H{"people": []string{"Иван"}, "additionalCount": 1, "isAccepted": false, "isOpened": false, "language": "bg"}
+}
+
+func invalidInvite() gin.H {
+	inv := validInvite()
+	inv["additional"] = []string{"John Smith"}
+	return inv
+}
+
+func TestResponseValidation_Modes(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       ResponseValidationMode
+		invite     gin.H
+		wantStatus int
+		wantLogged bool
+	}{
+		{"valid response passes", ResponseValidationFail, validInvite(), http.StatusOK, false},
+		{"off ignores violations", ResponseValidationOff, invalidInvite(), http.StatusOK, false},
+		{"log keeps response", ResponseValidationLog, invalidInvite(), http.StatusOK, true},
+		{"fail replaces response", ResponseValidationFail, invalidInvite(), http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := test.NewGlobal()
+			r := setupResponseValidationRouter(t, tt.mode, tt.invite)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, validInvitePath, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if logged := hook.LastEntry() != nil; logged != tt.wantLogged {
+				t.Fatalf("expected logged=%v, got %v", tt.wantLogged, logged)
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.Len() == 0 {
+				t.Fatal("expected response body to be flushed")
+			}
+		})
+	}
+}
+
+func TestResponseValidation_UnlistedRoutePassesThrough(t *testing.T) {
+	r := setupResponseValidationRouter(t, ResponseValidationFail, validInvite())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unlisted", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestParseResponseValidationMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ResponseValidationMode
+		wantErr bool
+	}{
+		{"off", ResponseValidationOff, false},
+		{"log", ResponseValidationLog, false},
+		{"fail", ResponseValidationFail, false},
+		{"", "", true},
+		{"strict", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseResponseValidationMode(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+)
+
+// NewRequestTimeout gives each request's context a deadline d from its
+// start. The stores check it, so a slow request gives up instead of holding
+// the writer lock, and the handlers answer it with 503 and the timeout code.
+// If a handler returns without writing anything after the deadline, the
+// middleware writes that response itself. A d of zero or less disables the
+// deadline.
+func NewRequestTimeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if !c.Writer.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			lang := i18n.FromContext(ctx)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrorResponse{
+				Code:    CodeTimeout,
+				Message: i18n.T(lang, i18n.MsgTimeout),
+			})
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	r := gin.New()
+	r.Use(NewLanguageDetector())
+	r.Use(NewRequestTimeout(20 * time.Millisecond))
+	r.GET("/silent", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	r.GET("/answered", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusTeapot, "done")
+	})
+	r.GET("/deadline", func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		if !ok {
+			t.Error("expected the request context to carry a deadline")
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/silent", nil)
+	req.Header.Set("Accept-Language", "en")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if resp.Code != CodeTimeout || resp.Message != "the request took too long, please try again" {
+		t.Fatalf("expected localized timeout error, got %+v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/answered", nil))
+	if w.Code != http.StatusTeapot || w.Body.String() != "done" {
+		t.Fatalf("expected the handler's own response kept, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/deadline", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+}
+
+func TestRequestTimeout_Disabled(t *testing.T) {
+	r := gin.New()
+	r.Use(NewRequestTimeout(0))
+	r.GET("/", func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			t.Error("expected no deadline when disabled")
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+}
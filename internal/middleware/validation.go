@@ -3,19 +3,45 @@ package middleware
 import (
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/getkin/kin-openapi/routers"
 	"github.com/getkin/kin-openapi/routers/gorillamux"
 	"github.com/gin-gonic/gin"
-	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/logging"
 )
 
+const tracerName = "github.com/dimitarkovachev/wedding/internal/middleware"
+
+// uuidPattern accepts any hex UUID; invite IDs are not required to carry an
+// RFC 4122 version, matching what the generated wrappers parse.
+const uuidPattern = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+func init() {
+	// Reason: kin-openapi does not check "format: uuid" unless it is registered,
+	// which let malformed invite IDs through to the generated wrapper
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewRegexpFormatValidator(uuidPattern))
+}
+
 // NewOpenAPIValidator creates a Gin middleware that validates incoming requests
-// against the provided OpenAPI 3 spec. Invalid requests are rejected with 400.
+// against the provided OpenAPI 3 spec. Invalid requests are rejected with 400
+// and an ErrorResponse listing each offending field.
 func NewOpenAPIValidator(spec *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := newSpecRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return validatorHandler(router), nil
+}
+
+func newSpecRouter(spec *openapi3.T) (routers.Router, error) {
 	// Reason: clear servers so the router matches paths without a server URL prefix
 	spec.Servers = nil
 
@@ -23,16 +49,16 @@ func NewOpenAPIValidator(spec *openapi3.T) (gin.HandlerFunc, error) {
 	if err != nil {
 		return nil, fmt.Errorf("creating openapi router: %w", err)
 	}
-
-	return validatorHandler(router), nil
+	return router, nil
 }
 
 func validatorHandler(router routers.Router) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		route, pathParams, err := router.FindRoute(c.Request)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
-				"message": "route not found in API specification",
+			c.AbortWithStatusJSON(http.StatusNotFound, ErrorResponse{
+				Code:    CodeRouteNotFound,
+				Message: i18n.T(i18n.FromContext(c.Request.Context()), i18n.MsgRouteNotFound),
 			})
 			return
 		}
@@ -44,28 +70,25 @@ func validatorHandler(router routers.Router) gin.HandlerFunc {
 			Options: &openapi3filter.Options{
 				// Reason: skip auth validation since this API has no auth
 				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				// Reason: report every violation so clients can flag each field
+				MultiError: true,
 			},
 		}
 
-		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
-			log.WithError(err).WithField("path", c.Request.URL.Path).Warn("request validation failed")
+		ctx, span := otel.Tracer(tracerName).Start(c.Request.Context(), "openapi.ValidateRequest")
+		span.SetAttributes(attribute.String("http.route", route.Path))
+		err = openapi3filter.ValidateRequest(ctx, input)
+		if err != nil {
+			span.SetStatus(codes.Error, "request validation failed")
+		}
+		span.End()
 
-			msg := sanitizeValidationError(err)
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-				"message": msg,
-			})
+		if err != nil {
+			logging.FromContext(c.Request.Context()).WithError(err).WithField("path", c.Request.URL.Path).Warn("request validation failed")
+			c.AbortWithStatusJSON(http.StatusBadRequest, validationErrorResponse(err, i18n.FromContext(c.Request.Context())))
 			return
 		}
 
 		c.Next()
 	}
 }
-
-func sanitizeValidationError(err error) string {
-	msg := err.Error()
-	// Reason: kin-openapi wraps errors verbosely; trim to the useful part
-	if idx := strings.Index(msg, "Schema:"); idx >= 0 {
-		msg = strings.TrimSpace(msg[idx:])
-	}
-	return msg
-}
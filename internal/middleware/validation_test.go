@@ -10,8 +10,23 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+// recordSpans installs a global tracer provider that records ended spans for
+// the duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return sr
+}
+
 func loadTestSpec(t *testing.T) *openapi3.T {
 	t.Helper()
 	spec, err := openapi3.NewLoader().LoadFromFile("../../docs/api/openapi.yaml")
@@ -51,7 +66,7 @@ func TestValidation_ValidPutRequest(t *testing.T) {
 	r := setupValidationRouter(t)
 
 	body, _ := json.Marshal(map[string]any{
-		"isAccepted":   true,
+		"isAccepted": true,
 		"additional": []string{"Иван Петров"},
 	})
 	w := httptest.NewRecorder()
@@ -68,7 +83,7 @@ func TestValidation_InvalidLatinNames(t *testing.T) {
 	r := setupValidationRouter(t)
 
 	body, _ := json.Marshal(map[string]any{
-		"isAccepted":   true,
+		"isAccepted": true,
 		"additional": []string{"John Doe"},
 	})
 	w := httptest.NewRecorder()
@@ -85,7 +100,7 @@ func TestValidation_TooManyAdditionalItems(t *testing.T) {
 	r := setupValidationRouter(t)
 
 	body, _ := json.Marshal(map[string]any{
-		"isAccepted":   true,
+		"isAccepted": true,
 		"additional": []string{"Иван Петров", "Мария Петрова", "Георги Димитров", "Елена Стоянова", "Петър Стоянов", "Ана Иванова"},
 	})
 	w := httptest.NewRecorder()
@@ -125,3 +140,22 @@ func TestValidation_HealthEndpointPassesThrough(t *testing.T) {
 		t.Fatalf("expected 200 for health, got %d: %s", w.Code, w.Body.String())
 	}
 }
+
+func TestValidation_RecordsSpan(t *testing.T) {
+	sr := recordSpans(t)
+	r := setupValidationRouter(t)
+
+	body, _ := json.Marshal(map[string]any{"additional": []string{"Иван"}})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/invites/550e8400-e29b-41d4-a716-446655440000", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	spans := sr.Ended()
+	if len(spans) != 1 || spans[0].Name() != "openapi.ValidateRequest" {
+		t.Fatalf("expected one validation span, got %v", spans)
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Fatalf("expected error status, got %v", spans[0].Status().Code)
+	}
+}
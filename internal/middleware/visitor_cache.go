@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type visitor struct {
+	key     string
+	limiter *rate.Limiter
+	// Reason: written by request goroutines after the cache lock is released
+	// and read by the cleanup goroutine, so it must be accessed atomically
+	lastSeen atomic.Int64
+}
+
+// visitorCache is a capacity-bounded LRU of rate limiter visitors. Once full,
+// adding a visitor evicts the least recently used one, which caps memory use
+// when an attacker sprays requests from many addresses.
+type visitorCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // front is most recently used
+}
+
+func newVisitorCache(capacity int) *visitorCache {
+	return &visitorCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get returns the limiter stored under key, creating it with newLimiter when
+// absent, and marks the visitor as seen at now.
+func (vc *visitorCache) get(key string, now time.Time, newLimiter func() *rate.Limiter) *rate.Limiter {
+	vc.mu.Lock()
+	var v *visitor
+	if el, ok := vc.items[key]; ok {
+		vc.order.MoveToFront(el)
+		v = el.Value.(*visitor)
+	} else {
+		v = &visitor{key: key, limiter: newLimiter()}
+		vc.items[key] = vc.order.PushFront(v)
+		if vc.order.Len() > vc.capacity {
+			vc.removeElement(vc.order.Back())
+		}
+	}
+	vc.mu.Unlock()
+
+	v.lastSeen.Store(now.UnixNano())
+	return v.limiter
+}
+
+// evictIdle removes visitors last seen before cutoff and returns how many
+// were removed. It walks from the least recently used end and stops at the
+// first visitor that is still active.
+func (vc *visitorCache) evictIdle(cutoff time.Time) int {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	removed := 0
+	for el := vc.order.Back(); el != nil; {
+		v := el.Value.(*visitor)
+		if v.lastSeen.Load() >= cutoff.UnixNano() {
+			break
+		}
+		prev := el.Prev()
+		vc.removeElement(el)
+		removed++
+		el = prev
+	}
+	return removed
+}
+
+func (vc *visitorCache) len() int {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	return vc.order.Len()
+}
+
+func (vc *visitorCache) removeElement(el *list.Element) {
+	vc.order.Remove(el)
+	delete(vc.items, el.Value.(*visitor).key)
+}
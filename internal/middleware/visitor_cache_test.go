@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func newTestLimiter() *rate.Limiter {
+	return rate.NewLimiter(1, 1)
+}
+
+func TestVisitorCache_ReusesLimiter(t *testing.T) {
+	vc := newVisitorCache(10)
+	now := time.Now()
+
+	first := vc.get("a", now, newTestLimiter)
+	second := vc.get("a", now, newTestLimiter)
+	if first != second {
+		t.Fatal("expected the same limiter for the same key")
+	}
+	if vc.len() != 1 {
+		t.Fatalf("expected 1 visitor, got %d", vc.len())
+	}
+}
+
+func TestVisitorCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	vc := newVisitorCache(2)
+	now := time.Now()
+
+	vc.get("a", now, newTestLimiter)
+	vc.get("b", now, newTestLimiter)
+	vc.get("a", now, newTestLimiter) // a becomes most recently used
+	vc.get("c", now, newTestLimiter) // evicts b
+
+	if vc.len() != 2 {
+		t.Fatalf("expected 2 visitors, got %d", vc.len())
+	}
+	if _, ok := vc.items["b"]; ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := vc.items["a"]; !ok {
+		t.Fatal("expected a to be kept")
+	}
+}
+
+func TestVisitorCache_EvictIdle(t *testing.T) {
+	vc := newVisitorCache(10)
+	now := time.Now()
+
+	vc.get("old", now.Add(-time.Hour), newTestLimiter)
+	vc.get("fresh", now, newTestLimiter)
+
+	if removed := vc.evictIdle(now.Add(-time.Minute)); removed != 1 {
+		t.Fatalf("expected 1 removed, got %d", removed)
+	}
+	if _, ok := vc.items["fresh"]; !ok {
+		t.Fatal("expected fresh visitor to be kept")
+	}
+}
+
+func TestVisitorCache_EvictIdleEmpty(t *testing.T) {
+	vc := newVisitorCache(10)
+	if removed := vc.evictIdle(time.Now()); removed != 0 {
+		t.Fatalf("expected 0 removed, got %d", removed)
+	}
+}
@@ -0,0 +1,187 @@
+// Package names holds the rules guest names must follow. The same Rules value
+// validates names in the public API, the admin API and the seed loader, and is
+// written into the OpenAPI specs so the published schema matches what the
+// server enforces.
+package names
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Constraints reported by Check. They match the OpenAPI keywords of the
+// GuestName schema so API field errors read the same either way.
+const (
+	ConstraintMinLength = "minLength"
+	ConstraintMaxLength = "maxLength"
+	ConstraintPattern   = "pattern"
+)
+
+// Rules describes which characters a guest name may contain.
+type Rules struct {
+	// Scripts are Unicode script names whose letters are allowed, e.g. "Cyrillic".
+	Scripts []string
+	// ExtraChars lists further allowed characters such as space, hyphen or apostrophe.
+	ExtraChars string
+	// MaxLength caps the name length in characters; zero means unlimited.
+	MaxLength int
+}
+
+// DefaultRules allows Cyrillic letters, spaces and hyphens, up to 100 characters.
+func DefaultRules() Rules {
+	r, _ := NewRules([]string{"Cyrillic"}, " -", 100)
+	return r
+}
+
+// NewRules builds Rules, rejecting unknown script names.
+func NewRules(scripts []string, extraChars string, maxLength int) (Rules, error) {
+	if len(scripts) == 0 && extraChars == "" {
+		return Rules{}, fmt.Errorf("at least one script or extra character is required")
+	}
+	if maxLength < 0 {
+		return Rules{}, fmt.Errorf("max length must not be negative")
+	}
+
+	r := Rules{ExtraChars: extraChars, MaxLength: maxLength}
+	for _, name := range scripts {
+		name = strings.TrimSpace(name)
+		if _, ok := unicode.Scripts[name]; !ok {
+			return Rules{}, fmt.Errorf("unknown unicode script %q", name)
+		}
+		r.Scripts = append(r.Scripts, name)
+	}
+	return r, nil
+}
+
+// ParseScripts splits a comma separated script list such as "Cyrillic,Latin".
+func ParseScripts(list string) []string {
+	var scripts []string
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scripts = append(scripts, s)
+		}
+	}
+	return scripts
+}
+
+// Check reports the first constraint name violates, or "" when it is valid.
+func (r Rules) Check(name string) string {
+	n := utf8.RuneCountInString(name)
+	if n == 0 {
+		return ConstraintMinLength
+	}
+	if r.MaxLength > 0 && n > r.MaxLength {
+		return ConstraintMaxLength
+	}
+	for _, c := range name {
+		if !r.allowed(c) {
+			return ConstraintPattern
+		}
+	}
+	return ""
+}
+
+// Violation is a name that broke the rules.
+type Violation struct {
+	Index      int
+	Constraint string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("name %d violates %s", v.Index, v.Constraint)
+}
+
+// CheckAll checks every name and returns the violations in order.
+func (r Rules) CheckAll(names []string) []Violation {
+	var out []Violation
+	for i, name := range names {
+		if c := r.Check(name); c != "" {
+			out = append(out, Violation{Index: i, Constraint: c})
+		}
+	}
+	return out
+}
+
+func (r Rules) allowed(c rune) bool {
+	if strings.ContainsRune(r.ExtraChars, c) {
+		return true
+	}
+	for _, s := range r.Scripts {
+		if t, ok := unicode.Scripts[s]; ok && unicode.Is(t, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pattern returns the rules as a regular expression usable both by Go and
+// as an OpenAPI pattern, e.g. `^[\p{Cyrillic} \-]+$`.
+func (r Rules) Pattern() string {
+	var b strings.Builder
+	b.WriteString("^[")
+	for _, s := range r.Scripts {
+		b.WriteString(`\p{` + s + `}`)
+	}
+
+	// Reason: a stable order keeps the published spec identical across restarts
+	for _, c := range r.sortedExtra() {
+		b.WriteString(classEscape(c))
+	}
+	b.WriteString("]+$")
+	return b.String()
+}
+
+// HTMLPattern returns the rules as a value for the HTML pattern attribute,
+// which browsers anchor themselves and compile as a JavaScript regular
+// expression, e.g. `[\p{Script=Cyrillic} \-]+`.
+func (r Rules) HTMLPattern() string {
+	var b strings.Builder
+	b.WriteString("[")
+	for _, s := range r.Scripts {
+		b.WriteString(`\p{Script=` + s + `}`)
+	}
+	for _, c := range r.sortedExtra() {
+		switch {
+		case !unicode.IsPrint(c):
+			fmt.Fprintf(&b, `\u{%x}`, c)
+		case strings.ContainsRune(`\]-[^(){}|/.*+?$`, c):
+			// Reason: newer browsers compile the pattern with the v flag,
+			// which reserves these inside a class; escaping only syntax
+			// characters keeps it valid under the older u flag too
+			b.WriteString(`\` + string(c))
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteString("]+")
+	return b.String()
+}
+
+// sortedExtra returns ExtraChars sorted and without repeats.
+func (r Rules) sortedExtra() []rune {
+	extra := []rune(r.ExtraChars)
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	out := extra[:0]
+	for i, c := range extra {
+		if i == 0 || c != extra[i-1] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// classEscape escapes c for use inside a bracketed character class.
+func classEscape(c rune) string {
+	switch c {
+	case '\\', ']', '[', '^', '-':
+		return `\` + string(c)
+	}
+	if !unicode.IsPrint(c) {
+		return fmt.Sprintf(`\x{%x}`, c)
+	}
+	return regexp.QuoteMeta(string(c))
+}
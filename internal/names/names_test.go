@@ -0,0 +1,132 @@
+package names
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNewRules_Invalid(t *testing.T) {
+	if _, err := NewRules([]string{"Klingon"}, " ", 10); err == nil {
+		t.Fatal("expected error for unknown script")
+	}
+	if _, err := NewRules(nil, "", 10); err == nil {
+		t.Fatal("expected error for empty rules")
+	}
+	if _, err := NewRules([]string{"Latin"}, "", -1); err == nil {
+		t.Fatal("expected error for negative max length")
+	}
+}
+
+func TestParseScripts(t *testing.T) {
+	got := ParseScripts(" Cyrillic, Latin ,,")
+	if len(got) != 2 || got[0] != "Cyrillic" || got[1] != "Latin" {
+		t.Fatalf("expected [Cyrillic Latin], got %q", got)
+	}
+}
+
+func TestRules_Check(t *testing.T) {
+	mixed, err := NewRules([]string{"Cyrillic", "Latin"}, " -'", 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		rules Rules
+		input string
+		want  string
+	}{
+		{"default cyrillic", DefaultRules(), "Иван Петров-Иванов", ""},
+		{"default rejects latin", DefaultRules(), "John Smith", ConstraintPattern},
+		{"default rejects apostrophe", DefaultRules(), "Д'Артанян", ConstraintPattern},
+		{"default rejects digits", DefaultRules(), "Иван123", ConstraintPattern},
+		{"empty", DefaultRules(), "", ConstraintMinLength},
+		{"default max length", DefaultRules(), strings.Repeat("а", 101), ConstraintMaxLength},
+		{"mixed latin", mixed, "John Smith", ""},
+		{"mixed apostrophe", mixed, "O'Brien", ""},
+		{"mixed cyrillic", mixed, "Иван", ""},
+		{"mixed rejects greek", mixed, "Αλέξης", ConstraintPattern},
+		{"mixed max length counts characters", mixed, "Йордан Йорда", ""},
+		{"mixed too long", mixed, "Йордан Йордан", ConstraintMaxLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.Check(tt.input); got != tt.want {
+				t.Fatalf("Check(%q): expected %q, got %q", tt.input, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRules_CheckAll(t *testing.T) {
+	got := DefaultRules().CheckAll([]string{"Иван", "John", "", "Мария"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", got)
+	}
+	if got[0].Index != 1 || got[0].Constraint != ConstraintPattern {
+		t.Fatalf("expected pattern violation at 1, got %+v", got[0])
+	}
+	if got[1].Index != 2 || got[1].Constraint != ConstraintMinLength {
+		t.Fatalf("expected minLength violation at 2, got %+v", got[1])
+	}
+}
+
+func TestRules_Pattern(t *testing.T) {
+	tests := []struct {
+		scripts []string
+		extra   string
+		want    string
+	}{
+		{[]string{"Cyrillic"}, " -", `^[\p{Cyrillic} \-]+$`},
+		{[]string{"Cyrillic", "Latin"}, "-' ", `^[\p{Cyrillic}\p{Latin} '\-]+$`},
+		{[]string{"Latin"}, `]\^.`, `^[\p{Latin}\.\\\]\^]+$`},
+	}
+	for _, tt := range tests {
+		r, err := NewRules(tt.scripts, tt.extra, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := r.Pattern(); got != tt.want {
+			t.Fatalf("expected pattern %s, got %s", tt.want, got)
+		}
+	}
+}
+
+// TestRules_PatternAgreesWithCheck guards against the published pattern and
+// the Go validator drifting apart.
+func TestRules_PatternAgreesWithCheck(t *testing.T) {
+	r, err := NewRules([]string{"Cyrillic", "Latin"}, ` -'.\]^`, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	re := regexp.MustCompile(r.Pattern())
+
+	inputs := []string{"Иван", "John Smith", "O'Brien", "J. R. R.", `a\b`, "x]y", "^", "Иван123", "a_b", "Ζ", "tab\tname"}
+	for _, in := range inputs {
+		if re.MatchString(in) != (r.Check(in) == "") {
+			t.Fatalf("pattern and Check disagree on %q", in)
+		}
+	}
+}
+
+func TestRules_HTMLPattern(t *testing.T) {
+	mixed, err := NewRules([]string{"Cyrillic", "Latin"}, "- '.-", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		rules Rules
+		want  string
+	}{
+		{"default", DefaultRules(), `[\p{Script=Cyrillic} \-]+`},
+		{"mixed", mixed, `[\p{Script=Cyrillic}\p{Script=Latin} '\-\.]+`},
+	}
+	for _, tt := range tests {
+		if got := tt.rules.HTMLPattern(); got != tt.want {
+			t.Fatalf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package names
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize cleans up a submitted name: it applies Unicode NFC, trims and
+// collapses whitespace, and title-cases words written in Cyrillic. Words in
+// other scripts keep their casing so names like "McDonald" survive.
+func Normalize(name string) string {
+	name = norm.NFC.String(name)
+	name = strings.Join(strings.Fields(name), " ")
+
+	var b strings.Builder
+	b.Grow(len(name))
+	word := make([]rune, 0, 16)
+	flush := func() {
+		if containsCyrillic(word) {
+			titleCase(word)
+		}
+		b.WriteString(string(word))
+		word = word[:0]
+	}
+	for _, c := range name {
+		// Reason: hyphenated surnames ("петрова-иванова") capitalise each part
+		if c == ' ' || c == '-' {
+			flush()
+			b.WriteRune(c)
+			continue
+		}
+		word = append(word, c)
+	}
+	flush()
+	return b.String()
+}
+
+// Key returns the form names are compared by: normalized and lower-cased.
+func Key(name string) string {
+	return strings.ToLower(Normalize(name))
+}
+
+func containsCyrillic(word []rune) bool {
+	for _, c := range word {
+		if unicode.Is(unicode.Cyrillic, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func titleCase(word []rune) {
+	for i, c := range word {
+		if i == 0 {
+			word[i] = unicode.ToUpper(c)
+		} else {
+			word[i] = unicode.ToLower(c)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package names
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Иван Петров", "Иван Петров"},
+		{"  иван   петров ", "Иван Петров"},
+		{"ИВАН\tПЕТРОВ", "Иван Петров"},
+		{"мария петрова-иванова", "Мария Петрова-Иванова"},
+		{"John McDonald", "John McDonald"},
+		{"john  smith", "john smith"},
+		// decomposed "й" (и + combining breve) is composed by NFC
+		{"\u0438\u0306ордан", "Йордан"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Fatalf("Normalize(%q): expected %q, got %q", tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key(" ИВАН  петров") != Key("Иван Петров") {
+		t.Fatal("expected keys to match regardless of case and spacing")
+	}
+	if Key("John Smith") != Key("john smith") {
+		t.Fatal("expected Latin keys to ignore case")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Иван Петров", "иван  петров", 1},
+		{"Иван Петров", "Петров Иван", 1},
+		{"Иван Петров", "Иван Петрова", 1 - 1.0/12},
+		{"", "", 1},
+		{"абв", "где", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("Similarity(%q, %q): expected %.3f, got %.3f", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"Мария", "Марийка", 3},
+	}
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Fatalf("levenshtein(%q, %q): expected %d, got %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
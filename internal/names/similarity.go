@@ -0,0 +1,61 @@
+package names
+
+import (
+	"sort"
+	"strings"
+)
+
+// Similarity scores how alike two names are, from 0 (nothing in common) to 1
+// (identical after normalization). It is the Levenshtein ratio of the
+// comparison keys, also tried with the words sorted so "Петров Иван" and
+// "Иван Петров" match.
+func Similarity(a, b string) float64 {
+	return KeySimilarity(Key(a), Key(b))
+}
+
+// KeySimilarity is Similarity for names already converted with Key, which
+// saves normalizing the same name repeatedly when comparing many pairs.
+func KeySimilarity(ka, kb string) float64 {
+	score := levenshteinRatio(ka, kb)
+	if sorted := levenshteinRatio(sortWords(ka), sortWords(kb)); sorted > score {
+		score = sorted
+	}
+	return score
+}
+
+func sortWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b using two rows of
+// the dynamic programming table.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,32 @@
+package names
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SchemaName is the component schema in both specs describing a guest name.
+const SchemaName = "GuestName"
+
+// ApplyToSpec rewrites the GuestName schema of spec to match r, so request
+// and response validation and the published spec follow the active rules.
+func (r Rules) ApplyToSpec(spec *openapi3.T) error {
+	if spec.Components == nil {
+		return fmt.Errorf("spec has no %s schema", SchemaName)
+	}
+	ref, ok := spec.Components.Schemas[SchemaName]
+	if !ok || ref.Value == nil {
+		return fmt.Errorf("spec has no %s schema", SchemaName)
+	}
+
+	schema := ref.Value
+	schema.Pattern = r.Pattern()
+	schema.MinLength = 1
+	schema.MaxLength = nil
+	if r.MaxLength > 0 {
+		maxLength := uint64(r.MaxLength)
+		schema.MaxLength = &maxLength
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package names
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func loadSpec(t *testing.T, path string) *openapi3.T {
+	t.Helper()
+	spec, err := openapi3.NewLoader().LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", path, err)
+	}
+	return spec
+}
+
+// TestDefaultRules_MatchSpecs keeps the checked-in specs in sync with DefaultRules.
+func TestDefaultRules_MatchSpecs(t *testing.T) {
+	for _, path := range []string{"../../docs/api/openapi.yaml", "../../docs/api/admin-openapi.yaml"} {
+		schema := loadSpec(t, path).Components.Schemas[SchemaName].Value
+		if schema.Pattern != DefaultRules().Pattern() {
+			t.Fatalf("%s: expected pattern %s, got %s", path, DefaultRules().Pattern(), schema.Pattern)
+		}
+		if schema.MaxLength == nil || *schema.MaxLength != uint64(DefaultRules().MaxLength) {
+			t.Fatalf("%s: expected maxLength %d, got %v", path, DefaultRules().MaxLength, schema.MaxLength)
+		}
+	}
+}
+
+func TestRules_ApplyToSpec(t *testing.T) {
+	spec := loadSpec(t, "../../docs/api/openapi.yaml")
+
+	r, err := NewRules([]string{"Cyrillic", "Latin"}, " -'", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.ApplyToSpec(spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	schema := spec.Components.Schemas[SchemaName].Value
+	if schema.Pattern != r.Pattern() {
+		t.Fatalf("expected pattern %s, got %s", r.Pattern(), schema.Pattern)
+	}
+	if schema.MaxLength != nil {
+		t.Fatalf("expected no maxLength, got %d", *schema.MaxLength)
+	}
+	// Reason: the items of InviteUpdate.additional are a $ref, so they must see the patch
+	items := spec.Components.Schemas["InviteUpdate"].Value.Properties["additional"].Value.Items.Value
+	if err := items.VisitJSON("O'Brien"); err != nil {
+		t.Fatalf("expected O'Brien to be accepted, got %v", err)
+	}
+}
+
+func TestRules_ApplyToSpec_MissingSchema(t *testing.T) {
+	if err := DefaultRules().ApplyToSpec(&openapi3.T{}); err == nil {
+		t.Fatal("expected error for spec without GuestName")
+	}
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dimitarkovachev/wedding/internal/config"
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+)
+
+// FromConfig starts a Notifier emailing RSVPs read from s as configured by
+// the SMTP_ and NOTIFY_ variables in cfg.
+func FromConfig(ctx context.Context, cfg *config.Config, s InviteReader) (*Notifier, error) {
+	opts, err := optionsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	from := cfg.SMTPFrom
+	if from == "" {
+		from = cfg.SMTPUsername
+	}
+	mailer := NewMailer(SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     from,
+		StartTLS: cfg.SMTPStartTLS,
+		Timeout:  cfg.SMTPTimeout,
+	})
+	n, err := New(ctx, s, mailer, opts)
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"smtp_host": cfg.SMTPHost, "instant": opts.Instant, "digest": cfg.NotifyDigestTime, "timezone": opts.Location.String(),
+	}).Info("email notifications enabled")
+	return n, nil
+}
+
+// optionsFromConfig parses the NOTIFY_ variables in cfg.
+func optionsFromConfig(cfg *config.Config) (Options, error) {
+	var to []string
+	for _, addr := range strings.Split(cfg.NotifyEmails, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return Options{}, errors.New("NOTIFY_EMAILS is required with SMTP_HOST")
+	}
+	lang, ok := i18n.Parse(cfg.NotifyLanguage)
+	if !ok {
+		return Options{}, fmt.Errorf("invalid NOTIFY_LANGUAGE %q, expected bg or en", cfg.NotifyLanguage)
+	}
+	loc, err := time.LoadLocation(cfg.NotifyTimezone)
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid NOTIFY_TIMEZONE: %w", err)
+	}
+	opts := Options{To: to, Language: lang, Location: loc, Instant: cfg.NotifyInstant}
+	if cfg.NotifyDigestTime != "off" {
+		if opts.DigestAt, err = ParseDigestTime(cfg.NotifyDigestTime); err != nil {
+			return Options{}, fmt.Errorf("invalid NOTIFY_DIGEST_TIME: %w", err)
+		}
+		opts.Digest = true
+	}
+	return opts, nil
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/config"
+)
+
+func TestOptionsFromConfig(t *testing.T) {
+	cfg := &config.Config{
+		NotifyEmails:     " bride@example.com, ,groom@example.com ",
+		NotifyLanguage:   "en",
+		NotifyInstant:    true,
+		NotifyDigestTime: "07:30",
+		NotifyTimezone:   "Europe/Sofia",
+	}
+	opts, err := optionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(opts.To, []string{"bride@example.com", "groom@example.com"}) {
+		t.Fatalf("expected both recipients, got %q", opts.To)
+	}
+	if opts.Language != language.English || opts.Location.String() != "Europe/Sofia" || !opts.Instant {
+		t.Fatalf("expected English, Sofia time and instant emails, got %+v", opts)
+	}
+	if !opts.Digest || opts.DigestAt != 7*time.Hour+30*time.Minute {
+		t.Fatalf("expected a digest at 07:30, got %v at %s", opts.Digest, opts.DigestAt)
+	}
+
+	cfg.NotifyDigestTime = "off"
+	if opts, err := optionsFromConfig(cfg); err != nil || opts.Digest {
+		t.Fatalf("expected the digest turned off, got %v, %v", opts.Digest, err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*config.Config)
+		want   string
+	}{
+		{"no recipients", func(c *config.Config) { c.NotifyEmails = " , " }, "NOTIFY_EMAILS"},
+		{"language", func(c *config.Config) { c.NotifyLanguage = "de" }, "NOTIFY_LANGUAGE"},
+		{"time zone", func(c *config.Config) { c.NotifyTimezone = "Mars/Olympus" }, "NOTIFY_TIMEZONE"},
+		{"digest time", func(c *config.Config) { c.NotifyDigestTime = "8am" }, "NOTIFY_DIGEST_TIME"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad := *cfg
+			tt.change(&bad)
+			if _, err := optionsFromConfig(&bad); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error naming %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/events"
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// InviteReader reads every invite, for the totals and the digest.
+type InviteReader interface {
+	GetAllInvites(ctx context.Context) (map[string]store.InviteRecord, error)
+}
+
+// Options configures a Notifier.
+type Options struct {
+	// To is who the emails are sent to.
+	To []string
+	// Language picks the templates (default i18n.Default).
+	Language language.Tag
+	// Location is the time zone of the digest time and of the times shown
+	// in emails (default time.Local).
+	Location *time.Location
+	// Instant emails every acceptance and decline as it is saved.
+	Instant bool
+	// Digest sends a summary once a day at DigestAt after midnight.
+	Digest   bool
+	DigestAt time.Duration
+	// QueueSize is how many instant emails can wait to be sent before new
+	// ones are dropped (default 64).
+	QueueSize int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Language == language.Und {
+		o.Language = i18n.Default
+	}
+	if o.Location == nil {
+		o.Location = time.Local
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 64
+	}
+	return o
+}
+
+// change is the latest RSVP change to an invite since the last digest.
+type change struct {
+	at time.Time
+	// accepted is set when one of the changes was the invite being accepted.
+	accepted bool
+}
+
+// Notifier emails RSVPs to the couple. It is an events.Publisher: instant
+// emails are queued and sent by a background goroutine, which also sends
+// the daily digest.
+type Notifier struct {
+	store  InviteReader
+	sender Sender
+	opts   Options
+	tmpl   *templates
+	now    func() time.Time
+
+	queue chan events.Event
+
+	mu      sync.Mutex
+	changed map[string]change
+	since   time.Time
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+var _ events.Publisher = (*Notifier)(nil)
+
+// New starts a Notifier sending through sender until ctx is cancelled or
+// Stop is called. The first digest covers the day before it.
+func New(ctx context.Context, s InviteReader, sender Sender, opts Options) (*Notifier, error) {
+	n, err := newNotifier(s, sender, opts)
+	if err != nil {
+		return nil, err
+	}
+	go n.run(ctx)
+	return n, nil
+}
+
+// newNotifier returns a Notifier that has not started sending.
+func newNotifier(s InviteReader, sender Sender, opts Options) (*Notifier, error) {
+	if len(opts.To) == 0 {
+		return nil, errors.New("creating notifier: no recipients")
+	}
+	opts = opts.withDefaults()
+	tmpl, err := loadTemplates(opts.Language, opts.Location)
+	if err != nil {
+		return nil, fmt.Errorf("creating notifier: %w", err)
+	}
+
+	n := &Notifier{
+		store:   s,
+		sender:  sender,
+		opts:    opts,
+		tmpl:    tmpl,
+		now:     func() time.Time { return time.Now().UTC() },
+		queue:   make(chan events.Event, opts.QueueSize),
+		changed: make(map[string]change),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	n.since = n.now().Add(-24 * time.Hour)
+	return n, nil
+}
+
+// Stop sends the instant emails still queued, ends the background
+// goroutine and waits for it to exit. It is safe to call more than once.
+func (n *Notifier) Stop() {
+	n.stopOnce.Do(func() { close(n.stop) })
+	<-n.done
+}
+
+// Publish notes RSVP changes for the digest and queues instant emails for
+// acceptances and declines. It never blocks on sending; when the queue is
+// full the email is dropped and a warning logged.
+func (n *Notifier) Publish(_ context.Context, evs ...events.Event) error {
+	for _, e := range evs {
+		if e.Type != events.InviteAccepted && e.Type != events.InviteDeclined && e.Type != events.InviteUpdated {
+			continue
+		}
+		if n.opts.Digest {
+			n.mu.Lock()
+			c := n.changed[e.Data.InviteID]
+			c.at = e.CreatedAt
+			c.accepted = c.accepted || e.Type == events.InviteAccepted
+			n.changed[e.Data.InviteID] = c
+			n.mu.Unlock()
+		}
+
+		if !n.opts.Instant || e.Type == events.InviteUpdated || e.Data.Invite == nil {
+			continue
+		}
+		select {
+		case n.queue <- e:
+		default:
+			log.WithFields(log.Fields{"event": e.Type, "invite_id": e.Data.InviteID}).
+				Warn("notification queue full, RSVP email dropped")
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) run(ctx context.Context) {
+	defer close(n.done)
+	// Reason: sends already started are finished even when ctx ends, so a
+	// shutdown never cuts an SMTP session short
+	work := context.WithoutCancel(ctx)
+
+	// Reason: a nil channel never fires, which leaves the digest out of the
+	// select below when it is turned off
+	var digest <-chan time.Time
+	var timer *time.Timer
+	if n.opts.Digest {
+		timer = time.NewTimer(n.untilDigest())
+		defer timer.Stop()
+		digest = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.drain(work)
+			return
+		case <-n.stop:
+			n.drain(work)
+			return
+		case e := <-n.queue:
+			n.sendRSVP(work, e)
+		case <-digest:
+			if err := n.sendDigest(work); err != nil {
+				log.WithError(err).Error("failed to send RSVP digest")
+			}
+			timer.Reset(n.untilDigest())
+		}
+	}
+}
+
+// drain sends the instant emails still queued.
+func (n *Notifier) drain(ctx context.Context) {
+	for {
+		select {
+		case e := <-n.queue:
+			n.sendRSVP(ctx, e)
+		default:
+			return
+		}
+	}
+}
+
+// sendRSVP emails the acceptance or decline in e with the current totals.
+func (n *Notifier) sendRSVP(ctx context.Context, e events.Event) {
+	logger := log.WithFields(log.Fields{"event": e.Type, "invite_id": e.Data.InviteID})
+	invites, err := n.store.GetAllInvites(ctx)
+	if err != nil {
+		logger.WithError(err).Error("failed to read invites for RSVP email")
+		return
+	}
+	subject, body, err := n.tmpl.rsvp(newReply(e.Data.InviteID, *e.Data.Invite, e.CreatedAt), countTotals(invites))
+	if err != nil {
+		logger.WithError(err).Error("failed to render RSVP email")
+		return
+	}
+	if err := n.sender.Send(ctx, Message{To: n.opts.To, Subject: subject, Body: body}); err != nil {
+		logger.WithError(err).Error("failed to send RSVP email")
+		return
+	}
+	logger.Info("RSVP email sent")
+}
+
+// sendDigest emails the replies since the last digest. A digest with
+// nothing new is skipped; one that fails to send is retried with the next,
+// which then also covers its period.
+func (n *Notifier) sendDigest(ctx context.Context) error {
+	until := n.now()
+	n.mu.Lock()
+	since := n.since
+	changed := maps.Clone(n.changed)
+	n.mu.Unlock()
+
+	invites, err := n.store.GetAllInvites(ctx)
+	if err != nil {
+		return fmt.Errorf("reading invites: %w", err)
+	}
+	d := buildDigest(invites, changed, since, until)
+	if len(d.Accepted) > 0 || len(d.Changes) > 0 {
+		subject, body, err := n.tmpl.digest(d)
+		if err != nil {
+			return err
+		}
+		if err := n.sender.Send(ctx, Message{To: n.opts.To, Subject: subject, Body: body}); err != nil {
+			return fmt.Errorf("sending digest: %w", err)
+		}
+	}
+	log.WithFields(log.Fields{"accepted": len(d.Accepted), "changes": len(d.Changes)}).Info("RSVP digest done")
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.since = until
+	for id, c := range n.changed {
+		if !c.at.After(until) {
+			delete(n.changed, id)
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) untilDigest() time.Duration {
+	now := n.now()
+	return nextDigest(now, n.opts.DigestAt, n.opts.Location).Sub(now)
+}
+
+// buildDigest summarizes the replies between since and until. Invites with
+// a change noted in changed are reported in their current state; invites
+// accepted in the period without one, such as before a restart, are found
+// by their acceptance time.
+func buildDigest(invites map[string]store.InviteRecord, changed map[string]change, since, until time.Time) Digest {
+	d := Digest{Since: since, Until: until, Totals: countTotals(invites)}
+	for id, rec := range invites {
+		c, ok := changed[id]
+		ok = ok && !c.at.After(until)
+		switch {
+		case ok && c.accepted && rec.Accepted:
+			d.Accepted = append(d.Accepted, newReply(id, rec, c.at))
+		case ok:
+			d.Changes = append(d.Changes, newReply(id, rec, c.at))
+		case rec.Accepted && rec.AcceptedAt != nil && rec.AcceptedAt.After(since) && !rec.AcceptedAt.After(until):
+			d.Accepted = append(d.Accepted, newReply(id, rec, *rec.AcceptedAt))
+		}
+	}
+	byTime := func(a, b Reply) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		return strings.Compare(a.InviteID, b.InviteID)
+	}
+	slices.SortFunc(d.Accepted, byTime)
+	slices.SortFunc(d.Changes, byTime)
+	return d
+}
+
+// ParseDigestTime parses a time of day such as "08:00" into the duration
+// after midnight.
+func ParseDigestTime(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("parsing digest time %q, expected HH:MM: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// nextDigest returns the next time after now when the clock in loc shows
+// at past midnight, keeping that wall clock time across daylight saving
+// changes.
+func nextDigest(now time.Time, at time.Duration, loc *time.Location) time.Time {
+	local := now.In(loc)
+	y, m, d := local.Date()
+	hour, minute := int(at/time.Hour), int(at%time.Hour/time.Minute)
+	next := time.Date(y, m, d, hour, minute, 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(y, m, d+1, hour, minute, 0, 0, loc)
+	}
+	return next
+}
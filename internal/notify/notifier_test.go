@@ -0,0 +1,269 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/events"
+	"github.com/dimitarkovachev/wedding/internal/notify/smtptest"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func setupNotifier(t *testing.T, opts Options) (store.Store, *Notifier, *smtptest.Server) {
+	t.Helper()
+	srv := smtptest.NewServer()
+	t.Cleanup(srv.Close)
+
+	s := store.NewMemoryStore()
+	err := s.Seed(context.Background(), map[string]store.InviteRecord{
+		"aaa-001": {People: []string{"Иван Петров", "Мария Петрова"}, AdditionalCount: 1},
+		"aaa-002": {People: []string{"Георги Иванов"}},
+		"aaa-003": {People: []string{"Елена Димова"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+
+	opts.To = []string{"couple@example.com"}
+	opts.Location = time.UTC
+	n, err := New(context.Background(), s, NewMailer(SMTPConfig{Host: srv.Host, Port: srv.Port, From: "wedding@example.com"}), opts)
+	if err != nil {
+		t.Fatalf("failed to create notifier: %v", err)
+	}
+	t.Cleanup(n.Stop)
+	return events.WithEvents(s, nil, n), n, srv
+}
+
+// waitForMessages waits until srv has accepted n messages.
+func waitForMessages(t *testing.T, srv *smtptest.Server, n int) []smtptest.Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		msgs := srv.Messages()
+		if len(msgs) >= n {
+			return msgs
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d emails, got %d", n, len(msgs))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNotifier_Instant(t *testing.T) {
+	s, n, srv := setupNotifier(t, Options{Instant: true})
+	ctx := context.Background()
+
+	if _, err := s.UpdateInvite(ctx, "aaa-001", true, []string{"Петър Колев"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Reason: the totals are read when the email is sent, so it goes out
+	// before the next change
+	waitForMessages(t, srv, 1)
+	// Changes other than an answer are left to the digest
+	if _, err := s.EditInvite(ctx, "aaa-002", func(r *store.InviteRecord) error {
+		r.Language = "en"
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.EditInvite(ctx, "aaa-003", func(r *store.InviteRecord) error {
+		r.Declined = true
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n.Stop()
+
+	msgs := srv.Messages()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 emails, got %d", len(msgs))
+	}
+	accepted := msgs[0]
+	if accepted.Subject != "Потвърждение: Иван Петров, Мария Петрова" || accepted.To[0] != "couple@example.com" {
+		t.Fatalf("expected the acceptance email, got %q to %v", accepted.Subject, accepted.To)
+	}
+	for _, want := range []string{
+		"Иван Петров, Мария Петрова потвърдиха присъствие на сватбата.\n",
+		"Допълнителни гости: Петър Колев\n",
+		"Покана: aaa-001\n",
+		"Общо: 3 гости в 1 потвърдени покани, 0 отказа и 2 покани без отговор от 3.\n",
+	} {
+		if !strings.Contains(accepted.Body, want) {
+			t.Fatalf("expected %q in body, got:\n%s", want, accepted.Body)
+		}
+	}
+	if got := msgs[1]; got.Subject != "Отказ: Елена Димова" || !strings.Contains(got.Body, "няма да присъстват") {
+		t.Fatalf("expected the decline email, got %q:\n%s", got.Subject, got.Body)
+	}
+}
+
+func TestNotifier_InstantOff(t *testing.T) {
+	s, n, srv := setupNotifier(t, Options{Language: language.English})
+	if _, err := s.UpdateInvite(context.Background(), "aaa-001", true, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n.Stop()
+	if got := srv.Messages(); len(got) != 0 {
+		t.Fatalf("expected no instant emails, got %d", len(got))
+	}
+}
+
+func TestNotifier_Digest(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	day := time.Date(2026, 5, 2, 8, 0, 0, 0, time.UTC)
+	yesterday := day.Add(-20 * time.Hour)
+	lastWeek := day.AddDate(0, 0, -7)
+	s := store.NewMemoryStore()
+	err := s.Seed(ctx, map[string]store.InviteRecord{
+		// Accepted before a restart, so only its acceptance time is known
+		"aaa-001": {People: []string{"Ivan Petrov"}, AdditionalCount: 1, Additional: []string{"Petar Kolev"}, Accepted: true, AcceptedAt: &yesterday},
+		"aaa-002": {People: []string{"Georgi Ivanov"}},
+		"aaa-003": {People: []string{"Elena Dimova"}},
+		"aaa-004": {People: []string{"Old Friend"}, Accepted: true, AcceptedAt: &lastWeek},
+		"aaa-005": {People: []string{"Still Waiting"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+
+	n, err := newNotifier(s, NewMailer(SMTPConfig{Host: srv.Host, Port: srv.Port, From: "wedding@example.com"}), Options{
+		To:       []string{"couple@example.com"},
+		Language: language.English,
+		Location: time.UTC,
+		Digest:   true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create notifier: %v", err)
+	}
+	n.now = func() time.Time { return day }
+	n.since = day.Add(-24 * time.Hour)
+
+	accepted := events.New(events.InviteAccepted, "aaa-002", day.Add(-3*time.Hour))
+	declined := events.New(events.InviteDeclined, "aaa-003", day.Add(-2*time.Hour))
+	if _, err := s.UpdateInvite(ctx, "aaa-002", true, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.EditInvite(ctx, "aaa-003", func(r *store.InviteRecord) error {
+		r.Declined = true
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := n.Publish(ctx, accepted, declined); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := n.sendDigest(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected the digest, got %d emails", len(msgs))
+	}
+	if want := "RSVP digest: 2 new acceptances, 1 changes"; msgs[0].Subject != want {
+		t.Fatalf("expected subject %q, got %q", want, msgs[0].Subject)
+	}
+	want := `Replies from 01.05.2026 08:00 to 02.05.2026 08:00.
+
+New acceptances:
+- Ivan Petrov (+ Petar Kolev), 01.05.2026 12:00
+- Georgi Ivanov, 02.05.2026 05:00
+
+Changes:
+- Elena Dimova: not coming, 02.05.2026 06:00
+
+In total: 4 guests on 3 accepted invites, 1 declined and 1 of 5 invites without an answer.
+`
+	if msgs[0].Body != want {
+		t.Fatalf("expected body:\n%s\ngot:\n%s", want, msgs[0].Body)
+	}
+
+	// Nothing new since, so the next digest is skipped
+	n.now = func() time.Time { return day.Add(24 * time.Hour) }
+	if err := n.sendDigest(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(srv.Messages()); got != 1 {
+		t.Fatalf("expected an empty digest skipped, got %d emails", got)
+	}
+}
+
+func TestNotifier_DigestRetried(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+	srv.Reject = true
+	ctx := context.Background()
+
+	s := store.NewMemoryStore()
+	if err := s.Seed(ctx, map[string]store.InviteRecord{"aaa-001": {People: []string{"Иван Петров"}}}); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	n, err := newNotifier(s, NewMailer(SMTPConfig{Host: srv.Host, Port: srv.Port, From: "wedding@example.com"}), Options{
+		To:     []string{"couple@example.com"},
+		Digest: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create notifier: %v", err)
+	}
+	if err := n.Publish(ctx, events.New(events.InviteUpdated, "aaa-001", n.now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := n.sendDigest(ctx); err == nil {
+		t.Fatalf("expected the rejected digest to fail")
+	}
+	srv.Reject = false
+	if err := n.sendDigest(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msgs := srv.Messages()
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Body, "Иван Петров: без отговор") {
+		t.Fatalf("expected the change in the next digest, got %+v", msgs)
+	}
+}
+
+func TestNextDigest(t *testing.T) {
+	sofia, err := time.LoadLocation("Europe/Sofia")
+	if err != nil {
+		t.Fatalf("failed to load zone: %v", err)
+	}
+	at := 8 * time.Hour
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"later today", time.Date(2026, 5, 1, 6, 0, 0, 0, sofia), time.Date(2026, 5, 1, 8, 0, 0, 0, sofia)},
+		{"tomorrow", time.Date(2026, 5, 1, 9, 0, 0, 0, sofia), time.Date(2026, 5, 2, 8, 0, 0, 0, sofia)},
+		{"exactly now", time.Date(2026, 5, 1, 8, 0, 0, 0, sofia), time.Date(2026, 5, 2, 8, 0, 0, 0, sofia)},
+		{"from UTC", time.Date(2026, 5, 1, 4, 30, 0, 0, time.UTC), time.Date(2026, 5, 1, 8, 0, 0, 0, sofia)},
+		{"daylight saving starts", time.Date(2026, 3, 28, 9, 0, 0, 0, sofia), time.Date(2026, 3, 29, 8, 0, 0, 0, sofia)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextDigest(tt.now, at, sofia); !got.Equal(tt.want) {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseDigestTime(t *testing.T) {
+	if got, err := ParseDigestTime("07:30"); err != nil || got != 7*time.Hour+30*time.Minute {
+		t.Fatalf("expected 7h30m, got %s, %v", got, err)
+	}
+	for _, s := range []string{"7", "25:00", "08:00:00", ""} {
+		if _, err := ParseDigestTime(s); err == nil {
+			t.Fatalf("expected %q rejected", s)
+		}
+	}
+}
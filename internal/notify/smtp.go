@@ -0,0 +1,206 @@
+// Package notify emails the couple about RSVPs: an instant message when an
+// invite is accepted or declined, and a daily digest of new acceptances and
+// other changes, both rendered from Bulgarian or English templates and sent
+// through an SMTP server.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SMTPConfig is how a Mailer reaches its SMTP server.
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password log in with AUTH PLAIN; an empty Username skips
+	// authentication.
+	Username string
+	Password string
+	// From is the sender address, optionally with a name, such as
+	// "Wedding <wedding@example.com>".
+	From string
+	// StartTLS upgrades the connection before authenticating and fails when
+	// the server does not offer it.
+	StartTLS bool
+	// Timeout bounds a whole send, from dialling to QUIT (default 10s).
+	Timeout time.Duration
+	// TLSConfig is used for STARTTLS (default verifies the certificate
+	// against the system roots for Host).
+	TLSConfig *tls.Config
+}
+
+// Message is one plain text email.
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Sender sends emails.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Mailer sends each message over a new SMTP connection.
+type Mailer struct {
+	cfg SMTPConfig
+	now func() time.Time
+}
+
+var _ Sender = (*Mailer)(nil)
+
+// NewMailer returns a Mailer for cfg. Nothing is dialled until Send.
+func NewMailer(cfg SMTPConfig) *Mailer {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Mailer{cfg: cfg, now: time.Now}
+}
+
+// Send delivers msg to every recipient, or returns why the server did not
+// take it.
+func (m *Mailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("sending email: no recipients")
+	}
+	from, err := mail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("parsing sender address: %w", err)
+	}
+	data, err := m.format(from, msg)
+	if err != nil {
+		return fmt.Errorf("formatting email: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to SMTP server: %w", err)
+	}
+	// Reason: net/smtp has no context support, so the deadline on the
+	// connection is what stops a stalled server
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return fmt.Errorf("connecting to SMTP server: %w", err)
+	}
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("greeting SMTP server: %w", err)
+	}
+	defer c.Close()
+	if err := m.send(c, from.Address, msg.To, data); err != nil {
+		return err
+	}
+	if err := c.Quit(); err != nil {
+		return fmt.Errorf("closing SMTP session: %w", err)
+	}
+	return nil
+}
+
+func (m *Mailer) send(c *smtp.Client, from string, to []string, data []byte) error {
+	if m.cfg.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("starting TLS: SMTP server does not support STARTTLS")
+		}
+		tlsConfig := &tls.Config{ServerName: m.cfg.Host}
+		if m.cfg.TLSConfig != nil {
+			tlsConfig = m.cfg.TLSConfig.Clone()
+			if tlsConfig.ServerName == "" {
+				tlsConfig.ServerName = m.cfg.Host
+			}
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starting TLS: %w", err)
+		}
+	}
+	if m.cfg.Username != "" {
+		// Reason: PlainAuth itself refuses to send the password over an
+		// unencrypted connection to anything but localhost
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("authenticating to SMTP server: %w", err)
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("setting sender: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("adding recipient %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("starting message: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+	return nil
+}
+
+// format renders msg as a UTF-8 plain text email, quoted-printable encoded
+// so Cyrillic survives servers without 8BITMIME.
+func (m *Mailer) format(from *mail.Address, msg Message) ([]byte, error) {
+	for _, v := range append([]string{msg.Subject}, msg.To...) {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, errors.New("header contains a line break")
+		}
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", m.now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+uuid.NewString()+"@"+messageIDDomain(from.Address)+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// messageIDDomain is the domain of the sender address, used to make
+// Message-IDs globally unique.
+func messageIDDomain(from string) string {
+	if i := strings.LastIndexByte(from, '@'); i >= 0 {
+		return from[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/notify/smtptest"
+)
+
+func testMailer(srv *smtptest.Server, startTLS bool) *Mailer {
+	return NewMailer(SMTPConfig{
+		Host:      srv.Host,
+		Port:      srv.Port,
+		Username:  "wedding",
+		Password:  "password-123",
+		From:      "Сватба <wedding@example.com>",
+		StartTLS:  startTLS,
+		Timeout:   5 * time.Second,
+		TLSConfig: srv.ClientTLSConfig(),
+	})
+}
+
+func TestMailer_StartTLS(t *testing.T) {
+	srv := smtptest.NewStartTLSServer()
+	defer srv.Close()
+	srv.Username, srv.Password = "wedding", "password-123"
+
+	msg := Message{
+		To:      []string{"bride@example.com", "groom@example.com"},
+		Subject: "Потвърждение: Иван Петров",
+		Body:    "Иван Петров потвърди присъствие.\n.\nКрай\n",
+	}
+	if err := testMailer(srv, true).Send(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := srv.Messages()
+	if len(got) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(got))
+	}
+	m := got[0]
+	if !m.TLS || m.Username != "wedding" {
+		t.Fatalf("expected an authenticated TLS session, got TLS=%v user=%q", m.TLS, m.Username)
+	}
+	if m.From != "wedding@example.com" || len(m.To) != 2 || m.To[1] != "groom@example.com" {
+		t.Fatalf("expected the envelope addresses, got from %q to %v", m.From, m.To)
+	}
+	if m.Subject != msg.Subject {
+		t.Fatalf("expected subject %q, got %q", msg.Subject, m.Subject)
+	}
+	if m.Body != msg.Body {
+		t.Fatalf("expected body %q, got %q", msg.Body, m.Body)
+	}
+	if got := m.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Fatalf("expected utf-8 plain text, got %q", got)
+	}
+	if got := m.Header.Get("Message-ID"); !strings.HasSuffix(got, "@example.com>") {
+		t.Fatalf("expected a Message-ID at the sender's domain, got %q", got)
+	}
+}
+
+func TestMailer_Plain(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+
+	m := testMailer(srv, false)
+	m.cfg.Username = ""
+	if err := m.Send(context.Background(), Message{To: []string{"bride@example.com"}, Subject: "hi", Body: "hello\n"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := srv.Messages(); len(got) != 1 || got[0].TLS || got[0].Body != "hello\n" {
+		t.Fatalf("expected one plain message, got %+v", got)
+	}
+}
+
+func TestMailer_Errors(t *testing.T) {
+	msg := Message{To: []string{"bride@example.com"}, Subject: "hi", Body: "hello"}
+
+	t.Run("no STARTTLS", func(t *testing.T) {
+		srv := smtptest.NewServer()
+		defer srv.Close()
+		err := testMailer(srv, true).Send(context.Background(), msg)
+		if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+			t.Fatalf("expected a STARTTLS error, got %v", err)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		srv := smtptest.NewStartTLSServer()
+		defer srv.Close()
+		srv.Username, srv.Password = "wedding", "another-password"
+		if err := testMailer(srv, true).Send(context.Background(), msg); err == nil {
+			t.Fatalf("expected an authentication error")
+		}
+		if n := len(srv.Messages()); n != 0 {
+			t.Fatalf("expected no messages, got %d", n)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		srv := smtptest.NewServer()
+		defer srv.Close()
+		srv.Reject = true
+		m := testMailer(srv, false)
+		m.cfg.Username = ""
+		if err := m.Send(context.Background(), msg); err == nil {
+			t.Fatalf("expected the rejection returned")
+		}
+	})
+
+	t.Run("header injection", func(t *testing.T) {
+		srv := smtptest.NewServer()
+		defer srv.Close()
+		bad := msg
+		bad.Subject = "hi\r\nBcc: someone@example.com"
+		if err := testMailer(srv, false).Send(context.Background(), bad); err == nil {
+			t.Fatalf("expected a line break in a header rejected")
+		}
+	})
+}
@@ -0,0 +1,297 @@
+// Package smtptest provides an in-process SMTP server for tests, in the
+// spirit of net/http/httptest. It speaks just enough SMTP for net/smtp:
+// EHLO, STARTTLS, AUTH PLAIN, MAIL, RCPT and DATA, and keeps every message
+// it accepts.
+package smtptest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is an email the server accepted.
+type Message struct {
+	From string
+	To   []string
+	// Username is who logged in with AUTH PLAIN, if anyone did.
+	Username string
+	// TLS reports whether the session was upgraded with STARTTLS.
+	TLS  bool
+	Data []byte
+
+	// Header, Subject and Body are Data parsed, with the subject and a
+	// quoted-printable body decoded.
+	Header  mail.Header
+	Subject string
+	Body    string
+}
+
+// Server is a running SMTP server. Set Username and Password before the
+// first connection to require AUTH PLAIN, and Reject to refuse every
+// message with a 554.
+type Server struct {
+	Host string
+	Port int
+
+	Username string
+	Password string
+	Reject   bool
+
+	listener net.Listener
+	tls      *tls.Config
+	roots    *x509.CertPool
+
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a plain SMTP server on a local port.
+func NewServer() *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("smtptest: failed to listen: " + err.Error())
+	}
+	addr := l.Addr().(*net.TCPAddr)
+	s := &Server{Host: addr.IP.String(), Port: addr.Port, listener: l}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// NewStartTLSServer starts a server that offers STARTTLS with a self-signed
+// certificate for 127.0.0.1; ClientTLSConfig trusts it.
+func NewStartTLSServer() *Server {
+	cert, roots := selfSigned()
+	s := NewServer()
+	s.tls = &tls.Config{Certificates: []tls.Certificate{cert}}
+	s.roots = roots
+	return s
+}
+
+// ClientTLSConfig returns a TLS config that trusts the server's certificate.
+func (s *Server) ClientTLSConfig() *tls.Config {
+	return &tls.Config{RootCAs: s.roots}
+}
+
+// Messages returns the messages accepted so far, oldest first.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Close stops the server and waits for open sessions to end.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+			s.session(conn)
+		}()
+	}
+}
+
+// session runs one SMTP conversation until QUIT or an I/O error.
+func (s *Server) session(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	var (
+		msg      Message
+		username string
+		secure   bool
+	)
+	reply := func(code int, text string) bool {
+		return tp.PrintfLine("%d %s", code, text) == nil
+	}
+	if !reply(220, "smtptest ready") {
+		return
+	}
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			ext := []string{"smtptest", "8BITMIME"}
+			if s.tls != nil && !secure {
+				ext = append(ext, "STARTTLS")
+			}
+			if s.Username != "" {
+				ext = append(ext, "AUTH PLAIN")
+			}
+			for _, e := range ext[:len(ext)-1] {
+				if tp.PrintfLine("250-%s", e) != nil {
+					return
+				}
+			}
+			reply(250, ext[len(ext)-1])
+		case "STARTTLS":
+			if s.tls == nil || secure {
+				reply(502, "STARTTLS not available")
+				continue
+			}
+			if !reply(220, "go ahead") {
+				return
+			}
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			tp = textproto.NewConn(conn)
+			msg, username = Message{}, ""
+		case "AUTH":
+			mech, resp, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mech, "PLAIN") {
+				reply(504, "only PLAIN is supported")
+				continue
+			}
+			user, ok := s.checkPlain(resp)
+			if !ok {
+				reply(535, "authentication failed")
+				continue
+			}
+			username = user
+			reply(235, "authenticated")
+		case "MAIL":
+			if s.Username != "" && username == "" {
+				reply(530, "authentication required")
+				continue
+			}
+			msg = Message{From: address(arg), Username: username, TLS: secure}
+			reply(250, "ok")
+		case "RCPT":
+			msg.To = append(msg.To, address(arg))
+			reply(250, "ok")
+		case "DATA":
+			if !reply(354, "end with <CRLF>.<CRLF>") {
+				return
+			}
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			if s.Reject {
+				reply(554, "rejected")
+				continue
+			}
+			msg.Data = data
+			parse(&msg)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply(250, "queued")
+		case "RSET":
+			msg = Message{Username: username, TLS: secure}
+			reply(250, "ok")
+		case "NOOP":
+			reply(250, "ok")
+		case "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			reply(502, "command not implemented")
+		}
+	}
+}
+
+// checkPlain checks an AUTH PLAIN initial response against the server's
+// credentials.
+func (s *Server) checkPlain(resp string) (string, bool) {
+	raw, err := base64.StdEncoding.DecodeString(resp)
+	if err != nil {
+		return "", false
+	}
+	parts := bytes.Split(raw, []byte{0})
+	if len(parts) != 3 {
+		return "", false
+	}
+	user, pass := string(parts[1]), string(parts[2])
+	return user, user == s.Username && pass == s.Password
+}
+
+// address takes the address out of "FROM:<a@b>" or "TO:<a@b>".
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
+
+// parse fills in the parsed fields of msg. A message that does not parse
+// keeps only its Data.
+func parse(msg *Message) {
+	m, err := mail.ReadMessage(bytes.NewReader(msg.Data))
+	if err != nil {
+		return
+	}
+	msg.Header = m.Header
+	msg.Subject, _ = new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	body := m.Body
+	if strings.EqualFold(m.Header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+		body = quotedprintable.NewReader(body)
+	}
+	b, _ := io.ReadAll(bufio.NewReader(body))
+	msg.Body = strings.ReplaceAll(string(b), "\r\n", "\n")
+}
+
+// selfSigned returns a certificate for 127.0.0.1 and localhost and a pool
+// that trusts it.
+func selfSigned() (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic("smtptest: failed to generate key: " + err.Error())
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:              []string{"localhost"},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		panic("smtptest: failed to create certificate: " + err.Error())
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		panic("smtptest: failed to parse certificate: " + err.Error())
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, roots
+}
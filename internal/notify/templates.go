@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/dimitarkovachev/wedding/internal/i18n"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// templateFS holds one file per language, named by its code, each defining
+// rsvp_subject, rsvp_body, digest_subject and digest_body.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Reply is one invite's answer as shown in emails.
+type Reply struct {
+	InviteID   string
+	People     []string
+	Additional []string
+	Accepted   bool
+	Declined   bool
+	// At is when the answer was given or last changed.
+	At time.Time
+}
+
+// Totals counts the answers across every invite.
+type Totals struct {
+	Invites  int
+	Accepted int
+	Declined int
+	// Pending is the invites with neither answer.
+	Pending int
+	// Guests is the people and additional guests on accepted invites.
+	Guests int
+}
+
+// Digest is the summary of the replies between Since and Until.
+type Digest struct {
+	Since time.Time
+	Until time.Time
+	// Accepted lists invites accepted in the period, Changes any other
+	// invite that changed, both oldest first.
+	Accepted []Reply
+	Changes  []Reply
+	Totals   Totals
+}
+
+type rsvpData struct {
+	Reply  Reply
+	Totals Totals
+}
+
+// newReply returns the reply on rec, given or changed at.
+func newReply(id string, rec store.InviteRecord, at time.Time) Reply {
+	return Reply{
+		InviteID:   id,
+		People:     rec.People,
+		Additional: rec.Additional,
+		Accepted:   rec.Accepted,
+		Declined:   rec.Declined,
+		At:         at,
+	}
+}
+
+// countTotals counts the answers on invites.
+func countTotals(invites map[string]store.InviteRecord) Totals {
+	t := Totals{Invites: len(invites)}
+	for _, rec := range invites {
+		switch {
+		case rec.Accepted:
+			t.Accepted++
+			t.Guests += len(rec.People) + len(rec.Additional)
+		case rec.Declined:
+			t.Declined++
+		default:
+			t.Pending++
+		}
+	}
+	return t
+}
+
+// templates renders the emails in one language.
+type templates struct {
+	tmpl *template.Template
+}
+
+// loadTemplates parses the templates for lang, showing times in loc.
+func loadTemplates(lang language.Tag, loc *time.Location) (*templates, error) {
+	if _, ok := i18n.Parse(lang.String()); !ok {
+		return nil, fmt.Errorf("no email templates for language %q", lang)
+	}
+	funcs := template.FuncMap{
+		"join": func(names []string) string { return strings.Join(names, ", ") },
+		"date": func(t time.Time) string { return t.In(loc).Format("02.01.2006 15:04") },
+	}
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(templateFS, "templates/"+lang.String()+".tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("parsing email templates: %w", err)
+	}
+	return &templates{tmpl: tmpl}, nil
+}
+
+// rsvp renders the instant email for reply.
+func (t *templates) rsvp(reply Reply, totals Totals) (subject, body string, err error) {
+	return t.render("rsvp", rsvpData{Reply: reply, Totals: totals})
+}
+
+// digest renders the digest email for d.
+func (t *templates) digest(d Digest) (subject, body string, err error) {
+	return t.render("digest", d)
+}
+
+func (t *templates) render(name string, data any) (subject, body string, err error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.ExecuteTemplate(&buf, name+"_subject", data); err != nil {
+		return "", "", fmt.Errorf("rendering %s subject: %w", name, err)
+	}
+	subject = strings.TrimSpace(buf.String())
+	buf.Reset()
+	if err := t.tmpl.ExecuteTemplate(&buf, name+"_body", data); err != nil {
+		return "", "", fmt.Errorf("rendering %s body: %w", name, err)
+	}
+	return subject, buf.String(), nil
+}
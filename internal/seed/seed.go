@@ -1,12 +1,15 @@
 package seed
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/dimitarkovachev/wedding/internal/names"
 	"github.com/dimitarkovachev/wedding/internal/store"
 )
 
@@ -15,8 +18,9 @@ type SeedData struct {
 }
 
 // LoadFromFile reads seed data from a JSON file and populates the store.
-// Returns nil if path is empty (seeding disabled).
-func LoadFromFile(path string, s *store.BBoltStore) error {
+// Returns nil if path is empty (seeding disabled). Additional guest names must
+// follow rules; the first violation aborts seeding.
+func LoadFromFile(ctx context.Context, path string, s store.Store, rules names.Rules) error {
 	if path == "" {
 		return nil
 	}
@@ -31,7 +35,27 @@ func LoadFromFile(path string, s *store.BBoltStore) error {
 		return fmt.Errorf("parsing seed file %s: %w", path, err)
 	}
 
+	if err := validate(sd.Invites, rules); err != nil {
+		return fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+
 	log.WithField("count", len(sd.Invites)).Info("seeding invites from file")
 
-	return s.Seed(sd.Invites)
+	return s.Seed(ctx, sd.Invites)
+}
+
+func validate(invites map[string]store.InviteRecord, rules names.Rules) error {
+	ids := make([]string, 0, len(invites))
+	for id := range invites {
+		ids = append(ids, id)
+	}
+	// Reason: report the same invite first on every run
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if v := rules.CheckAll(invites[id].Additional); len(v) > 0 {
+			return fmt.Errorf("invite %s: additional %w", id, v[0])
+		}
+	}
+	return nil
 }
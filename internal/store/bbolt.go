@@ -3,140 +3,217 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var bucketName = []byte("invites")
 
+const tracerName = "github.com/dimitarkovachev/wedding/internal/store"
+
+// startSpan starts a span for a BBoltStore method.
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", "bbolt"))
+	return otel.Tracer(tracerName).Start(ctx, "BBoltStore."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// lockAcquired marks the point where a read-write transaction obtained the
+// single BBolt writer lock, so time spent waiting for it shows up in traces.
+func lockAcquired(span trace.Span) {
+	span.AddEvent("write lock acquired")
+}
+
 type BBoltStore struct {
-	db *bolt.DB
+	db       *bolt.DB
+	views    ViewPolicy
+	deadline time.Time
 }
 
-func NewBBoltStore(path string) (*BBoltStore, error) {
+func NewBBoltStore(path string, opts ...Option) (*BBoltStore, error) {
 	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
 	if err != nil {
-		return nil, fmt.Errorf("opening bbolt db at %s: %w", path, err)
+		return nil, storageErr("opening bbolt db at "+path, err)
 	}
 
 	// Reason: bucket must exist before any read/write operations
 	err = db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(bucketName)
-		return err
+		for _, name := range [][]byte{bucketName, idempotencyBucket, webhooksBucket, outboxBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("creating invites bucket: %w", err)
+		return nil, storageErr("creating buckets", err)
 	}
 
-	return &BBoltStore{db: db}, nil
+	o := newOptions(opts)
+	return &BBoltStore{db: db, views: o.views, deadline: o.deadline}, nil
 }
 
-func (s *BBoltStore) GetInvite(_ context.Context, id string) (*InviteRecord, error) {
-	var record *InviteRecord
-
-	err := s.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucketName)
-		data := b.Get([]byte(id))
-		if data == nil {
-			return nil
-		}
+// view runs fn in a read transaction unless ctx is already done.
+func (s *BBoltStore) view(ctx context.Context, op string, fn func(*bolt.Tx) error) error {
+	if err := ctxErr(ctx, op); err != nil {
+		return err
+	}
+	return txErr(op, s.db.View, fn)
+}
 
-		var r InviteRecord
-		if err := json.Unmarshal(data, &r); err != nil {
-			return fmt.Errorf("unmarshaling invite %s: %w", id, err)
+// update runs fn in a read-write transaction, checking ctx again once the
+// writer lock is held.
+func (s *BBoltStore) update(ctx context.Context, span trace.Span, op string, fn func(*bolt.Tx) error) error {
+	if err := ctxErr(ctx, op); err != nil {
+		return err
+	}
+	return txErr(op, s.db.Update, func(tx *bolt.Tx) error {
+		lockAcquired(span)
+		if err := ctxErr(ctx, op); err != nil {
+			return err
 		}
+		return fn(tx)
+	})
+}
 
-		// Reason: capture pre-append state so the caller sees the original ViewedAt
-		snapshot := r
-		record = &snapshot
+// txErr runs fn in a transaction started by run. Errors from fn are returned
+// as they are; failures of the transaction itself, such as a failed commit,
+// are storage errors.
+func txErr(op string, run func(func(*bolt.Tx) error) error, fn func(*bolt.Tx) error) error {
+	var fnErr error
+	err := run(func(tx *bolt.Tx) error {
+		fnErr = fn(tx)
+		return fnErr
+	})
+	if err != nil && err != fnErr {
+		return storageErr(op, err)
+	}
+	return err
+}
 
-		r.ViewedAt = append(r.ViewedAt, time.Now().UTC())
+// GetInvite returns the invite, or ErrNotFound when it does not exist. It
+// does not record a view; see RecordView.
+func (s *BBoltStore) GetInvite(ctx context.Context, id string) (record *InviteRecord, err error) {
+	_, span := startSpan(ctx, "GetInvite", attribute.String("invite.id", id))
+	defer func() { endSpan(span, err) }()
 
-		updated, err := json.Marshal(r)
-		if err != nil {
-			return fmt.Errorf("marshaling invite %s: %w", id, err)
+	err = s.view(ctx, "getting invite "+id, func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketName).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("getting invite %s: %w", id, ErrNotFound)
 		}
-		if err := b.Put([]byte(id), updated); err != nil {
-			return fmt.Errorf("writing viewed_at for invite %s: %w", id, err)
+		r, err := decodeInvite([]byte(id), data)
+		if err != nil {
+			return err
 		}
+		record = &r
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-
 	return record, nil
 }
 
-func (s *BBoltStore) UpdateInvite(_ context.Context, id string, accepted bool, additional []string) (*InviteRecord, error) {
-	var record *InviteRecord
+// RecordView adds view to the invite's history under the store's
+// ViewPolicy. Views deduplicated by the policy, and views of missing
+// invites, are dropped without a write.
+func (s *BBoltStore) RecordView(ctx context.Context, id string, view View) (err error) {
+	ctx, span := startSpan(ctx, "RecordView", attribute.String("invite.id", id), attribute.Bool("view.bot", view.Bot))
+	defer func() { endSpan(span, err) }()
 
-	err := s.db.Update(func(tx *bolt.Tx) error {
+	// Reason: most repeat views are deduplicated, so check under a read
+	// transaction first rather than queueing every view for the writer lock
+	rec, err := s.GetInvite(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !rec.Views.record(view, s.views) {
+		span.SetAttributes(attribute.Bool("view.counted", false))
+		return nil
+	}
+
+	return s.update(ctx, span, "recording view of invite "+id, func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketName)
 		data := b.Get([]byte(id))
 		if data == nil {
 			return nil
 		}
-
-		var r InviteRecord
-		if err := json.Unmarshal(data, &r); err != nil {
-			return fmt.Errorf("unmarshaling invite %s: %w", id, err)
+		r, err := decodeInvite([]byte(id), data)
+		if err != nil {
+			return err
 		}
-
-		if !accepted {
-			return fmt.Errorf("only accepted=true updates are allowed")
+		counted := r.Views.record(view, s.views)
+		span.SetAttributes(attribute.Bool("view.counted", counted))
+		if !counted {
+			return nil
 		}
+		return putInvite(b, id, r)
+	})
+}
 
-		if len(additional) > r.AdditionalCount {
-			return fmt.Errorf(
-				"too many additional guests: got %d, max allowed %d",
-				len(additional), r.AdditionalCount,
-			)
-		}
+func (s *BBoltStore) UpdateInvite(ctx context.Context, id string, accepted bool, additional []string) (*InviteRecord, error) {
+	return s.UpdateInviteWithOutbox(ctx, id, accepted, additional, nil)
+}
 
-		r.Accepted = true
-		r.Additional = additional
-		now := time.Now().UTC()
-		r.AcceptedAt = &now
+// UpdateInviteWithOutbox is UpdateInvite that also puts the deliveries
+// outbox returns for the change in the outbox, in the same transaction.
+func (s *BBoltStore) UpdateInviteWithOutbox(ctx context.Context, id string, accepted bool, additional []string, outbox OutboxFunc) (record *InviteRecord, err error) {
+	_, span := startSpan(ctx, "UpdateInvite", attribute.String("invite.id", id))
+	defer func() { endSpan(span, err) }()
 
-		updated, err := json.Marshal(r)
-		if err != nil {
-			return fmt.Errorf("marshaling invite %s: %w", id, err)
-		}
-		if err := b.Put([]byte(id), updated); err != nil {
-			return fmt.Errorf("writing invite %s: %w", id, err)
-		}
-
-		record = &r
-		return nil
+	op := "updating invite " + id
+	err = s.update(ctx, span, op, func(tx *bolt.Tx) error {
+		record, err = editInvite(tx, op, id, func(r *InviteRecord) error {
+			return accept(r, accepted, additional, time.Now().UTC(), s.deadline)
+		}, outbox)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-
 	return record, nil
 }
 
 // Seed loads invite records from a map, skipping keys that already exist.
-func (s *BBoltStore) Seed(invites map[string]InviteRecord) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+// It checks ctx between records, and a canceled seed writes nothing.
+func (s *BBoltStore) Seed(ctx context.Context, invites map[string]InviteRecord) (err error) {
+	_, span := startSpan(ctx, "Seed", attribute.Int("invite.count", len(invites)))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, span, "seeding invites", func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketName)
 		for id, rec := range invites {
+			if err := ctxErr(ctx, "seeding invites"); err != nil {
+				return err
+			}
 			existing := b.Get([]byte(id))
 			if existing != nil {
 				log.WithField("id", id).Debug("seed: invite already exists, skipping")
 				continue
 			}
-			data, err := json.Marshal(rec)
-			if err != nil {
-				return fmt.Errorf("marshaling seed invite %s: %w", id, err)
-			}
-			if err := b.Put([]byte(id), data); err != nil {
-				return fmt.Errorf("seeding invite %s: %w", id, err)
+			if err := putInvite(b, id, rec); err != nil {
+				return err
 			}
 			log.WithField("id", id).Info("seeded invite")
 		}
@@ -144,15 +221,21 @@ func (s *BBoltStore) Seed(invites map[string]InviteRecord) error {
 	})
 }
 
-func (s *BBoltStore) GetAllInvites(_ context.Context) (map[string]InviteRecord, error) {
+func (s *BBoltStore) GetAllInvites(ctx context.Context) (_ map[string]InviteRecord, err error) {
+	_, span := startSpan(ctx, "GetAllInvites")
+	defer func() { endSpan(span, err) }()
+
 	result := make(map[string]InviteRecord)
 
-	err := s.db.View(func(tx *bolt.Tx) error {
+	err = s.view(ctx, "getting invites", func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketName)
 		return b.ForEach(func(k, v []byte) error {
-			var r InviteRecord
-			if err := json.Unmarshal(v, &r); err != nil {
-				return fmt.Errorf("unmarshaling invite %s: %w", string(k), err)
+			if err := ctxErr(ctx, "getting invites"); err != nil {
+				return err
+			}
+			r, err := decodeInvite(k, v)
+			if err != nil {
+				return err
 			}
 			result[string(k)] = r
 			return nil
@@ -162,25 +245,182 @@ func (s *BBoltStore) GetAllInvites(_ context.Context) (map[string]InviteRecord,
 		return nil, err
 	}
 
+	span.SetAttributes(attribute.Int("invite.count", len(result)))
 	return result, nil
 }
 
-func (s *BBoltStore) ReplaceAllInvites(_ context.Context, invites map[string]InviteRecord) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+// RecordViews applies a batch of views in one transaction, under the same
+// ViewPolicy as RecordView, and returns the views that were counted. Views
+// of missing invites are skipped.
+func (s *BBoltStore) RecordViews(ctx context.Context, events []ViewEvent) (counted []ViewEvent, err error) {
+	_, span := startSpan(ctx, "RecordViews", attribute.Int("view.count", len(events)))
+	defer func() {
+		span.SetAttributes(attribute.Int("view.counted", len(counted)))
+		endSpan(span, err)
+	}()
+
+	err = s.update(ctx, span, "recording views", func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		changed, n, err := applyViews(events, s.views, func(id string) (*InviteRecord, error) {
+			data := b.Get([]byte(id))
+			if data == nil {
+				return nil, nil
+			}
+			r, err := decodeInvite([]byte(id), data)
+			return &r, err
+		})
+		if err != nil {
+			return err
+		}
+		for id, r := range changed {
+			if err := putInvite(b, id, *r); err != nil {
+				return err
+			}
+		}
+		counted = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counted, nil
+}
+
+// CreateInvite stores a new invite, failing with ErrInviteExists if the ID
+// is taken.
+func (s *BBoltStore) CreateInvite(ctx context.Context, id string, rec InviteRecord) (err error) {
+	_, span := startSpan(ctx, "CreateInvite", attribute.String("invite.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, span, "creating invite "+id, func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		if b.Get([]byte(id)) != nil {
+			return fmt.Errorf("creating invite %s: %w", id, ErrInviteExists)
+		}
+		return putInvite(b, id, rec)
+	})
+}
+
+// EditInvite applies edit to the stored invite in one transaction, so views
+// recorded meanwhile are not lost, and returns the saved record. It returns
+// ErrNotFound when the invite does not exist; an error from edit aborts the
+// change and is returned as it is.
+func (s *BBoltStore) EditInvite(ctx context.Context, id string, edit func(*InviteRecord) error) (*InviteRecord, error) {
+	return s.EditInviteWithOutbox(ctx, id, edit, nil)
+}
+
+// EditInviteWithOutbox is EditInvite that also puts the deliveries outbox
+// returns for the change in the outbox, in the same transaction.
+func (s *BBoltStore) EditInviteWithOutbox(ctx context.Context, id string, edit func(*InviteRecord) error, outbox OutboxFunc) (record *InviteRecord, err error) {
+	_, span := startSpan(ctx, "EditInvite", attribute.String("invite.id", id))
+	defer func() { endSpan(span, err) }()
+
+	op := "editing invite " + id
+	err = s.update(ctx, span, op, func(tx *bolt.Tx) error {
+		record, err = editInvite(tx, op, id, edit, outbox)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// editInvite applies edit to the invite in tx and saves it together with
+// the deliveries from outbox, which may be nil.
+func editInvite(tx *bolt.Tx, op, id string, edit func(*InviteRecord) error, outbox OutboxFunc) (*InviteRecord, error) {
+	b := tx.Bucket(bucketName)
+	data := b.Get([]byte(id))
+	if data == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+	r, err := decodeInvite([]byte(id), data)
+	if err != nil {
+		return nil, err
+	}
+	before := r.clone()
+	if err := edit(&r); err != nil {
+		return nil, err
+	}
+	if err := putInvite(b, id, r); err != nil {
+		return nil, err
+	}
+	if outbox != nil {
+		deliveries, err := outbox(id, before, r)
+		if err != nil {
+			return nil, err
+		}
+		if err := addDeliveries(tx, deliveries); err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// DeleteInvite removes the invite, or returns ErrNotFound when it does not
+// exist.
+func (s *BBoltStore) DeleteInvite(ctx context.Context, id string) (err error) {
+	_, span := startSpan(ctx, "DeleteInvite", attribute.String("invite.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, span, "deleting invite "+id, func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		if b.Get([]byte(id)) == nil {
+			return fmt.Errorf("deleting invite %s: %w", id, ErrNotFound)
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return storageErr("deleting invite "+id, err)
+		}
+		return nil
+	})
+}
+
+func putInvite(b *bolt.Bucket, id string, rec InviteRecord) error {
+	data, err := encodeInvite(id, rec)
+	if err != nil {
+		return err
+	}
+	if err := b.Put([]byte(id), data); err != nil {
+		return storageErr("writing invite "+id, err)
+	}
+	return nil
+}
+
+func encodeInvite(id string, rec InviteRecord) ([]byte, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, storageErr("marshaling invite "+id, err)
+	}
+	return data, nil
+}
+
+func decodeInvite(k, v []byte) (InviteRecord, error) {
+	var r InviteRecord
+	if err := json.Unmarshal(v, &r); err != nil {
+		return r, storageErr("unmarshaling invite "+string(k), err)
+	}
+	r.compactLegacyViews()
+	return r, nil
+}
+
+func (s *BBoltStore) ReplaceAllInvites(ctx context.Context, invites map[string]InviteRecord) (err error) {
+	_, span := startSpan(ctx, "ReplaceAllInvites", attribute.Int("invite.count", len(invites)))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, span, "replacing invites", func(tx *bolt.Tx) error {
 		if err := tx.DeleteBucket(bucketName); err != nil {
-			return fmt.Errorf("deleting invites bucket: %w", err)
+			return storageErr("deleting invites bucket", err)
 		}
 		b, err := tx.CreateBucket(bucketName)
 		if err != nil {
-			return fmt.Errorf("recreating invites bucket: %w", err)
+			return storageErr("recreating invites bucket", err)
 		}
 		for id, rec := range invites {
-			data, err := json.Marshal(rec)
-			if err != nil {
-				return fmt.Errorf("marshaling invite %s: %w", id, err)
+			if err := ctxErr(ctx, "replacing invites"); err != nil {
+				return err
 			}
-			if err := b.Put([]byte(id), data); err != nil {
-				return fmt.Errorf("writing invite %s: %w", id, err)
+			if err := putInvite(b, id, rec); err != nil {
+				return err
 			}
 		}
 		return nil
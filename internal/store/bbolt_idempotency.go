@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var idempotencyBucket = []byte("idempotency")
+
+// GetIdempotencyRecord returns the record saved under key, or ErrNotFound.
+func (s *BBoltStore) GetIdempotencyRecord(ctx context.Context, key string) (rec *IdempotencyRecord, err error) {
+	_, span := startSpan(ctx, "GetIdempotencyRecord")
+	defer func() { endSpan(span, err) }()
+
+	err = s.view(ctx, "getting idempotency record", func(tx *bolt.Tx) error {
+		data := tx.Bucket(idempotencyBucket).Get([]byte(key))
+		if data == nil {
+			return fmt.Errorf("getting idempotency record %s: %w", key, ErrNotFound)
+		}
+		rec = &IdempotencyRecord{}
+		return decodeJSON("idempotency record", key, data, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// PutIdempotencyRecord saves rec under key, replacing any earlier record.
+func (s *BBoltStore) PutIdempotencyRecord(ctx context.Context, key string, rec IdempotencyRecord) (err error) {
+	_, span := startSpan(ctx, "PutIdempotencyRecord")
+	defer func() { endSpan(span, err) }()
+
+	data, err := encodeJSON("idempotency record", key, rec)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, span, "saving idempotency record", func(tx *bolt.Tx) error {
+		if err := tx.Bucket(idempotencyBucket).Put([]byte(key), data); err != nil {
+			return storageErr("saving idempotency record "+key, err)
+		}
+		return nil
+	})
+}
+
+// DeleteIdempotencyRecords removes the records created before cutoff. Keys
+// are in no time order, so the whole bucket is scanned.
+func (s *BBoltStore) DeleteIdempotencyRecords(ctx context.Context, cutoff time.Time) (deleted int, err error) {
+	_, span := startSpan(ctx, "DeleteIdempotencyRecords")
+	defer func() {
+		span.SetAttributes(attribute.Int("idempotency.deleted", deleted))
+		endSpan(span, err)
+	}()
+
+	err = s.update(ctx, span, "deleting idempotency records", func(tx *bolt.Tx) error {
+		b := tx.Bucket(idempotencyBucket)
+		var expired [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			if err := ctxErr(ctx, "deleting idempotency records"); err != nil {
+				return err
+			}
+			var rec IdempotencyRecord
+			if err := decodeJSON("idempotency record", string(k), v, &rec); err != nil {
+				return err
+			}
+			if rec.CreatedAt.Before(cutoff) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Reason: deleting while iterating makes a BBolt cursor skip keys
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return storageErr("deleting idempotency record "+string(k), err)
+			}
+		}
+		deleted = len(expired)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
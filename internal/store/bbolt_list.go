@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ListInvites returns one page of invites matching q. Pages in ID order are
+// read straight off a BBolt cursor and stop once the page is full; other
+// orders scan the bucket and sort the matches.
+func (s *BBoltStore) ListInvites(ctx context.Context, q ListQuery) (page *ListPage, err error) {
+	_, span := startSpan(ctx, "ListInvites",
+		attribute.String("list.sort", string(q.Sort)), attribute.Int("list.limit", q.Limit))
+	defer func() { endSpan(span, err) }()
+
+	if q.Limit <= 0 {
+		return nil, fmt.Errorf("list limit must be positive, got %d", q.Limit)
+	}
+
+	err = s.view(ctx, "listing invites", func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketName).Cursor()
+		if q.Sort == SortByID || q.Sort == "" {
+			page, err = listByID(c, q)
+			return err
+		}
+
+		var entries []InviteEntry
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if err := ctxErr(ctx, "listing invites"); err != nil {
+				return err
+			}
+			r, err := decodeInvite(k, v)
+			if err != nil {
+				return err
+			}
+			if q.keep(string(k), r) {
+				entries = append(entries, InviteEntry{ID: string(k), Record: r})
+			}
+		}
+		page = sortedPage(entries, q)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("invite.count", len(page.Invites)))
+	return page, nil
+}
+
+// listByID walks the bucket in key order from q.After, collecting one more
+// match than the limit to learn whether another page follows.
+func listByID(c *bolt.Cursor, q ListQuery) (*ListPage, error) {
+	var k, v []byte
+	switch {
+	case q.After == nil && q.Desc:
+		k, v = c.Last()
+	case q.After == nil:
+		k, v = c.First()
+	case q.Desc:
+		// Reason: Seek lands on the first key >= After, so one step back is
+		// the first key strictly before it
+		if k, _ = c.Seek([]byte(q.After.ID)); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+	default:
+		if k, v = c.Seek([]byte(q.After.ID)); k != nil && string(k) == q.After.ID {
+			k, v = c.Next()
+		}
+	}
+
+	page := &ListPage{}
+	for ; k != nil; k, v = step(c, q.Desc) {
+		r, err := decodeInvite(k, v)
+		if err != nil {
+			return nil, err
+		}
+		if !q.keep(string(k), r) {
+			continue
+		}
+		if len(page.Invites) == q.Limit {
+			last := page.Invites[q.Limit-1].ID
+			page.Next = &Cursor{Value: last, ID: last}
+			break
+		}
+		page.Invites = append(page.Invites, InviteEntry{ID: string(k), Record: r})
+	}
+	return page, nil
+}
+
+func step(c *bolt.Cursor, desc bool) ([]byte, []byte) {
+	if desc {
+		return c.Prev()
+	}
+	return c.Next()
+}
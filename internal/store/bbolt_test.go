@@ -5,6 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func tempDBPath(t *testing.T) string {
@@ -20,7 +26,7 @@ func seedTestStore(t *testing.T) *BBoltStore {
 	}
 	t.Cleanup(func() { s.Close() })
 
-	err = s.Seed(map[string]InviteRecord{
+	err = s.Seed(t.Context(), map[string]InviteRecord{
 		"aaa-001": {
 			People:          []string{"Иван Петров", "Мария Петрова"},
 			AdditionalCount: 2,
@@ -33,190 +39,48 @@ func seedTestStore(t *testing.T) *BBoltStore {
 	return s
 }
 
-func TestGetInvite_Found(t *testing.T) {
-	s := seedTestStore(t)
-
-	rec, err := s.GetInvite(context.Background(), "aaa-001")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if rec == nil {
-		t.Fatal("expected invite, got nil")
-	}
-	if len(rec.People) != 2 {
-		t.Fatalf("expected 2 people, got %d", len(rec.People))
-	}
-	if rec.Accepted {
-		t.Fatal("expected accepted=false")
-	}
-	if len(rec.ViewedAt) != 0 {
-		t.Fatalf("expected 0 viewed_at entries (pre-append), got %d", len(rec.ViewedAt))
-	}
-}
-
-func TestGetInvite_NotFound(t *testing.T) {
-	s := seedTestStore(t)
-
-	rec, err := s.GetInvite(context.Background(), "nonexistent")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if rec != nil {
-		t.Fatal("expected nil for nonexistent invite")
-	}
-}
-
-func TestGetInvite_AppendsViewedAt(t *testing.T) {
-	s := seedTestStore(t)
-
-	for i := 0; i < 3; i++ {
-		_, _ = s.GetInvite(context.Background(), "aaa-001")
-	}
-
-	rec, err := s.GetInvite(context.Background(), "aaa-001")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	// Returned record is pre-append: 3 previous GETs already persisted
-	if len(rec.ViewedAt) != 3 {
-		t.Fatalf("expected 3 viewed_at entries (pre-append), got %d", len(rec.ViewedAt))
-	}
-
-	// Verify DB has all 4 entries by reading once more
-	rec2, err := s.GetInvite(context.Background(), "aaa-001")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if len(rec2.ViewedAt) != 4 {
-		t.Fatalf("expected 4 viewed_at entries persisted in DB, got %d", len(rec2.ViewedAt))
-	}
-}
-
-func TestUpdateInvite_AcceptWithAdditionals(t *testing.T) {
-	s := seedTestStore(t)
-
-	rec, err := s.UpdateInvite(context.Background(), "aaa-001", true, []string{"Георги"})
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if !rec.Accepted {
-		t.Fatal("expected accepted=true")
-	}
-	if len(rec.Additional) != 1 || rec.Additional[0] != "Георги" {
-		t.Fatalf("unexpected additional: %v", rec.Additional)
-	}
-	if rec.AcceptedAt == nil {
-		t.Fatal("expected accepted_at to be set")
-	}
-}
-
-func TestUpdateInvite_NotFound(t *testing.T) {
-	s := seedTestStore(t)
-
-	rec, err := s.UpdateInvite(context.Background(), "nonexistent", true, nil)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if rec != nil {
-		t.Fatal("expected nil for nonexistent invite")
-	}
-}
-
-func TestUpdateInvite_AcceptedFalse(t *testing.T) {
-	s := seedTestStore(t)
-
-	_, err := s.UpdateInvite(context.Background(), "aaa-001", false, nil)
+func TestNewBBoltStore_InvalidPath(t *testing.T) {
+	_, err := NewBBoltStore(filepath.Join(os.DevNull, "impossible", "path.db"))
 	if err == nil {
-		t.Fatal("expected error for accepted=false")
+		t.Fatal("expected error for invalid path")
 	}
 }
 
-func TestUpdateInvite_TooManyAdditionals(t *testing.T) {
+func TestBBoltStore_RecordsSpans(t *testing.T) {
 	s := seedTestStore(t)
 
-	_, err := s.UpdateInvite(context.Background(), "aaa-001", true, []string{"А", "Б", "В"})
-	if err == nil {
-		t.Fatal("expected error for too many additionals")
-	}
-}
+	sr := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
 
-func TestGetAllInvites_Expected(t *testing.T) {
-	s := seedTestStore(t)
+	_ = s.RecordView(context.Background(), "aaa-001", View{At: time.Now()})
+	_, _ = s.UpdateInvite(context.Background(), "aaa-001", true, []string{"А", "Б", "В"})
 
-	invites, err := s.GetAllInvites(context.Background())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
 	}
-	if len(invites) != 1 {
-		t.Fatalf("expected 1 invite, got %d", len(invites))
-	}
-	rec, ok := invites["aaa-001"]
-	if !ok {
-		t.Fatal("expected key aaa-001")
-	}
-	if len(rec.People) != 2 {
-		t.Fatalf("expected 2 people, got %d", len(rec.People))
-	}
-}
 
-func TestGetAllInvites_EmptyBucket(t *testing.T) {
-	s, err := NewBBoltStore(tempDBPath(t))
-	if err != nil {
-		t.Fatalf("failed to create store: %v", err)
+	get, record := spans[0], spans[1]
+	if get.Name() != "BBoltStore.GetInvite" || record.Name() != "BBoltStore.RecordView" {
+		t.Fatalf("unexpected span names %q, %q", get.Name(), record.Name())
 	}
-	t.Cleanup(func() { s.Close() })
-
-	invites, err := s.GetAllInvites(context.Background())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	if get.Parent().SpanID() != record.SpanContext().SpanID() {
+		t.Fatal("expected the read to be a child of RecordView")
 	}
-	if len(invites) != 0 {
-		t.Fatalf("expected 0 invites, got %d", len(invites))
+	if len(get.Events()) != 0 {
+		t.Fatalf("expected read without write lock, got %v", get.Events())
 	}
-}
-
-func TestReplaceAllInvites_Expected(t *testing.T) {
-	s := seedTestStore(t)
-
-	newInvites := map[string]InviteRecord{
-		"bbb-001": {People: []string{"Нов Гост"}, AdditionalCount: 1, Accepted: false},
-		"bbb-002": {People: []string{"Друг Гост"}, AdditionalCount: 0, Accepted: true},
-	}
-	if err := s.ReplaceAllInvites(context.Background(), newInvites); err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	invites, err := s.GetAllInvites(context.Background())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if len(invites) != 2 {
-		t.Fatalf("expected 2 invites, got %d", len(invites))
-	}
-	if _, ok := invites["aaa-001"]; ok {
-		t.Fatal("old invite aaa-001 should have been removed")
-	}
-}
-
-func TestReplaceAllInvites_EmptyMap(t *testing.T) {
-	s := seedTestStore(t)
-
-	if err := s.ReplaceAllInvites(context.Background(), map[string]InviteRecord{}); err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	if len(record.Events()) != 1 || record.Events()[0].Name != "write lock acquired" {
+		t.Fatalf("expected write lock event, got %v", record.Events())
 	}
 
-	invites, err := s.GetAllInvites(context.Background())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if len(invites) != 0 {
-		t.Fatalf("expected 0 invites after empty replace, got %d", len(invites))
+	update := spans[2]
+	if update.Name() != "BBoltStore.UpdateInvite" {
+		t.Fatalf("unexpected span name %q", update.Name())
 	}
-}
-
-func TestNewBBoltStore_InvalidPath(t *testing.T) {
-	_, err := NewBBoltStore(filepath.Join(os.DevNull, "impossible", "path.db"))
-	if err == nil {
-		t.Fatal("expected error for invalid path")
+	if update.Status().Code != codes.Error {
+		t.Fatalf("expected error status for rejected update, got %v", update.Status().Code)
 	}
 }
@@ -0,0 +1,256 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	webhooksBucket = []byte("webhooks")
+	// outboxBucket holds every delivery by ID: the pending ones are the
+	// outbox, the rest the delivery log.
+	outboxBucket = []byte("webhook_outbox")
+)
+
+// ListWebhooks returns every webhook in ID order.
+func (s *BBoltStore) ListWebhooks(ctx context.Context) (hooks []Webhook, err error) {
+	_, span := startSpan(ctx, "ListWebhooks")
+	defer func() { endSpan(span, err) }()
+
+	err = s.view(ctx, "listing webhooks", func(tx *bolt.Tx) error {
+		return tx.Bucket(webhooksBucket).ForEach(func(k, v []byte) error {
+			var w Webhook
+			if err := decodeJSON("webhook", string(k), v, &w); err != nil {
+				return err
+			}
+			hooks = append(hooks, w)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// GetWebhook returns the webhook, or ErrNotFound.
+func (s *BBoltStore) GetWebhook(ctx context.Context, id string) (hook *Webhook, err error) {
+	_, span := startSpan(ctx, "GetWebhook", attribute.String("webhook.id", id))
+	defer func() { endSpan(span, err) }()
+
+	err = s.view(ctx, "getting webhook "+id, func(tx *bolt.Tx) error {
+		data := tx.Bucket(webhooksBucket).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("getting webhook %s: %w", id, ErrNotFound)
+		}
+		hook = &Webhook{}
+		return decodeJSON("webhook", id, data, hook)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// PutWebhook saves w under w.ID, replacing any earlier version.
+func (s *BBoltStore) PutWebhook(ctx context.Context, w Webhook) (err error) {
+	_, span := startSpan(ctx, "PutWebhook", attribute.String("webhook.id", w.ID))
+	defer func() { endSpan(span, err) }()
+
+	data, err := encodeJSON("webhook", w.ID, w)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, span, "saving webhook "+w.ID, func(tx *bolt.Tx) error {
+		if err := tx.Bucket(webhooksBucket).Put([]byte(w.ID), data); err != nil {
+			return storageErr("saving webhook "+w.ID, err)
+		}
+		return nil
+	})
+}
+
+// DeleteWebhook removes the webhook and its deliveries, or returns
+// ErrNotFound.
+func (s *BBoltStore) DeleteWebhook(ctx context.Context, id string) (err error) {
+	_, span := startSpan(ctx, "DeleteWebhook", attribute.String("webhook.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, span, "deleting webhook "+id, func(tx *bolt.Tx) error {
+		b := tx.Bucket(webhooksBucket)
+		if b.Get([]byte(id)) == nil {
+			return fmt.Errorf("deleting webhook %s: %w", id, ErrNotFound)
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return storageErr("deleting webhook "+id, err)
+		}
+		return deleteDeliveriesWhere(tx.Bucket(outboxBucket), func(d Delivery) bool {
+			return d.WebhookID == id
+		})
+	})
+}
+
+// AddDeliveries puts deliveries in the outbox in one transaction, dropping
+// those to a webhook deleted meanwhile.
+func (s *BBoltStore) AddDeliveries(ctx context.Context, deliveries []Delivery) (err error) {
+	_, span := startSpan(ctx, "AddDeliveries", attribute.Int("delivery.count", len(deliveries)))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, span, "adding deliveries", func(tx *bolt.Tx) error {
+		return addDeliveries(tx, deliveries)
+	})
+}
+
+// addDeliveries puts deliveries in the outbox in tx, dropping those to a
+// webhook that does not exist.
+func addDeliveries(tx *bolt.Tx, deliveries []Delivery) error {
+	hooks := tx.Bucket(webhooksBucket)
+	var kept []Delivery
+	for _, d := range deliveries {
+		if hooks.Get([]byte(d.WebhookID)) != nil {
+			kept = append(kept, d)
+		}
+	}
+	return putDeliveries(tx.Bucket(outboxBucket), kept)
+}
+
+// DueDeliveries returns up to limit pending deliveries due at now, oldest
+// first. Delivery IDs sort by creation, so a cursor scan gives that order.
+func (s *BBoltStore) DueDeliveries(ctx context.Context, now time.Time, limit int) (due []Delivery, err error) {
+	_, span := startSpan(ctx, "DueDeliveries")
+	defer func() {
+		span.SetAttributes(attribute.Int("delivery.count", len(due)))
+		endSpan(span, err)
+	}()
+
+	err = s.view(ctx, "listing due deliveries", func(tx *bolt.Tx) error {
+		c := tx.Bucket(outboxBucket).Cursor()
+		for k, v := c.First(); k != nil && len(due) < limit; k, v = c.Next() {
+			if err := ctxErr(ctx, "listing due deliveries"); err != nil {
+				return err
+			}
+			var d Delivery
+			if err := decodeJSON("delivery", string(k), v, &d); err != nil {
+				return err
+			}
+			if d.due(now) {
+				due = append(due, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// UpdateDelivery saves d after an attempt, or returns ErrNotFound when it was
+// deleted meanwhile.
+func (s *BBoltStore) UpdateDelivery(ctx context.Context, d Delivery) (err error) {
+	_, span := startSpan(ctx, "UpdateDelivery", attribute.String("delivery.id", d.ID))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, span, "updating delivery "+d.ID, func(tx *bolt.Tx) error {
+		b := tx.Bucket(outboxBucket)
+		if b.Get([]byte(d.ID)) == nil {
+			return fmt.Errorf("updating delivery %s: %w", d.ID, ErrNotFound)
+		}
+		return putDeliveries(b, []Delivery{d})
+	})
+}
+
+// ListDeliveries returns up to limit deliveries to the webhook, newest first.
+func (s *BBoltStore) ListDeliveries(ctx context.Context, webhookID string, limit int) (list []Delivery, err error) {
+	_, span := startSpan(ctx, "ListDeliveries", attribute.String("webhook.id", webhookID))
+	defer func() { endSpan(span, err) }()
+
+	err = s.view(ctx, "listing deliveries", func(tx *bolt.Tx) error {
+		c := tx.Bucket(outboxBucket).Cursor()
+		for k, v := c.Last(); k != nil && len(list) < limit; k, v = c.Prev() {
+			if err := ctxErr(ctx, "listing deliveries"); err != nil {
+				return err
+			}
+			var d Delivery
+			if err := decodeJSON("delivery", string(k), v, &d); err != nil {
+				return err
+			}
+			if d.WebhookID == webhookID {
+				list = append(list, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteDeliveries removes the finished deliveries last updated before
+// cutoff and returns how many there were.
+func (s *BBoltStore) DeleteDeliveries(ctx context.Context, cutoff time.Time) (deleted int, err error) {
+	_, span := startSpan(ctx, "DeleteDeliveries")
+	defer func() {
+		span.SetAttributes(attribute.Int("delivery.deleted", deleted))
+		endSpan(span, err)
+	}()
+
+	err = s.update(ctx, span, "deleting deliveries", func(tx *bolt.Tx) error {
+		n := 0
+		err := deleteDeliveriesWhere(tx.Bucket(outboxBucket), func(d Delivery) bool {
+			if d.finishedBefore(cutoff) {
+				n++
+				return true
+			}
+			return false
+		})
+		deleted = n
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func putDeliveries(b *bolt.Bucket, deliveries []Delivery) error {
+	for _, d := range deliveries {
+		data, err := encodeJSON("delivery", d.ID, d)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(d.ID), data); err != nil {
+			return storageErr("saving delivery "+d.ID, err)
+		}
+	}
+	return nil
+}
+
+// deleteDeliveriesWhere deletes the deliveries in b that match.
+func deleteDeliveriesWhere(b *bolt.Bucket, match func(Delivery) bool) error {
+	var keys [][]byte
+	err := b.ForEach(func(k, v []byte) error {
+		var d Delivery
+		if err := decodeJSON("delivery", string(k), v, &d); err != nil {
+			return err
+		}
+		if match(d) {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Reason: deleting while iterating makes a BBolt cursor skip keys
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return storageErr("deleting delivery "+string(k), err)
+		}
+	}
+	return nil
+}
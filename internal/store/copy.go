@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNotEmpty is returned by Copy when the destination already holds
+// invites or webhooks and replace was not requested.
+var ErrNotEmpty = errors.New("destination store is not empty")
+
+// Copied counts what Copy copied.
+type Copied struct {
+	Invites    int
+	Webhooks   int
+	Deliveries int
+}
+
+// Copy copies every invite, with its RSVP and view history, and every
+// webhook with its outbox and delivery log from src to dst. dst must be
+// empty unless replace is set, in which case its invites and webhooks are
+// replaced.
+//
+// Idempotency records are skipped on purpose: they only guard retries of
+// requests still in flight, and the server is stopped while stores are
+// copied.
+func Copy(ctx context.Context, dst, src Store, replace bool) (Copied, error) {
+	invites, err := src.GetAllInvites(ctx)
+	if err != nil {
+		return Copied{}, fmt.Errorf("reading source invites: %w", err)
+	}
+	hooks, err := src.ListWebhooks(ctx)
+	if err != nil {
+		return Copied{}, fmt.Errorf("reading source webhooks: %w", err)
+	}
+	var deliveries []Delivery
+	for _, w := range hooks {
+		list, err := src.ListDeliveries(ctx, w.ID, math.MaxInt32)
+		if err != nil {
+			return Copied{}, fmt.Errorf("reading source deliveries: %w", err)
+		}
+		deliveries = append(deliveries, list...)
+	}
+
+	existing, err := dst.GetAllInvites(ctx)
+	if err != nil {
+		return Copied{}, fmt.Errorf("reading destination invites: %w", err)
+	}
+	existingHooks, err := dst.ListWebhooks(ctx)
+	if err != nil {
+		return Copied{}, fmt.Errorf("reading destination webhooks: %w", err)
+	}
+	if !replace && (len(existing) > 0 || len(existingHooks) > 0) {
+		return Copied{}, fmt.Errorf("%w: %d invites, %d webhooks", ErrNotEmpty, len(existing), len(existingHooks))
+	}
+
+	if err := dst.ReplaceAllInvites(ctx, invites); err != nil {
+		return Copied{}, fmt.Errorf("writing destination invites: %w", err)
+	}
+	for _, w := range existingHooks {
+		if err := dst.DeleteWebhook(ctx, w.ID); err != nil {
+			return Copied{}, fmt.Errorf("deleting destination webhook %s: %w", w.ID, err)
+		}
+	}
+	for _, w := range hooks {
+		if err := dst.PutWebhook(ctx, w); err != nil {
+			return Copied{}, fmt.Errorf("writing destination webhook %s: %w", w.ID, err)
+		}
+	}
+	if err := dst.AddDeliveries(ctx, deliveries); err != nil {
+		return Copied{}, fmt.Errorf("writing destination deliveries: %w", err)
+	}
+	return Copied{Invites: len(invites), Webhooks: len(hooks), Deliveries: len(deliveries)}, nil
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCopy_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	open := func(driver, name string) Store {
+		t.Helper()
+		s, err := Open(driver, filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", driver, err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+
+	at := func(h int) *time.Time {
+		v := time.Date(2026, 5, 1, h, 0, 0, 0, time.UTC)
+		return &v
+	}
+	want := map[string]InviteRecord{
+		"a": {
+			People: []string{"Иван Петров", "Мария Петрова"}, AdditionalCount: 2, Additional: []string{"Георги"},
+			Accepted: true, AcceptedAt: at(3), Language: "en",
+			Views: Views{
+				Browser: ViewStats{Count: 5, First: at(1), Last: at(2)},
+				Bot:     ViewStats{Count: 1, First: at(1), Last: at(1)},
+				Recent:  []View{{At: *at(1), Bot: true}, {At: *at(2)}},
+			},
+		},
+		"b": {People: []string{"Борис Стоев"}, Declined: true},
+	}
+	hook := Webhook{
+		ID:        "w1",
+		URL:       "https://example.com/hook",
+		Secret:    "secret-w1-0123456789",
+		Events:    []string{"invite.accepted", "invite.viewed"},
+		CreatedAt: *at(0),
+	}
+	wantDeliveries := []Delivery{
+		{
+			ID: "d2", WebhookID: "w1", EventID: "e2", EventType: "invite.viewed", Payload: []byte(`{"id":"e2"}`),
+			State: DeliveryPending, Attempts: 1, NextAttemptAt: *at(5), LastError: "connection refused",
+			CreatedAt: *at(4), UpdatedAt: *at(4),
+		},
+		{
+			ID: "d1", WebhookID: "w1", EventID: "e1", EventType: "invite.accepted", Payload: []byte(`{"id":"e1"}`),
+			State: DeliveryDelivered, Attempts: 1, NextAttemptAt: *at(3), LastStatus: 204,
+			CreatedAt: *at(3), UpdatedAt: *at(3),
+		},
+	}
+
+	bbolt := open(DriverBBolt, "wedding.db")
+	if err := bbolt.ReplaceAllInvites(ctx, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bbolt.PutWebhook(ctx, hook); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bbolt.AddDeliveries(ctx, wantDeliveries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqlite := open(DriverSQLite, "wedding.sqlite")
+	copied, err := Copy(ctx, sqlite, bbolt, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if copied != (Copied{Invites: 2, Webhooks: 1, Deliveries: 2}) {
+		t.Fatalf("expected 2 invites, 1 webhook and 2 deliveries copied, got %+v", copied)
+	}
+	back := open(DriverBBolt, "back.db")
+	if _, err := Copy(ctx, back, sqlite, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, s := range map[string]Store{"sqlite": sqlite, "bbolt": back} {
+		got, err := s.GetAllInvites(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: expected %+v, got %+v", name, want, got)
+		}
+		hooks, err := s.ListWebhooks(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(hooks, []Webhook{hook}) {
+			t.Fatalf("%s: expected %+v, got %+v", name, hook, hooks)
+		}
+		deliveries, err := s.ListDeliveries(ctx, "w1", 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(deliveries, wantDeliveries) {
+			t.Fatalf("%s: expected %+v, got %+v", name, wantDeliveries, deliveries)
+		}
+	}
+
+	if _, err := Copy(ctx, sqlite, bbolt, false); !errors.Is(err, ErrNotEmpty) {
+		t.Fatalf("expected ErrNotEmpty, got %v", err)
+	}
+	if _, err := Copy(ctx, sqlite, open(DriverMemory, ""), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := sqlite.GetAllInvites(ctx); len(got) != 0 {
+		t.Fatalf("expected replace to empty the destination, got %d invites", len(got))
+	}
+	if hooks, _ := sqlite.ListWebhooks(ctx); len(hooks) != 0 {
+		t.Fatalf("expected replace to remove the destination's webhooks, got %+v", hooks)
+	}
+	if due, _ := sqlite.DueDeliveries(ctx, *at(23), 10); len(due) != 0 {
+		t.Fatalf("expected replace to remove the destination's deliveries, got %+v", due)
+	}
+}
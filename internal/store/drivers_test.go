@@ -0,0 +1,37 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+	"github.com/dimitarkovachev/wedding/internal/store/storetest"
+)
+
+func TestBBoltStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, opts ...store.Option) store.Store {
+		s, err := store.NewBBoltStore(filepath.Join(t.TempDir(), "test.db"), opts...)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, opts ...store.Option) store.Store {
+		return store.NewMemoryStore(opts...)
+	})
+}
+
+func TestSQLiteStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, opts ...store.Option) store.Store {
+		s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.sqlite"), opts...)
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// TestStores_CorruptRecord checks that a record the store cannot decode is
+// reported as ErrStorage, never as a missing invite.
+func TestStores_CorruptRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T) Store
+	}{
+		{"bbolt", func(t *testing.T) Store {
+			s := seedTestStore(t)
+			err := s.db.Update(func(tx *bolt.Tx) error {
+				return tx.Bucket(bucketName).Put([]byte("aaa-001"), []byte("{not json"))
+			})
+			if err != nil {
+				t.Fatalf("failed to corrupt record: %v", err)
+			}
+			return s
+		}},
+		{"memory", func(t *testing.T) Store {
+			s := NewMemoryStore()
+			s.invites["aaa-001"] = []byte("{not json")
+			return s
+		}},
+		{"sqlite", func(t *testing.T) Store {
+			s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.sqlite"))
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			t.Cleanup(func() { s.Close() })
+			_, err = s.db.Exec(`INSERT INTO invites (id, additional_count, accepted, accepted_at) VALUES ('aaa-001', 0, 1, 'yesterday')`)
+			if err != nil {
+				t.Fatalf("failed to corrupt record: %v", err)
+			}
+			return s
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.corrupt(t)
+			ctx := context.Background()
+
+			if _, err := s.GetInvite(ctx, "aaa-001"); !errors.Is(err, ErrStorage) || errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrStorage from GetInvite, got %v", err)
+			}
+			if _, err := s.UpdateInvite(ctx, "aaa-001", true, nil); !errors.Is(err, ErrStorage) {
+				t.Fatalf("expected ErrStorage from UpdateInvite, got %v", err)
+			}
+			if _, err := s.GetAllInvites(ctx); !errors.Is(err, ErrStorage) {
+				t.Fatalf("expected ErrStorage from GetAllInvites, got %v", err)
+			}
+		})
+	}
+}
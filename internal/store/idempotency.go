@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyRecord is the response saved for a request sent with an
+// Idempotency-Key, replayed when the same request is retried with that key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request the key was first used with, so
+	// reuse of the key for a different request can be refused.
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	// ContentLanguage is the language the body was written in, if the
+	// response named one.
+	ContentLanguage string    `json:"content_language,omitempty"`
+	Body            []byte    `json:"body"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// IdempotencyStore keeps IdempotencyRecords by key, apart from the invites:
+// replacing all invites leaves them alone.
+type IdempotencyStore interface {
+	// GetIdempotencyRecord returns the record saved under key, or
+	// ErrNotFound.
+	GetIdempotencyRecord(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// PutIdempotencyRecord saves rec under key, replacing any earlier record.
+	PutIdempotencyRecord(ctx context.Context, key string, rec IdempotencyRecord) error
+	// DeleteIdempotencyRecords removes the records created before cutoff and
+	// returns how many there were.
+	DeleteIdempotencyRecords(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// encodeJSON and decodeJSON give the JSON form BBoltStore and MemoryStore
+// keep idempotency records, webhooks and deliveries in; what and key name the
+// value in errors.
+func encodeJSON(what, key string, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, storageErr("marshaling "+what+" "+key, err)
+	}
+	return data, nil
+}
+
+func decodeJSON(what, key string, data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return storageErr("unmarshaling "+what+" "+key, err)
+	}
+	return nil
+}
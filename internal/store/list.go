@@ -0,0 +1,141 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/dimitarkovachev/wedding/internal/names"
+)
+
+// Status is the RSVP state of an invite as shown to admins.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusOpened   Status = "opened"
+	StatusAccepted Status = "accepted"
+	StatusDeclined Status = "declined"
+)
+
+// Status derives the invite's RSVP state: accepted and declined win over
+// opened, which means a guest's browser viewed the invite at least once.
+func (r InviteRecord) Status() Status {
+	switch {
+	case r.Accepted:
+		return StatusAccepted
+	case r.Declined:
+		return StatusDeclined
+	case r.Views.Opened():
+		return StatusOpened
+	default:
+		return StatusPending
+	}
+}
+
+// SortField selects the order of ListInvites results.
+type SortField string
+
+const (
+	SortByID         SortField = "id"
+	SortByName       SortField = "name"
+	SortByAcceptedAt SortField = "accepted_at"
+	SortByLastViewed SortField = "last_viewed"
+)
+
+// sortTimeLayout is fixed width so formatted times compare as strings.
+const sortTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// SortKey returns the value r is ordered by for field; empty means the
+// record has no value and sorts last.
+func (r InviteRecord) SortKey(id string, field SortField) string {
+	switch field {
+	case SortByName:
+		if len(r.People) == 0 {
+			return ""
+		}
+		return names.Key(r.People[0])
+	case SortByAcceptedAt:
+		if r.AcceptedAt == nil {
+			return ""
+		}
+		return r.AcceptedAt.UTC().Format(sortTimeLayout)
+	case SortByLastViewed:
+		if r.Views.Browser.Last == nil {
+			return ""
+		}
+		return r.Views.Browser.Last.UTC().Format(sortTimeLayout)
+	default:
+		return id
+	}
+}
+
+// Cursor is the position of the last invite on a page.
+type Cursor struct {
+	Value string
+	ID    string
+}
+
+// ListQuery selects one page of invites.
+type ListQuery struct {
+	// Filter, when set, keeps only the invites it returns true for.
+	Filter func(id string, r InviteRecord) bool
+	Sort   SortField
+	Desc   bool
+	// After continues from the previous page's Next cursor.
+	After *Cursor
+	Limit int
+}
+
+func (q ListQuery) keep(id string, r InviteRecord) bool {
+	return q.Filter == nil || q.Filter(id, r)
+}
+
+// InviteEntry is an invite together with its ID.
+type InviteEntry struct {
+	ID     string
+	Record InviteRecord
+}
+
+// ListPage is one page of ListInvites results. Next is nil on the last page.
+type ListPage struct {
+	Invites []InviteEntry
+	Next    *Cursor
+}
+
+// before reports whether a sorts before b in q's order. Records without a
+// sort value always come last; ties are broken by ID.
+func (q ListQuery) before(a, b Cursor) bool {
+	if (a.Value == "") != (b.Value == "") {
+		return b.Value == ""
+	}
+	if a.Value != b.Value {
+		return (a.Value < b.Value) != q.Desc
+	}
+	if a.ID == b.ID {
+		return false
+	}
+	return (a.ID < b.ID) != q.Desc
+}
+
+// sortedPage orders the already filtered entries and cuts the page after
+// q.After.
+func sortedPage(entries []InviteEntry, q ListQuery) *ListPage {
+	keys := make(map[string]Cursor, len(entries))
+	for _, e := range entries {
+		keys[e.ID] = Cursor{Value: e.Record.SortKey(e.ID, q.Sort), ID: e.ID}
+	}
+	sort.Slice(entries, func(i, j int) bool { return q.before(keys[entries[i].ID], keys[entries[j].ID]) })
+
+	start := 0
+	if q.After != nil {
+		start = sort.Search(len(entries), func(i int) bool { return q.before(*q.After, keys[entries[i].ID]) })
+	}
+	entries = entries[start:]
+
+	page := &ListPage{Invites: entries}
+	if len(entries) > q.Limit {
+		page.Invites = entries[:q.Limit]
+		last := keys[page.Invites[q.Limit-1].ID]
+		page.Next = &last
+	}
+	return page
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInviteRecord_Status(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		rec  InviteRecord
+		want Status
+	}{
+		{"pending", InviteRecord{}, StatusPending},
+		{"viewed by a bot", InviteRecord{Views: Views{Bot: ViewStats{Count: 1}}}, StatusPending},
+		{"opened", InviteRecord{Views: viewed(now)}, StatusOpened},
+		{"declined", InviteRecord{Declined: true, Views: viewed(now)}, StatusDeclined},
+		{"accepted", InviteRecord{Accepted: true, Views: viewed(now)}, StatusAccepted},
+	}
+	for _, tt := range tests {
+		if got := tt.rec.Status(); got != tt.want {
+			t.Fatalf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MemoryStore keeps invites in memory, for tests, demos and running without
+// a writable disk; everything is lost when the process exits. Records are
+// held JSON-encoded exactly as BBoltStore stores them, so both behave the
+// same and callers never share memory with the store.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	invites    map[string][]byte
+	keys       map[string][]byte
+	webhooks   map[string][]byte
+	deliveries map[string][]byte
+	views      ViewPolicy
+	deadline   time.Time
+}
+
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	o := newOptions(opts)
+	return &MemoryStore{
+		invites:    make(map[string][]byte),
+		keys:       make(map[string][]byte),
+		webhooks:   make(map[string][]byte),
+		deliveries: make(map[string][]byte),
+		views:      o.views,
+		deadline:   o.deadline,
+	}
+}
+
+// load decodes the invite, or returns nil when it does not exist. The caller
+// holds mu.
+func (s *MemoryStore) load(id string) (*InviteRecord, error) {
+	data, ok := s.invites[id]
+	if !ok {
+		return nil, nil
+	}
+	r, err := decodeInvite([]byte(id), data)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// put encodes rec under id. The caller holds mu for writing.
+func (s *MemoryStore) put(id string, rec InviteRecord) error {
+	data, err := encodeInvite(id, rec)
+	if err != nil {
+		return err
+	}
+	s.invites[id] = data
+	return nil
+}
+
+func (s *MemoryStore) GetInvite(ctx context.Context, id string) (*InviteRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "getting invite "+id); err != nil {
+		return nil, err
+	}
+	r, err := s.load(id)
+	if err == nil && r == nil {
+		return nil, fmt.Errorf("getting invite %s: %w", id, ErrNotFound)
+	}
+	return r, err
+}
+
+func (s *MemoryStore) RecordView(ctx context.Context, id string, view View) error {
+	_, err := s.RecordViews(ctx, []ViewEvent{{ID: id, View: view}})
+	return err
+}
+
+func (s *MemoryStore) RecordViews(ctx context.Context, events []ViewEvent) ([]ViewEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "recording views"); err != nil {
+		return nil, err
+	}
+
+	changed, counted, err := applyViews(events, s.views, s.load)
+	if err != nil {
+		return nil, err
+	}
+	for id, r := range changed {
+		if err := s.put(id, *r); err != nil {
+			return nil, err
+		}
+	}
+	return counted, nil
+}
+
+func (s *MemoryStore) UpdateInvite(ctx context.Context, id string, accepted bool, additional []string) (*InviteRecord, error) {
+	return s.UpdateInviteWithOutbox(ctx, id, accepted, additional, nil)
+}
+
+// UpdateInviteWithOutbox is UpdateInvite that also puts the deliveries
+// outbox returns for the change in the outbox.
+func (s *MemoryStore) UpdateInviteWithOutbox(ctx context.Context, id string, accepted bool, additional []string, outbox OutboxFunc) (*InviteRecord, error) {
+	return s.edit(ctx, "updating invite "+id, id, func(r *InviteRecord) error {
+		return accept(r, accepted, additional, time.Now().UTC(), s.deadline)
+	}, outbox)
+}
+
+// Seed loads invite records from a map, skipping keys that already exist.
+func (s *MemoryStore) Seed(ctx context.Context, invites map[string]InviteRecord) error {
+	encoded, err := encodeAll(ctx, "seeding invites", invites)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "seeding invites"); err != nil {
+		return err
+	}
+	for id, data := range encoded {
+		if _, ok := s.invites[id]; ok {
+			log.WithField("id", id).Debug("seed: invite already exists, skipping")
+			continue
+		}
+		s.invites[id] = data
+		log.WithField("id", id).Info("seeded invite")
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetAllInvites(ctx context.Context) (map[string]InviteRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "getting invites"); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]InviteRecord, len(s.invites))
+	for id := range s.invites {
+		if err := ctxErr(ctx, "getting invites"); err != nil {
+			return nil, err
+		}
+		r, err := s.load(id)
+		if err != nil {
+			return nil, err
+		}
+		result[id] = *r
+	}
+	return result, nil
+}
+
+// ListInvites returns one page of invites matching q, sorting all matches.
+func (s *MemoryStore) ListInvites(ctx context.Context, q ListQuery) (*ListPage, error) {
+	if q.Limit <= 0 {
+		return nil, fmt.Errorf("list limit must be positive, got %d", q.Limit)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "listing invites"); err != nil {
+		return nil, err
+	}
+
+	var entries []InviteEntry
+	for id := range s.invites {
+		if err := ctxErr(ctx, "listing invites"); err != nil {
+			return nil, err
+		}
+		r, err := s.load(id)
+		if err != nil {
+			return nil, err
+		}
+		if q.keep(id, *r) {
+			entries = append(entries, InviteEntry{ID: id, Record: *r})
+		}
+	}
+	return sortedPage(entries, q), nil
+}
+
+// CreateInvite stores a new invite, failing with ErrInviteExists if the ID
+// is taken.
+func (s *MemoryStore) CreateInvite(ctx context.Context, id string, rec InviteRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "creating invite "+id); err != nil {
+		return err
+	}
+
+	if _, ok := s.invites[id]; ok {
+		return fmt.Errorf("creating invite %s: %w", id, ErrInviteExists)
+	}
+	return s.put(id, rec)
+}
+
+// EditInvite applies edit to the stored invite and returns the saved record,
+// or ErrNotFound when the invite does not exist; an error from edit aborts
+// the change and is returned as it is.
+func (s *MemoryStore) EditInvite(ctx context.Context, id string, edit func(*InviteRecord) error) (*InviteRecord, error) {
+	return s.EditInviteWithOutbox(ctx, id, edit, nil)
+}
+
+// EditInviteWithOutbox is EditInvite that also puts the deliveries outbox
+// returns for the change in the outbox.
+func (s *MemoryStore) EditInviteWithOutbox(ctx context.Context, id string, edit func(*InviteRecord) error, outbox OutboxFunc) (*InviteRecord, error) {
+	return s.edit(ctx, "editing invite "+id, id, edit, outbox)
+}
+
+// edit applies edit to the stored invite and saves it together with the
+// deliveries from outbox, which may be nil.
+func (s *MemoryStore) edit(ctx context.Context, op, id string, edit func(*InviteRecord) error, outbox OutboxFunc) (*InviteRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, op); err != nil {
+		return nil, err
+	}
+
+	r, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+	before := r.clone()
+	if err := edit(r); err != nil {
+		return nil, err
+	}
+	var deliveries []encodedDelivery
+	if outbox != nil {
+		raised, err := outbox(id, before, *r)
+		if err != nil {
+			return nil, err
+		}
+		if deliveries, err = encodeDeliveries(raised); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.put(id, *r); err != nil {
+		return nil, err
+	}
+	s.addDeliveries(deliveries)
+	return r, nil
+}
+
+// DeleteInvite removes the invite, or returns ErrNotFound when it does not
+// exist.
+func (s *MemoryStore) DeleteInvite(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "deleting invite "+id); err != nil {
+		return err
+	}
+	if _, ok := s.invites[id]; !ok {
+		return fmt.Errorf("deleting invite %s: %w", id, ErrNotFound)
+	}
+	delete(s.invites, id)
+	return nil
+}
+
+func (s *MemoryStore) ReplaceAllInvites(ctx context.Context, invites map[string]InviteRecord) error {
+	replaced, err := encodeAll(ctx, "replacing invites", invites)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "replacing invites"); err != nil {
+		return err
+	}
+	s.invites = replaced
+	return nil
+}
+
+// GetIdempotencyRecord returns the record saved under key, or ErrNotFound.
+func (s *MemoryStore) GetIdempotencyRecord(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "getting idempotency record"); err != nil {
+		return nil, err
+	}
+	data, ok := s.keys[key]
+	if !ok {
+		return nil, fmt.Errorf("getting idempotency record %s: %w", key, ErrNotFound)
+	}
+	var rec IdempotencyRecord
+	if err := decodeJSON("idempotency record", key, data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// PutIdempotencyRecord saves rec under key, replacing any earlier record.
+func (s *MemoryStore) PutIdempotencyRecord(ctx context.Context, key string, rec IdempotencyRecord) error {
+	data, err := encodeJSON("idempotency record", key, rec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "saving idempotency record"); err != nil {
+		return err
+	}
+	s.keys[key] = data
+	return nil
+}
+
+// DeleteIdempotencyRecords removes the records created before cutoff.
+func (s *MemoryStore) DeleteIdempotencyRecords(ctx context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "deleting idempotency records"); err != nil {
+		return 0, err
+	}
+
+	var expired []string
+	for key, data := range s.keys {
+		if err := ctxErr(ctx, "deleting idempotency records"); err != nil {
+			return 0, err
+		}
+		var rec IdempotencyRecord
+		if err := decodeJSON("idempotency record", key, data, &rec); err != nil {
+			return 0, err
+		}
+		if rec.CreatedAt.Before(cutoff) {
+			expired = append(expired, key)
+		}
+	}
+	// Reason: delete only once every record decoded, so a failure changes nothing
+	for _, key := range expired {
+		delete(s.keys, key)
+	}
+	return len(expired), nil
+}
+
+// encodeAll encodes invites ahead of taking the lock, checking ctx between
+// records.
+func encodeAll(ctx context.Context, op string, invites map[string]InviteRecord) (map[string][]byte, error) {
+	encoded := make(map[string][]byte, len(invites))
+	for id, rec := range invites {
+		if err := ctxErr(ctx, op); err != nil {
+			return nil, err
+		}
+		data, err := encodeInvite(id, rec)
+		if err != nil {
+			return nil, err
+		}
+		encoded[id] = data
+	}
+	return encoded, nil
+}
+
+// Close does nothing; the data lives as long as the MemoryStore.
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,222 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ListWebhooks returns every webhook in ID order.
+func (s *MemoryStore) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "listing webhooks"); err != nil {
+		return nil, err
+	}
+	var hooks []Webhook
+	for _, id := range slices.Sorted(maps.Keys(s.webhooks)) {
+		var w Webhook
+		if err := decodeJSON("webhook", id, s.webhooks[id], &w); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, nil
+}
+
+// GetWebhook returns the webhook, or ErrNotFound.
+func (s *MemoryStore) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "getting webhook "+id); err != nil {
+		return nil, err
+	}
+	data, ok := s.webhooks[id]
+	if !ok {
+		return nil, fmt.Errorf("getting webhook %s: %w", id, ErrNotFound)
+	}
+	var w Webhook
+	if err := decodeJSON("webhook", id, data, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// PutWebhook saves w under w.ID, replacing any earlier version.
+func (s *MemoryStore) PutWebhook(ctx context.Context, w Webhook) error {
+	data, err := encodeJSON("webhook", w.ID, w)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "saving webhook "+w.ID); err != nil {
+		return err
+	}
+	s.webhooks[w.ID] = data
+	return nil
+}
+
+// DeleteWebhook removes the webhook and its deliveries, or returns
+// ErrNotFound.
+func (s *MemoryStore) DeleteWebhook(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "deleting webhook "+id); err != nil {
+		return err
+	}
+	if _, ok := s.webhooks[id]; !ok {
+		return fmt.Errorf("deleting webhook %s: %w", id, ErrNotFound)
+	}
+	matches, err := s.deliveriesWhere(func(d Delivery) bool { return d.WebhookID == id })
+	if err != nil {
+		return err
+	}
+	delete(s.webhooks, id)
+	for _, d := range matches {
+		delete(s.deliveries, d.ID)
+	}
+	return nil
+}
+
+// AddDeliveries puts deliveries in the outbox, all or none, dropping those to
+// a webhook deleted meanwhile.
+func (s *MemoryStore) AddDeliveries(ctx context.Context, deliveries []Delivery) error {
+	encoded, err := encodeDeliveries(deliveries)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "adding deliveries"); err != nil {
+		return err
+	}
+	s.addDeliveries(encoded)
+	return nil
+}
+
+// encodedDelivery is a delivery encoded for the outbox.
+type encodedDelivery struct {
+	id        string
+	webhookID string
+	data      []byte
+}
+
+func encodeDeliveries(deliveries []Delivery) ([]encodedDelivery, error) {
+	encoded := make([]encodedDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		data, err := encodeJSON("delivery", d.ID, d)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, encodedDelivery{id: d.ID, webhookID: d.WebhookID, data: data})
+	}
+	return encoded, nil
+}
+
+// addDeliveries puts encoded deliveries in the outbox, dropping those to a
+// webhook that does not exist. The caller holds mu for writing.
+func (s *MemoryStore) addDeliveries(encoded []encodedDelivery) {
+	for _, d := range encoded {
+		if _, ok := s.webhooks[d.webhookID]; ok {
+			s.deliveries[d.id] = d.data
+		}
+	}
+}
+
+// DueDeliveries returns up to limit pending deliveries due at now, oldest
+// first.
+func (s *MemoryStore) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "listing due deliveries"); err != nil {
+		return nil, err
+	}
+	due, err := s.deliveriesWhere(func(d Delivery) bool { return d.due(now) })
+	if err != nil {
+		return nil, err
+	}
+	return due[:min(limit, len(due))], nil
+}
+
+// UpdateDelivery saves d after an attempt, or returns ErrNotFound when it was
+// deleted meanwhile.
+func (s *MemoryStore) UpdateDelivery(ctx context.Context, d Delivery) error {
+	data, err := encodeJSON("delivery", d.ID, d)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "updating delivery "+d.ID); err != nil {
+		return err
+	}
+	if _, ok := s.deliveries[d.ID]; !ok {
+		return fmt.Errorf("updating delivery %s: %w", d.ID, ErrNotFound)
+	}
+	s.deliveries[d.ID] = data
+	return nil
+}
+
+// ListDeliveries returns up to limit deliveries to the webhook, newest first.
+func (s *MemoryStore) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctxErr(ctx, "listing deliveries"); err != nil {
+		return nil, err
+	}
+	list, err := s.deliveriesWhere(func(d Delivery) bool { return d.WebhookID == webhookID })
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(list)
+	return list[:min(limit, len(list))], nil
+}
+
+// DeleteDeliveries removes the finished deliveries last updated before
+// cutoff and returns how many there were.
+func (s *MemoryStore) DeleteDeliveries(ctx context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctxErr(ctx, "deleting deliveries"); err != nil {
+		return 0, err
+	}
+	old, err := s.deliveriesWhere(func(d Delivery) bool { return d.finishedBefore(cutoff) })
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range old {
+		delete(s.deliveries, d.ID)
+	}
+	return len(old), nil
+}
+
+// deliveriesWhere decodes the deliveries that match, in ID order. The caller
+// holds mu.
+func (s *MemoryStore) deliveriesWhere(match func(Delivery) bool) ([]Delivery, error) {
+	var list []Delivery
+	for _, id := range slices.Sorted(maps.Keys(s.deliveries)) {
+		var d Delivery
+		if err := decodeJSON("delivery", id, s.deliveries[id], &d); err != nil {
+			return nil, err
+		}
+		if match(d) {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
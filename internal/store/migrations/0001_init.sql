@@ -0,0 +1,43 @@
+-- Times are UTC text in a fixed-width layout (2006-01-02T15:04:05.000000000Z)
+-- so they sort and compare as strings; booleans are 0 or 1.
+
+CREATE TABLE invites (
+    id                 TEXT PRIMARY KEY,
+    additional_count   INTEGER NOT NULL DEFAULT 0,
+    accepted           INTEGER NOT NULL DEFAULT 0 CHECK (accepted IN (0, 1)),
+    accepted_at        TEXT,
+    declined           INTEGER NOT NULL DEFAULT 0 CHECK (declined IN (0, 1)),
+    language           TEXT NOT NULL DEFAULT '',
+    browser_views      INTEGER NOT NULL DEFAULT 0,
+    first_browser_view TEXT,
+    last_browser_view  TEXT,
+    bot_views          INTEGER NOT NULL DEFAULT 0,
+    first_bot_view     TEXT,
+    last_bot_view      TEXT
+);
+
+-- The people the invite is addressed to, in order.
+CREATE TABLE people (
+    invite_id TEXT NOT NULL REFERENCES invites (id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    name      TEXT NOT NULL,
+    PRIMARY KEY (invite_id, position)
+);
+
+-- The additional guests named when the invite was accepted, in order.
+CREATE TABLE additional_guests (
+    invite_id TEXT NOT NULL REFERENCES invites (id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    name      TEXT NOT NULL,
+    PRIMARY KEY (invite_id, position)
+);
+
+-- The most recent views of each invite, oldest first; the totals and
+-- first/last times of all views are kept on invites.
+CREATE TABLE views (
+    invite_id TEXT NOT NULL REFERENCES invites (id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    at        TEXT NOT NULL,
+    bot       INTEGER NOT NULL DEFAULT 0 CHECK (bot IN (0, 1)),
+    PRIMARY KEY (invite_id, position)
+);
@@ -0,0 +1,14 @@
+-- Responses saved for requests sent with an Idempotency-Key, replayed when
+-- the request is retried. created_at uses the same fixed-width layout as the
+-- invite times, so expired keys are found with a plain comparison.
+
+CREATE TABLE idempotency_keys (
+    key          TEXT PRIMARY KEY,
+    fingerprint  TEXT NOT NULL,
+    status       INTEGER NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    body         BLOB,
+    created_at   TEXT NOT NULL
+);
+
+CREATE INDEX idempotency_keys_created_at ON idempotency_keys (created_at);
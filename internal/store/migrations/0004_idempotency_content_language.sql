@@ -0,0 +1,3 @@
+-- The Content-Language of a saved response, replayed with it.
+
+ALTER TABLE idempotency_keys ADD COLUMN content_language TEXT NOT NULL DEFAULT '';
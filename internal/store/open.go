@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Store is the full set of operations every storage driver implements.
+type Store interface {
+	InviteStore
+	ViewWriter
+	IdempotencyStore
+	WebhookStore
+	Seed(ctx context.Context, invites map[string]InviteRecord) error
+	GetAllInvites(ctx context.Context) (map[string]InviteRecord, error)
+	ListInvites(ctx context.Context, q ListQuery) (*ListPage, error)
+	CreateInvite(ctx context.Context, id string, rec InviteRecord) error
+	EditInvite(ctx context.Context, id string, edit func(*InviteRecord) error) (*InviteRecord, error)
+	// UpdateInviteWithOutbox and EditInviteWithOutbox are UpdateInvite and
+	// EditInvite that also put the deliveries outbox returns for the change
+	// in the outbox, in the same transaction.
+	UpdateInviteWithOutbox(ctx context.Context, id string, accepted bool, additional []string, outbox OutboxFunc) (*InviteRecord, error)
+	EditInviteWithOutbox(ctx context.Context, id string, edit func(*InviteRecord) error, outbox OutboxFunc) (*InviteRecord, error)
+	DeleteInvite(ctx context.Context, id string) error
+	ReplaceAllInvites(ctx context.Context, invites map[string]InviteRecord) error
+}
+
+var (
+	_ Store = (*BBoltStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*SQLiteStore)(nil)
+)
+
+// Storage drivers accepted by Open.
+const (
+	DriverBBolt  = "bbolt"
+	DriverMemory = "memory"
+	DriverSQLite = "sqlite"
+)
+
+// MemoryPath as the path selects the memory driver when none is named.
+const MemoryPath = ":memory:"
+
+// Option configures a store.
+type Option func(*options)
+
+type options struct {
+	views    ViewPolicy
+	deadline time.Time
+}
+
+// WithViewPolicy replaces DefaultViewPolicy.
+func WithViewPolicy(p ViewPolicy) Option {
+	return func(o *options) { o.views = p }
+}
+
+// WithRSVPDeadline makes UpdateInvite fail with ErrDeadlinePassed after t.
+// Admin edits are not affected.
+func WithRSVPDeadline(t time.Time) Option {
+	return func(o *options) { o.deadline = t }
+}
+
+func newOptions(opts []Option) options {
+	o := options{views: DefaultViewPolicy}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// ResolveDriver returns the driver Open uses: driver itself when set,
+// otherwise bbolt, or memory when path is MemoryPath.
+func ResolveDriver(driver, path string) string {
+	if driver != "" {
+		return driver
+	}
+	if path == MemoryPath {
+		return DriverMemory
+	}
+	return DriverBBolt
+}
+
+// Open opens the store for driver at path; see ResolveDriver for an empty
+// driver.
+func Open(driver, path string, opts ...Option) (Store, error) {
+	switch driver = ResolveDriver(driver, path); driver {
+	case DriverBBolt:
+		return NewBBoltStore(path, opts...)
+	case DriverMemory:
+		return NewMemoryStore(opts...), nil
+	case DriverSQLite:
+		return NewSQLiteStore(path, opts...)
+	default:
+		return nil, fmt.Errorf("unknown store driver %q", driver)
+	}
+}
@@ -0,0 +1,32 @@
+package store
+
+import "testing"
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		driver, path string
+		want         string
+	}{
+		{"", MemoryPath, DriverMemory},
+		{DriverMemory, "ignored.db", DriverMemory},
+		{"", "test.db", DriverBBolt},
+		{DriverBBolt, "test.db", DriverBBolt},
+	}
+	for _, tt := range tests {
+		if got := ResolveDriver(tt.driver, tt.path); got != tt.want {
+			t.Fatalf("ResolveDriver(%q, %q): expected %s, got %s", tt.driver, tt.path, tt.want, got)
+		}
+	}
+
+	s, err := Open("", MemoryPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := s.(*MemoryStore); !ok {
+		t.Fatalf("expected MemoryStore, got %T", s)
+	}
+
+	if _, err := Open("postgres", "db"); err == nil {
+		t.Fatal("expected error for unknown driver")
+	}
+}
@@ -0,0 +1,407 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	// Reason: pure-Go driver, so the server still builds with CGO_ENABLED=0
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps invites in a relational SQLite schema (invites, people,
+// additional_guests, views) that can be queried with plain SQL. Records are
+// read whole, changed with the same code as the other stores and written
+// back, so it behaves exactly like them.
+type SQLiteStore struct {
+	db       *sql.DB
+	views    ViewPolicy
+	deadline time.Time
+}
+
+// sqliteParams enable foreign keys for the ON DELETE CASCADE rules, let
+// readers work alongside the writer (WAL), wait for locks instead of failing,
+// and take the write lock when a read-write transaction begins, so two
+// transactions never deadlock upgrading their read locks.
+const sqliteParams = "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+
+// NewSQLiteStore opens the SQLite database at path, creating it if needed,
+// and applies pending migrations.
+func NewSQLiteStore(path string, opts ...Option) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path+sqliteParams)
+	if err != nil {
+		return nil, storageErr("opening sqlite db at "+path, err)
+	}
+	// Reason: SQLite has a single writer; extra connections only spin in the
+	// busy handler, while database/sql queues callers fairly
+	db.SetMaxOpenConns(4)
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, storageErr("migrating sqlite db at "+path, err)
+	}
+	o := newOptions(opts)
+	return &SQLiteStore{db: db, views: o.views, deadline: o.deadline}, nil
+}
+
+// read runs fn in a read-only transaction, so it sees one consistent state
+// across the tables.
+func (s *SQLiteStore) read(ctx context.Context, op string, fn func(tx *sql.Tx) error) error {
+	if err := ctxErr(ctx, op); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return storageErr(op, err)
+	}
+	defer tx.Rollback()
+	return queryErr(ctx, op, fn(tx))
+}
+
+// write runs fn in a read-write transaction and commits it unless fn fails.
+// ctx is checked again once the write lock is held.
+func (s *SQLiteStore) write(ctx context.Context, op string, fn func(tx *sql.Tx) error) error {
+	if err := ctxErr(ctx, op); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return storageErr(op, err)
+	}
+	defer tx.Rollback()
+
+	if err := ctxErr(ctx, op); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return queryErr(ctx, op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return queryErr(ctx, op, storageErr(op, err))
+	}
+	return nil
+}
+
+// queryErr reports a query that failed because ctx ended as ctx's error
+// rather than a storage failure.
+func queryErr(ctx context.Context, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctxErr(ctx, op); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// loadInvite returns the invite, or nil when it does not exist.
+func loadInvite(ctx context.Context, q querier, id string) (*InviteRecord, error) {
+	invites, err := readInvites(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	return invites[id], nil
+}
+
+// GetInvite returns the invite, or ErrNotFound when it does not exist. It
+// does not record a view; see RecordView.
+func (s *SQLiteStore) GetInvite(ctx context.Context, id string) (record *InviteRecord, err error) {
+	err = s.read(ctx, "getting invite "+id, func(tx *sql.Tx) error {
+		if record, err = loadInvite(ctx, tx, id); err == nil && record == nil {
+			return fmt.Errorf("getting invite %s: %w", id, ErrNotFound)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// RecordView adds view to the invite's history under the store's
+// ViewPolicy. Views deduplicated by the policy, and views of missing
+// invites, are dropped without a write.
+func (s *SQLiteStore) RecordView(ctx context.Context, id string, view View) error {
+	// Reason: most repeat views are deduplicated, so check under a read
+	// transaction first rather than queueing every view for the write lock
+	rec, err := s.GetInvite(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil || !rec.Views.record(view, s.views) {
+		return err
+	}
+	_, err = s.RecordViews(ctx, []ViewEvent{{ID: id, View: view}})
+	return err
+}
+
+// RecordViews applies a batch of views in one transaction, under the same
+// ViewPolicy as RecordView, and returns the views that were counted. Views
+// of missing invites are skipped.
+func (s *SQLiteStore) RecordViews(ctx context.Context, events []ViewEvent) (counted []ViewEvent, err error) {
+	err = s.write(ctx, "recording views", func(tx *sql.Tx) error {
+		changed, n, err := applyViews(events, s.views, func(id string) (*InviteRecord, error) {
+			return loadInvite(ctx, tx, id)
+		})
+		if err != nil {
+			return err
+		}
+		for id, r := range changed {
+			if err := writeInvite(ctx, tx, id, *r); err != nil {
+				return err
+			}
+		}
+		counted = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counted, nil
+}
+
+func (s *SQLiteStore) UpdateInvite(ctx context.Context, id string, accepted bool, additional []string) (*InviteRecord, error) {
+	return s.UpdateInviteWithOutbox(ctx, id, accepted, additional, nil)
+}
+
+// UpdateInviteWithOutbox is UpdateInvite that also puts the deliveries
+// outbox returns for the change in the outbox, in the same transaction.
+func (s *SQLiteStore) UpdateInviteWithOutbox(ctx context.Context, id string, accepted bool, additional []string, outbox OutboxFunc) (*InviteRecord, error) {
+	return s.edit(ctx, "updating invite "+id, id, func(r *InviteRecord) error {
+		return accept(r, accepted, additional, time.Now().UTC(), s.deadline)
+	}, outbox)
+}
+
+// Seed loads invite records from a map, skipping keys that already exist.
+func (s *SQLiteStore) Seed(ctx context.Context, invites map[string]InviteRecord) error {
+	return s.write(ctx, "seeding invites", func(tx *sql.Tx) error {
+		for id, rec := range invites {
+			if err := ctxErr(ctx, "seeding invites"); err != nil {
+				return err
+			}
+			existing, err := loadInvite(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				log.WithField("id", id).Debug("seed: invite already exists, skipping")
+				continue
+			}
+			if err := writeInvite(ctx, tx, id, rec); err != nil {
+				return fmt.Errorf("seeding invite %s: %w", id, err)
+			}
+			log.WithField("id", id).Info("seeded invite")
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) GetAllInvites(ctx context.Context) (map[string]InviteRecord, error) {
+	var result map[string]InviteRecord
+	err := s.read(ctx, "getting invites", func(tx *sql.Tx) error {
+		invites, err := readInvites(ctx, tx, "")
+		if err != nil {
+			return err
+		}
+		result = make(map[string]InviteRecord, len(invites))
+		for id, r := range invites {
+			result[id] = *r
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListInvites returns one page of invites matching q, sorting all matches.
+func (s *SQLiteStore) ListInvites(ctx context.Context, q ListQuery) (*ListPage, error) {
+	if q.Limit <= 0 {
+		return nil, fmt.Errorf("list limit must be positive, got %d", q.Limit)
+	}
+
+	var entries []InviteEntry
+	err := s.read(ctx, "listing invites", func(tx *sql.Tx) error {
+		invites, err := readInvites(ctx, tx, "")
+		if err != nil {
+			return err
+		}
+		for id, r := range invites {
+			if q.keep(id, *r) {
+				entries = append(entries, InviteEntry{ID: id, Record: *r})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedPage(entries, q), nil
+}
+
+// CreateInvite stores a new invite, failing with ErrInviteExists if the ID
+// is taken.
+func (s *SQLiteStore) CreateInvite(ctx context.Context, id string, rec InviteRecord) error {
+	return s.write(ctx, "creating invite "+id, func(tx *sql.Tx) error {
+		existing, err := loadInvite(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("creating invite %s: %w", id, ErrInviteExists)
+		}
+		return writeInvite(ctx, tx, id, rec)
+	})
+}
+
+// EditInvite applies edit to the stored invite in one transaction, so views
+// recorded meanwhile are not lost, and returns the saved record. It returns
+// ErrNotFound when the invite does not exist; an error from edit aborts the
+// change and is returned as it is.
+func (s *SQLiteStore) EditInvite(ctx context.Context, id string, edit func(*InviteRecord) error) (*InviteRecord, error) {
+	return s.EditInviteWithOutbox(ctx, id, edit, nil)
+}
+
+// EditInviteWithOutbox is EditInvite that also puts the deliveries outbox
+// returns for the change in the outbox, in the same transaction.
+func (s *SQLiteStore) EditInviteWithOutbox(ctx context.Context, id string, edit func(*InviteRecord) error, outbox OutboxFunc) (*InviteRecord, error) {
+	return s.edit(ctx, "editing invite "+id, id, edit, outbox)
+}
+
+// edit applies edit to the invite and saves it together with the deliveries
+// from outbox, which may be nil.
+func (s *SQLiteStore) edit(ctx context.Context, op, id string, edit func(*InviteRecord) error, outbox OutboxFunc) (record *InviteRecord, err error) {
+	err = s.write(ctx, op, func(tx *sql.Tx) error {
+		r, err := loadInvite(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if r == nil {
+			return fmt.Errorf("%s: %w", op, ErrNotFound)
+		}
+		before := r.clone()
+		if err := edit(r); err != nil {
+			return err
+		}
+		if err := writeInvite(ctx, tx, id, *r); err != nil {
+			return err
+		}
+		if outbox != nil {
+			deliveries, err := outbox(id, before, *r)
+			if err != nil {
+				return err
+			}
+			if err := insertDeliveries(ctx, tx, deliveries); err != nil {
+				return err
+			}
+		}
+		record = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// DeleteInvite removes the invite, or returns ErrNotFound when it does not
+// exist. Its child rows go with it through ON DELETE CASCADE.
+func (s *SQLiteStore) DeleteInvite(ctx context.Context, id string) error {
+	return s.write(ctx, "deleting invite "+id, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM invites WHERE id = ?`, id)
+		if err != nil {
+			return storageErr("deleting invite "+id, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return storageErr("deleting invite "+id, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("deleting invite %s: %w", id, ErrNotFound)
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStore) ReplaceAllInvites(ctx context.Context, invites map[string]InviteRecord) error {
+	return s.write(ctx, "replacing invites", func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM invites`); err != nil {
+			return storageErr("deleting invites", err)
+		}
+		for id, rec := range invites {
+			if err := ctxErr(ctx, "replacing invites"); err != nil {
+				return err
+			}
+			if err := writeInvite(ctx, tx, id, rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetIdempotencyRecord returns the record saved under key, or ErrNotFound.
+func (s *SQLiteStore) GetIdempotencyRecord(ctx context.Context, key string) (rec *IdempotencyRecord, err error) {
+	err = s.read(ctx, "getting idempotency record", func(tx *sql.Tx) error {
+		var r IdempotencyRecord
+		var createdAt string
+		err := tx.QueryRowContext(ctx,
+			`SELECT fingerprint, status, content_type, content_language, body, created_at FROM idempotency_keys WHERE key = ?`, key,
+		).Scan(&r.Fingerprint, &r.Status, &r.ContentType, &r.ContentLanguage, &r.Body, &createdAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("getting idempotency record %s: %w", key, ErrNotFound)
+		}
+		if err != nil {
+			return storageErr("getting idempotency record "+key, err)
+		}
+		if r.CreatedAt, err = parseTime(createdAt); err != nil {
+			return storageErr("getting idempotency record "+key, err)
+		}
+		rec = &r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// PutIdempotencyRecord saves rec under key, replacing any earlier record.
+func (s *SQLiteStore) PutIdempotencyRecord(ctx context.Context, key string, rec IdempotencyRecord) error {
+	return s.write(ctx, "saving idempotency record", func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO idempotency_keys
+			(key, fingerprint, status, content_type, content_language, body, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			key, rec.Fingerprint, rec.Status, rec.ContentType, rec.ContentLanguage, rec.Body, formatTime(rec.CreatedAt))
+		if err != nil {
+			return storageErr("saving idempotency record "+key, err)
+		}
+		return nil
+	})
+}
+
+// DeleteIdempotencyRecords removes the records created before cutoff.
+func (s *SQLiteStore) DeleteIdempotencyRecords(ctx context.Context, cutoff time.Time) (deleted int, err error) {
+	err = s.write(ctx, "deleting idempotency records", func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < ?`, formatTime(cutoff))
+		if err != nil {
+			return storageErr("deleting idempotency records", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return storageErr("deleting idempotency records", err)
+		}
+		deleted = int(n)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// querier is the part of *sql.DB and *sql.Tx the row helpers need.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sortTimeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(sortTimeLayout, s)
+}
+
+// nullTime formats t for a nullable column.
+func nullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func scanNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// readInvites loads the invite with the given ID, or every invite when id is
+// empty, assembling each record from its rows in the four tables.
+func readInvites(ctx context.Context, q querier, id string) (map[string]*InviteRecord, error) {
+	where, args := "", []any(nil)
+	if id != "" {
+		where, args = " WHERE id = ?", []any{id}
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT id, additional_count, accepted, accepted_at, declined, language,
+		browser_views, first_browser_view, last_browser_view, bot_views, first_bot_view, last_bot_view
+		FROM invites`+where, args...)
+	if err != nil {
+		return nil, storageErr("querying invites", err)
+	}
+	invites := make(map[string]*InviteRecord)
+	err = scanRows(rows, func() error {
+		if err := ctxErr(ctx, "reading invites"); err != nil {
+			return err
+		}
+		var (
+			key                       string
+			r                         InviteRecord
+			acceptedAt                sql.NullString
+			firstBrowser, lastBrowser sql.NullString
+			firstBot, lastBot         sql.NullString
+		)
+		err := rows.Scan(&key, &r.AdditionalCount, &r.Accepted, &acceptedAt, &r.Declined, &r.Language,
+			&r.Views.Browser.Count, &firstBrowser, &lastBrowser, &r.Views.Bot.Count, &firstBot, &lastBot)
+		if err != nil {
+			return err
+		}
+		for _, t := range []struct {
+			dst **time.Time
+			src sql.NullString
+		}{
+			{&r.AcceptedAt, acceptedAt},
+			{&r.Views.Browser.First, firstBrowser}, {&r.Views.Browser.Last, lastBrowser},
+			{&r.Views.Bot.First, firstBot}, {&r.Views.Bot.Last, lastBot},
+		} {
+			if *t.dst, err = scanNullTime(t.src); err != nil {
+				return fmt.Errorf("invite %s: %w", key, err)
+			}
+		}
+		invites[key] = &r
+		return nil
+	})
+	if err := ctxErr(ctx, "reading invites"); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, storageErr("reading invites", err)
+	}
+	if len(invites) == 0 {
+		return invites, nil
+	}
+
+	where = ""
+	if id != "" {
+		where = " WHERE invite_id = ?"
+	}
+	names := func(table string, add func(r *InviteRecord, name string)) error {
+		rows, err := q.QueryContext(ctx, `SELECT invite_id, name FROM `+table+where+` ORDER BY invite_id, position`, args...)
+		if err != nil {
+			return storageErr("querying "+table, err)
+		}
+		err = scanRows(rows, func() error {
+			var key, name string
+			if err := rows.Scan(&key, &name); err != nil {
+				return err
+			}
+			if r := invites[key]; r != nil {
+				add(r, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return storageErr("reading "+table, err)
+		}
+		return nil
+	}
+	if err := names("people", func(r *InviteRecord, name string) { r.People = append(r.People, name) }); err != nil {
+		return nil, err
+	}
+	if err := names("additional_guests", func(r *InviteRecord, name string) { r.Additional = append(r.Additional, name) }); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.QueryContext(ctx, `SELECT invite_id, at, bot FROM views`+where+` ORDER BY invite_id, position`, args...)
+	if err != nil {
+		return nil, storageErr("querying views", err)
+	}
+	err = scanRows(rows, func() error {
+		var (
+			key, at string
+			v       View
+		)
+		if err := rows.Scan(&key, &at, &v.Bot); err != nil {
+			return err
+		}
+		if v.At, err = parseTime(at); err != nil {
+			return fmt.Errorf("invite %s: %w", key, err)
+		}
+		if r := invites[key]; r != nil {
+			r.Views.Recent = append(r.Views.Recent, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, storageErr("reading views", err)
+	}
+	return invites, nil
+}
+
+// scanRows calls scan for each row and closes rows.
+func scanRows(rows *sql.Rows, scan func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// writeInvite inserts or replaces the invite and all its child rows.
+func writeInvite(ctx context.Context, q querier, id string, rec InviteRecord) error {
+	// Reason: the bulk import may still carry the legacy viewed_at list, which
+	// has no column of its own
+	rec.compactLegacyViews()
+
+	_, err := q.ExecContext(ctx, `INSERT INTO invites (id, additional_count, accepted, accepted_at, declined, language,
+		browser_views, first_browser_view, last_browser_view, bot_views, first_bot_view, last_bot_view)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET additional_count = excluded.additional_count,
+			accepted = excluded.accepted, accepted_at = excluded.accepted_at,
+			declined = excluded.declined, language = excluded.language,
+			browser_views = excluded.browser_views, first_browser_view = excluded.first_browser_view,
+			last_browser_view = excluded.last_browser_view, bot_views = excluded.bot_views,
+			first_bot_view = excluded.first_bot_view, last_bot_view = excluded.last_bot_view`,
+		id, rec.AdditionalCount, rec.Accepted, nullTime(rec.AcceptedAt), rec.Declined, rec.Language,
+		rec.Views.Browser.Count, nullTime(rec.Views.Browser.First), nullTime(rec.Views.Browser.Last),
+		rec.Views.Bot.Count, nullTime(rec.Views.Bot.First), nullTime(rec.Views.Bot.Last))
+	if err != nil {
+		return storageErr("writing invite "+id, err)
+	}
+
+	for _, table := range []string{"people", "additional_guests", "views"} {
+		if _, err := q.ExecContext(ctx, `DELETE FROM `+table+` WHERE invite_id = ?`, id); err != nil {
+			return storageErr("clearing "+table+" of invite "+id, err)
+		}
+	}
+	for table, names := range map[string][]string{"people": rec.People, "additional_guests": rec.Additional} {
+		for i, name := range names {
+			if _, err := q.ExecContext(ctx, `INSERT INTO `+table+` (invite_id, position, name) VALUES (?, ?, ?)`, id, i, name); err != nil {
+				return storageErr("writing "+table+" of invite "+id, err)
+			}
+		}
+	}
+	for i, v := range rec.Views.Recent {
+		if _, err := q.ExecContext(ctx, `INSERT INTO views (invite_id, position, at, bot) VALUES (?, ?, ?, ?)`, id, i, formatTime(v.At), v.Bot); err != nil {
+			return storageErr("writing views of invite "+id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStore_Migrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sqlite")
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := s.CreateInvite(context.Background(), "a", InviteRecord{People: []string{"Тест"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Close()
+
+	// Reopening applies nothing twice and keeps the data
+	s, err = NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	var applied int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ms, _ := migrations()
+	if applied != len(ms) {
+		t.Fatalf("expected %d applied migrations, got %d", len(ms), applied)
+	}
+	if rec, _ := s.GetInvite(context.Background(), "a"); rec == nil {
+		t.Fatal("expected invite kept after reopening")
+	}
+
+	_, err = s.db.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'future', '')`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Close()
+	if _, err := NewSQLiteStore(path); err == nil {
+		t.Fatal("expected error for a schema newer than this build")
+	}
+}
+
+func TestSQLiteStore_Schema(t *testing.T) {
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	ctx := context.Background()
+
+	err = s.Seed(t.Context(), map[string]InviteRecord{"a": {People: []string{"Иван Петров", "Мария Петрова"}, AdditionalCount: 1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.UpdateInvite(ctx, "a", true, []string{"Георги"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.RecordView(ctx, "a", View{At: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Reason: reports query the tables directly, so the rows themselves are
+	// part of the contract
+	query := func(q string) []string {
+		t.Helper()
+		rows, err := s.db.Query(q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		var got []string
+		err = scanRows(rows, func() error {
+			var v sql.NullString
+			if err := rows.Scan(&v); err != nil {
+				return err
+			}
+			got = append(got, v.String)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		return got
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`SELECT name FROM people WHERE invite_id = 'a' ORDER BY position`, []string{"Иван Петров", "Мария Петрова"}},
+		{`SELECT name FROM additional_guests WHERE invite_id = 'a'`, []string{"Георги"}},
+		{`SELECT accepted || ',' || browser_views || ',' || last_browser_view FROM invites`, []string{"1,1,2026-05-01T12:00:00.000000000Z"}},
+		{`SELECT at FROM views WHERE invite_id = 'a'`, []string{"2026-05-01T12:00:00.000000000Z"}},
+	}
+	for _, tt := range tests {
+		if got := query(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+
+	if err := s.DeleteInvite(ctx, "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, table := range []string{"people", "additional_guests", "views"} {
+		if got := query(`SELECT invite_id FROM ` + table); len(got) != 0 {
+			t.Fatalf("expected %s rows deleted with the invite, got %v", table, got)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, state, attempts,
+	next_attempt_at, last_status, last_error, created_at, updated_at`
+
+// ListWebhooks returns every webhook in ID order.
+func (s *SQLiteStore) ListWebhooks(ctx context.Context) (hooks []Webhook, err error) {
+	err = s.read(ctx, "listing webhooks", func(tx *sql.Tx) error {
+		hooks, err = readWebhooks(ctx, tx, "")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// GetWebhook returns the webhook, or ErrNotFound.
+func (s *SQLiteStore) GetWebhook(ctx context.Context, id string) (hook *Webhook, err error) {
+	err = s.read(ctx, "getting webhook "+id, func(tx *sql.Tx) error {
+		hooks, err := readWebhooks(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if len(hooks) == 0 {
+			return fmt.Errorf("getting webhook %s: %w", id, ErrNotFound)
+		}
+		hook = &hooks[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// PutWebhook saves w under w.ID, replacing any earlier version.
+func (s *SQLiteStore) PutWebhook(ctx context.Context, w Webhook) error {
+	return s.write(ctx, "saving webhook "+w.ID, func(tx *sql.Tx) error {
+		// Reason: an upsert rather than INSERT OR REPLACE, which would delete
+		// the row and cascade to its deliveries
+		_, err := tx.ExecContext(ctx, `INSERT INTO webhooks (id, url, secret, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET url = excluded.url, secret = excluded.secret, created_at = excluded.created_at`,
+			w.ID, w.URL, w.Secret, formatTime(w.CreatedAt))
+		if err != nil {
+			return storageErr("saving webhook "+w.ID, err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_events WHERE webhook_id = ?`, w.ID); err != nil {
+			return storageErr("clearing events of webhook "+w.ID, err)
+		}
+		for i, event := range w.Events {
+			_, err := tx.ExecContext(ctx, `INSERT INTO webhook_events (webhook_id, position, event) VALUES (?, ?, ?)`, w.ID, i, event)
+			if err != nil {
+				return storageErr("saving events of webhook "+w.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteWebhook removes the webhook, or returns ErrNotFound. Its events and
+// deliveries go with it through ON DELETE CASCADE.
+func (s *SQLiteStore) DeleteWebhook(ctx context.Context, id string) error {
+	return s.write(ctx, "deleting webhook "+id, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+		if err != nil {
+			return storageErr("deleting webhook "+id, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return storageErr("deleting webhook "+id, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("deleting webhook %s: %w", id, ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// AddDeliveries puts deliveries in the outbox in one transaction. Deliveries
+// to a webhook deleted meanwhile are dropped.
+func (s *SQLiteStore) AddDeliveries(ctx context.Context, deliveries []Delivery) error {
+	return s.write(ctx, "adding deliveries", func(tx *sql.Tx) error {
+		return insertDeliveries(ctx, tx, deliveries)
+	})
+}
+
+// insertDeliveries puts deliveries in the outbox in tx, dropping those to a
+// webhook that does not exist.
+func insertDeliveries(ctx context.Context, tx *sql.Tx, deliveries []Delivery) error {
+	for _, d := range deliveries {
+		_, err := tx.ExecContext(ctx, `INSERT INTO webhook_deliveries (`+deliveryColumns+`)
+			SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM webhooks WHERE id = ?)`,
+			append(deliveryArgs(d), d.WebhookID)...)
+		if err != nil {
+			return storageErr("adding delivery "+d.ID, err)
+		}
+	}
+	return nil
+}
+
+// DueDeliveries returns up to limit pending deliveries due at now, oldest
+// first.
+func (s *SQLiteStore) DueDeliveries(ctx context.Context, now time.Time, limit int) (due []Delivery, err error) {
+	err = s.read(ctx, "listing due deliveries", func(tx *sql.Tx) error {
+		due, err = readDeliveries(ctx, tx, `WHERE state = ? AND next_attempt_at <= ? ORDER BY id LIMIT ?`,
+			DeliveryPending, formatTime(now), limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+// UpdateDelivery saves d after an attempt, or returns ErrNotFound when it was
+// deleted meanwhile.
+func (s *SQLiteStore) UpdateDelivery(ctx context.Context, d Delivery) error {
+	return s.write(ctx, "updating delivery "+d.ID, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE webhook_deliveries SET state = ?, attempts = ?, next_attempt_at = ?,
+			last_status = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+			d.State, d.Attempts, formatTime(d.NextAttemptAt), d.LastStatus, d.LastError, formatTime(d.UpdatedAt), d.ID)
+		if err != nil {
+			return storageErr("updating delivery "+d.ID, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return storageErr("updating delivery "+d.ID, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("updating delivery %s: %w", d.ID, ErrNotFound)
+		}
+		return nil
+	})
+}
+
+// ListDeliveries returns up to limit deliveries to the webhook, newest first.
+func (s *SQLiteStore) ListDeliveries(ctx context.Context, webhookID string, limit int) (list []Delivery, err error) {
+	err = s.read(ctx, "listing deliveries", func(tx *sql.Tx) error {
+		list, err = readDeliveries(ctx, tx, `WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`, webhookID, limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteDeliveries removes the finished deliveries last updated before
+// cutoff and returns how many there were.
+func (s *SQLiteStore) DeleteDeliveries(ctx context.Context, cutoff time.Time) (deleted int, err error) {
+	err = s.write(ctx, "deleting deliveries", func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE state != ? AND updated_at < ?`,
+			DeliveryPending, formatTime(cutoff))
+		if err != nil {
+			return storageErr("deleting deliveries", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return storageErr("deleting deliveries", err)
+		}
+		deleted = int(n)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// readWebhooks loads the webhook with the given ID, or every webhook when id
+// is empty, in ID order.
+func readWebhooks(ctx context.Context, q querier, id string) ([]Webhook, error) {
+	where, args := "", []any(nil)
+	if id != "" {
+		where, args = " WHERE id = ?", []any{id}
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT id, url, secret, created_at FROM webhooks`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, storageErr("querying webhooks", err)
+	}
+	var hooks []Webhook
+	index := make(map[string]int)
+	err = scanRows(rows, func() error {
+		var w Webhook
+		var createdAt string
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &createdAt); err != nil {
+			return err
+		}
+		if w.CreatedAt, err = parseTime(createdAt); err != nil {
+			return err
+		}
+		index[w.ID] = len(hooks)
+		hooks = append(hooks, w)
+		return nil
+	})
+	if err != nil {
+		return nil, storageErr("reading webhooks", err)
+	}
+
+	where = ""
+	if id != "" {
+		where = " WHERE webhook_id = ?"
+	}
+	rows, err = q.QueryContext(ctx, `SELECT webhook_id, event FROM webhook_events`+where+` ORDER BY webhook_id, position`, args...)
+	if err != nil {
+		return nil, storageErr("querying webhook events", err)
+	}
+	err = scanRows(rows, func() error {
+		var hookID, event string
+		if err := rows.Scan(&hookID, &event); err != nil {
+			return err
+		}
+		if i, ok := index[hookID]; ok {
+			hooks[i].Events = append(hooks[i].Events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, storageErr("reading webhook events", err)
+	}
+	return hooks, nil
+}
+
+// readDeliveries loads the deliveries selected by the WHERE, ORDER BY and
+// LIMIT clauses in tail.
+func readDeliveries(ctx context.Context, q querier, tail string, args ...any) ([]Delivery, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries `+tail, args...)
+	if err != nil {
+		return nil, storageErr("querying deliveries", err)
+	}
+	var list []Delivery
+	err = scanRows(rows, func() error {
+		var d Delivery
+		var next, created, updated string
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.State, &d.Attempts,
+			&next, &d.LastStatus, &d.LastError, &created, &updated)
+		if err != nil {
+			return err
+		}
+		for _, t := range []struct {
+			dst *time.Time
+			src string
+		}{{&d.NextAttemptAt, next}, {&d.CreatedAt, created}, {&d.UpdatedAt, updated}} {
+			if *t.dst, err = parseTime(t.src); err != nil {
+				return err
+			}
+		}
+		list = append(list, d)
+		return nil
+	})
+	if err != nil {
+		return nil, storageErr("reading deliveries", err)
+	}
+	return list, nil
+}
+
+func deliveryArgs(d Delivery) []any {
+	return []any{d.ID, d.WebhookID, d.EventID, d.EventType, d.Payload, d.State, d.Attempts,
+		formatTime(d.NextAttemptAt), d.LastStatus, d.LastError, formatTime(d.CreatedAt), formatTime(d.UpdatedAt)}
+}
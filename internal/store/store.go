@@ -2,20 +2,200 @@ package store
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/names"
 )
 
 type InviteRecord struct {
-	People          []string    `json:"people"`
-	AdditionalCount int         `json:"additional_count"`
-	Additional      []string    `json:"additional"`
-	Accepted        bool        `json:"accepted"`
-	ViewedAt        []time.Time `json:"viewed_at"`
-	AcceptedAt      *time.Time  `json:"accepted_at"`
+	People          []string `json:"people"`
+	AdditionalCount int      `json:"additional_count"`
+	Additional      []string `json:"additional"`
+	Accepted        bool     `json:"accepted"`
+	Views           Views    `json:"views"`
+	// ViewedAt is the uncompacted view list of earlier versions. It is only
+	// read, and folded into Views when the invite is loaded.
+	ViewedAt   []time.Time `json:"viewed_at,omitempty"`
+	AcceptedAt *time.Time  `json:"accepted_at"`
+	// Declined is set by admins for guests who sent their regrets; accepting
+	// the invite clears it.
+	Declined bool `json:"declined,omitempty"`
+	// Language is the preferred language code ("bg" or "en") for messages
+	// shown to this invite's guests; empty means follow Accept-Language.
+	Language string `json:"language,omitempty"`
+}
+
+// clone returns a copy of r that shares no memory with it, so r can be
+// edited while the copy keeps the state before.
+func (r InviteRecord) clone() InviteRecord {
+	c := r
+	c.People = slices.Clone(r.People)
+	c.Additional = slices.Clone(r.Additional)
+	c.Views.Recent = slices.Clone(r.Views.Recent)
+	c.ViewedAt = slices.Clone(r.ViewedAt)
+	if r.AcceptedAt != nil {
+		at := *r.AcceptedAt
+		c.AcceptedAt = &at
+	}
+	return c
 }
 
+// InviteStore is the storage used by the public API. GetInvite only reads;
+// views are recorded separately with RecordView. GetInvite and UpdateInvite
+// return ErrNotFound for a missing invite.
 type InviteStore interface {
 	GetInvite(ctx context.Context, id string) (*InviteRecord, error)
+	RecordView(ctx context.Context, id string, view View) error
 	UpdateInvite(ctx context.Context, id string, accepted bool, additional []string) (*InviteRecord, error)
 	Close() error
 }
+
+// Errors returned by the stores, wrapped with what was being done. Callers
+// match them with errors.Is; any other error is internal, and its text must
+// not be shown to guests.
+var (
+	// ErrNotFound is returned when the invite, or idempotency record, does
+	// not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInviteExists is returned by CreateInvite when the ID is already taken.
+	ErrInviteExists = errors.New("invite already exists")
+	// ErrTooManyGuests matches every TooManyGuestsError.
+	ErrTooManyGuests = errors.New("too many additional guests")
+	// ErrDuplicateGuest matches every DuplicateGuestError.
+	ErrDuplicateGuest = errors.New("duplicate guest")
+	// ErrBlankGuest matches every BlankGuestError.
+	ErrBlankGuest = errors.New("blank guest name")
+	// ErrInvalidTransition is returned by UpdateInvite for an RSVP change
+	// guests cannot make themselves, such as withdrawing an acceptance.
+	ErrInvalidTransition = errors.New("invalid RSVP transition")
+	// ErrDeadlinePassed is returned by UpdateInvite once the RSVP deadline
+	// set with WithRSVPDeadline is over.
+	ErrDeadlinePassed = errors.New("RSVP deadline has passed")
+	// ErrStorage marks failures of the storage itself: I/O errors, failed
+	// transactions and records that cannot be decoded.
+	ErrStorage = errors.New("storage failure")
+)
+
+// storageErr wraps err from the underlying storage as ErrStorage.
+func storageErr(op string, err error) error {
+	return fmt.Errorf("%s: %w: %w", op, ErrStorage, err)
+}
+
+// TooManyGuestsError is returned by UpdateInvite when more additional guests
+// are submitted than the invite allows.
+type TooManyGuestsError struct {
+	Got int
+	Max int
+}
+
+func (e *TooManyGuestsError) Error() string {
+	return fmt.Sprintf("too many additional guests: got %d, max allowed %d", e.Got, e.Max)
+}
+
+func (e *TooManyGuestsError) Is(target error) bool {
+	return target == ErrTooManyGuests
+}
+
+// DuplicateGuestError is returned by UpdateInvite when an additional guest
+// repeats another additional guest or someone already in People.
+type DuplicateGuestError struct {
+	// Index is the position in the submitted additional list.
+	Index int
+	// InPeople is true when the name matches an invited person rather than
+	// an earlier additional guest.
+	InPeople bool
+}
+
+func (e *DuplicateGuestError) Error() string {
+	if e.InPeople {
+		return fmt.Sprintf("additional guest %d is already invited", e.Index)
+	}
+	return fmt.Sprintf("additional guest %d is listed more than once", e.Index)
+}
+
+func (e *DuplicateGuestError) Is(target error) bool {
+	return target == ErrDuplicateGuest
+}
+
+// BlankGuestError is returned by UpdateInvite when an additional guest's
+// name is empty once normalized, such as a name of only spaces.
+type BlankGuestError struct {
+	// Index is the position in the submitted additional list.
+	Index int
+}
+
+func (e *BlankGuestError) Error() string {
+	return fmt.Sprintf("additional guest %d has a blank name", e.Index)
+}
+
+func (e *BlankGuestError) Is(target error) bool {
+	return target == ErrBlankGuest
+}
+
+// ctxErr returns ctx's error wrapped with op, or nil while ctx is live.
+// Stores check it before starting and again once they hold the write lock,
+// so a caller that gave up while waiting changes nothing.
+func ctxErr(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// accept records the guests' acceptance on r with the additional guests
+// normalized and checked, as UpdateInvite does in every store. A zero
+// deadline means replies never close.
+func accept(r *InviteRecord, accepted bool, additional []string, now, deadline time.Time) error {
+	if !accepted {
+		return fmt.Errorf("%w: guests can only accept", ErrInvalidTransition)
+	}
+	if !deadline.IsZero() && now.After(deadline) {
+		return fmt.Errorf("%w: replies closed at %s", ErrDeadlinePassed, deadline.Format(time.RFC3339))
+	}
+	normalized, err := normalizeAdditional(*r, additional)
+	if err != nil {
+		return err
+	}
+	r.Accepted = true
+	r.Declined = false
+	r.Additional = normalized
+	r.AcceptedAt = &now
+	return nil
+}
+
+// normalizeAdditional normalizes the submitted names and checks them against
+// the guest limit, for blank names and for duplicates within the invite.
+func normalizeAdditional(r InviteRecord, additional []string) ([]string, error) {
+	if len(additional) > r.AdditionalCount {
+		return nil, &TooManyGuestsError{Got: len(additional), Max: r.AdditionalCount}
+	}
+	if additional == nil {
+		return nil, nil
+	}
+
+	people := make(map[string]bool, len(r.People))
+	for _, p := range r.People {
+		people[names.Key(p)] = true
+	}
+
+	seen := make(map[string]bool, len(additional))
+	out := make([]string, len(additional))
+	for i, name := range additional {
+		out[i] = names.Normalize(name)
+		if out[i] == "" {
+			return nil, &BlankGuestError{Index: i}
+		}
+		key := names.Key(out[i])
+		if people[key] {
+			return nil, &DuplicateGuestError{Index: i, InPeople: true}
+		}
+		if seen[key] {
+			return nil, &DuplicateGuestError{Index: i}
+		}
+		seen[key] = true
+	}
+	return out, nil
+}
@@ -0,0 +1,294 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func testSeedSkipsExisting(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	err := s.Seed(t.Context(), map[string]store.InviteRecord{
+		SeedID:    {People: []string{"Друг"}},
+		"aaa-002": {People: []string{"Нов Гост"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invites, err := s.GetAllInvites(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invites) != 2 || invites[SeedID].People[0] != "Иван Петров" {
+		t.Fatalf("expected %s kept and aaa-002 added, got %+v", SeedID, invites)
+	}
+}
+
+func testCreateInvite(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	if err := s.CreateInvite(ctx, "aaa-002", store.InviteRecord{People: []string{"Нов Гост"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec := get(t, s, "aaa-002"); rec == nil || rec.People[0] != "Нов Гост" {
+		t.Fatalf("expected created invite, got %+v", rec)
+	}
+
+	err := s.CreateInvite(ctx, SeedID, store.InviteRecord{People: []string{"Друг"}})
+	if !errors.Is(err, store.ErrInviteExists) {
+		t.Fatalf("expected ErrInviteExists, got %v", err)
+	}
+	if !strings.Contains(err.Error(), SeedID) {
+		t.Fatalf("expected error to name %s, got %v", SeedID, err)
+	}
+	if rec := get(t, s, SeedID); rec.People[0] != "Иван Петров" {
+		t.Fatalf("expected existing invite kept, got %+v", rec)
+	}
+}
+
+func testEditInvite(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	if err := s.RecordView(ctx, SeedID, store.View{At: time.Now()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec, err := s.EditInvite(ctx, SeedID, func(r *store.InviteRecord) error {
+		r.AdditionalCount = 5
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.AdditionalCount != 5 || rec.Views.Browser.Count != 1 {
+		t.Fatalf("expected count 5 with the view kept, got %+v", rec)
+	}
+
+	abort := errors.New("abort")
+	if _, err := s.EditInvite(ctx, SeedID, func(r *store.InviteRecord) error {
+		r.AdditionalCount = 9
+		return abort
+	}); !errors.Is(err, abort) {
+		t.Fatalf("expected abort error, got %v", err)
+	}
+	if rec := get(t, s, SeedID); rec.AdditionalCount != 5 {
+		t.Fatalf("expected aborted edit not to be saved, got %d", rec.AdditionalCount)
+	}
+
+	called := false
+	rec, err = s.EditInvite(ctx, "nonexistent", func(*store.InviteRecord) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, store.ErrNotFound) || rec != nil || called {
+		t.Fatalf("expected ErrNotFound without calling edit for missing invite, got %+v, %v, called=%v", rec, err, called)
+	}
+}
+
+func testDeleteInvite(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	if err := s.DeleteInvite(ctx, SeedID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	missing(t, s, SeedID)
+
+	if err := s.DeleteInvite(ctx, SeedID); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting a missing invite, got %v", err)
+	}
+}
+
+func testGetAllInvites(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	invites, err := s.GetAllInvites(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invites) != 1 {
+		t.Fatalf("expected 1 invite, got %d", len(invites))
+	}
+	rec, ok := invites[SeedID]
+	if !ok {
+		t.Fatalf("expected key %s", SeedID)
+	}
+	if len(rec.People) != 2 {
+		t.Fatalf("expected 2 people, got %d", len(rec.People))
+	}
+}
+
+func testGetAllInvitesEmpty(t *testing.T, open Opener) {
+	s := open(t)
+
+	invites, err := s.GetAllInvites(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invites) != 0 {
+		t.Fatalf("expected 0 invites, got %d", len(invites))
+	}
+}
+
+func testReplaceAllInvites(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	replaced := map[string]store.InviteRecord{
+		"bbb-001": {People: []string{"Нов Гост"}, AdditionalCount: 1},
+		"bbb-002": {People: []string{"Друг Гост"}, Accepted: true},
+	}
+	if err := s.ReplaceAllInvites(ctx, replaced); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invites, err := s.GetAllInvites(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invites) != 2 || !invites["bbb-002"].Accepted {
+		t.Fatalf("expected the 2 replacement invites, got %+v", invites)
+	}
+	if _, ok := invites[SeedID]; ok {
+		t.Fatalf("old invite %s should have been removed", SeedID)
+	}
+}
+
+func testReplaceAllInvitesEmpty(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	if err := s.ReplaceAllInvites(context.Background(), map[string]store.InviteRecord{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	invites, err := s.GetAllInvites(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invites) != 0 {
+		t.Fatalf("expected 0 invites after empty replace, got %d", len(invites))
+	}
+}
+
+func listTestStore(t *testing.T, open Opener) store.Store {
+	t.Helper()
+	s := open(t)
+
+	at := func(day int) *time.Time {
+		v := time.Date(2026, 5, day, 12, 0, 0, 0, time.UTC)
+		return &v
+	}
+	// viewed builds the browser views recorded at the given times, in order.
+	viewed := func(times ...time.Time) store.Views {
+		v := store.Views{Browser: store.ViewStats{Count: len(times)}}
+		for i := range times {
+			at := times[i]
+			if v.Browser.First == nil || at.Before(*v.Browser.First) {
+				v.Browser.First = &at
+			}
+			if v.Browser.Last == nil || at.After(*v.Browser.Last) {
+				v.Browser.Last = &at
+			}
+			v.Recent = append(v.Recent, store.View{At: at})
+		}
+		return v
+	}
+	err := s.Seed(t.Context(), map[string]store.InviteRecord{
+		"a": {People: []string{"Яна Иванова"}, Accepted: true, AcceptedAt: at(3), Views: viewed(*at(1))},
+		"b": {People: []string{"Борис Стоев"}},
+		"c": {People: []string{"Атанас Колев"}, Views: viewed(*at(5), *at(2))},
+		"d": {People: []string{"Виктория Ненова"}, Accepted: true, AcceptedAt: at(1), Views: viewed(*at(1))},
+		"e": {People: []string{"Георги Тодоров"}, Declined: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	return s
+}
+
+// listAll follows Next cursors until the last page and returns the IDs in order.
+func listAll(t *testing.T, s store.Store, q store.ListQuery) []string {
+	t.Helper()
+	var ids []string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("expected pagination to end")
+		}
+		page, err := s.ListInvites(context.Background(), q)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Invites) > q.Limit {
+			t.Fatalf("expected at most %d invites, got %d", q.Limit, len(page.Invites))
+		}
+		for _, e := range page.Invites {
+			ids = append(ids, e.ID)
+		}
+		if page.Next == nil {
+			return ids
+		}
+		q.After = page.Next
+	}
+}
+
+func testListInvites(t *testing.T, open Opener) {
+	s := listTestStore(t, open)
+	accepted := func(_ string, r store.InviteRecord) bool { return r.Status() == store.StatusAccepted }
+
+	tests := []struct {
+		name string
+		q    store.ListQuery
+		want []string
+	}{
+		{"by id", store.ListQuery{Limit: 2}, []string{"a", "b", "c", "d", "e"}},
+		{"by id desc", store.ListQuery{Sort: store.SortByID, Desc: true, Limit: 2}, []string{"e", "d", "c", "b", "a"}},
+		{"by id filtered", store.ListQuery{Filter: accepted, Limit: 1}, []string{"a", "d"}},
+		{"by name", store.ListQuery{Sort: store.SortByName, Limit: 2}, []string{"c", "b", "d", "e", "a"}},
+		{"by name desc", store.ListQuery{Sort: store.SortByName, Desc: true, Limit: 3}, []string{"a", "e", "d", "b", "c"}},
+		{"by accepted_at, unaccepted last", store.ListQuery{Sort: store.SortByAcceptedAt, Limit: 2}, []string{"d", "a", "b", "c", "e"}},
+		{"by accepted_at desc", store.ListQuery{Sort: store.SortByAcceptedAt, Desc: true, Limit: 10}, []string{"a", "d", "e", "c", "b"}},
+		{"by last viewed", store.ListQuery{Sort: store.SortByLastViewed, Limit: 1}, []string{"a", "d", "c", "b", "e"}},
+		{"by last viewed filtered", store.ListQuery{Sort: store.SortByLastViewed, Filter: accepted, Limit: 1}, []string{"a", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listAll(t, s, tt.q); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func testListInvitesDeletedCursor(t *testing.T, open Opener) {
+	s := listTestStore(t, open)
+
+	// Reason: a cursor must still work after the invite it points at is removed
+	for _, desc := range []bool{false, true} {
+		page, err := s.ListInvites(context.Background(), store.ListQuery{Desc: desc, After: &store.Cursor{Value: "bb", ID: "bb"}, Limit: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "c"
+		if desc {
+			want = "b"
+		}
+		if len(page.Invites) != 1 || page.Invites[0].ID != want {
+			t.Fatalf("desc=%v: expected %s, got %+v", desc, want, page.Invites)
+		}
+	}
+}
+
+func testListInvitesInvalidLimit(t *testing.T, open Opener) {
+	s := listTestStore(t, open)
+	if _, err := s.ListInvites(context.Background(), store.ListQuery{}); err == nil {
+		t.Fatal("expected error for zero limit")
+	}
+}
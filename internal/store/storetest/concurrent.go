@@ -0,0 +1,268 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// parallel runs n copies of fn at once and fails on the first error.
+func parallel(t *testing.T, n int, fn func(i int) error) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- fn(i)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func testConcurrentViewsAndAccepts(t *testing.T, open Opener) {
+	s := open(t, store.WithViewPolicy(store.ViewPolicy{MaxRecent: 5}))
+	ctx := context.Background()
+
+	const invites, views = 4, 25
+	for i := 0; i < invites; i++ {
+		if err := s.CreateInvite(ctx, fmt.Sprint(i), store.InviteRecord{People: []string{"Гост"}, AdditionalCount: 1}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Reason: one accept per invite is mixed in with its views; neither may
+	// overwrite the other's change
+	now := time.Now().UTC()
+	parallel(t, invites*(views+1), func(i int) error {
+		id := fmt.Sprint(i % invites)
+		if i < invites {
+			_, err := s.UpdateInvite(ctx, id, true, []string{"Георги"})
+			return err
+		}
+		return s.RecordView(ctx, id, store.View{At: now})
+	})
+
+	all, err := s.GetAllInvites(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for id, rec := range all {
+		if !rec.Accepted || len(rec.Additional) != 1 || rec.Views.Browser.Count != views || len(rec.Views.Recent) != 5 {
+			t.Fatalf("invite %s: expected accepted with %d views and 5 recent, got %+v", id, views, rec)
+		}
+	}
+}
+
+func testConcurrentAccepts(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	answers := [][]string{{"Георги"}, {"Елена", "Петър"}, nil, {"Стоян"}}
+	parallel(t, 20, func(i int) error {
+		_, err := s.UpdateInvite(ctx, SeedID, true, answers[i%len(answers)])
+		return err
+	})
+
+	// Reason: the last accept wins whole; lists must never be mixed
+	rec := get(t, s, SeedID)
+	for _, a := range answers {
+		if fmt.Sprint(rec.Additional) == fmt.Sprint(a) {
+			return
+		}
+	}
+	t.Fatalf("expected one of the submitted guest lists, got %v", rec.Additional)
+}
+
+func testConcurrentCreates(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	created := 0
+	parallel(t, 10, func(i int) error {
+		err := s.CreateInvite(ctx, SeedID, store.InviteRecord{People: []string{fmt.Sprint("Гост ", i)}})
+		if errors.Is(err, store.ErrInviteExists) {
+			return nil
+		}
+		if err == nil {
+			mu.Lock()
+			created++
+			mu.Unlock()
+		}
+		return err
+	})
+	if created != 1 {
+		t.Fatalf("expected exactly one create to succeed, got %d", created)
+	}
+}
+
+func testContextCanceled(t *testing.T, open Opener) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checkContextErr(t, open, ctx, context.Canceled)
+}
+
+func testContextDeadline(t *testing.T, open Opener) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	checkContextErr(t, open, ctx, context.DeadlineExceeded)
+}
+
+// checkContextErr calls every context-aware method with the finished ctx and
+// expects each to fail with an error wrapping want and to change nothing.
+func checkContextErr(t *testing.T, open Opener, ctx context.Context, want error) {
+	t.Helper()
+	s := Seeded(t, open)
+	rec := store.InviteRecord{People: []string{"Нов Гост"}}
+
+	calls := map[string]func() error{
+		"GetInvite": func() error { _, err := s.GetInvite(ctx, SeedID); return err },
+		"RecordView": func() error {
+			return s.RecordView(ctx, SeedID, store.View{At: time.Now()})
+		},
+		"RecordViews": func() error {
+			_, err := s.RecordViews(ctx, []store.ViewEvent{{ID: SeedID, View: store.View{At: time.Now()}}})
+			return err
+		},
+		"UpdateInvite":  func() error { _, err := s.UpdateInvite(ctx, SeedID, true, nil); return err },
+		"GetAllInvites": func() error { _, err := s.GetAllInvites(ctx); return err },
+		"ListInvites": func() error {
+			_, err := s.ListInvites(ctx, store.ListQuery{Limit: 10})
+			return err
+		},
+		"CreateInvite": func() error { return s.CreateInvite(ctx, "aaa-002", rec) },
+		"Seed": func() error {
+			return s.Seed(ctx, map[string]store.InviteRecord{"aaa-002": rec})
+		},
+		"EditInvite": func() error {
+			_, err := s.EditInvite(ctx, SeedID, func(r *store.InviteRecord) error {
+				r.AdditionalCount = 9
+				return nil
+			})
+			return err
+		},
+		"DeleteInvite": func() error { return s.DeleteInvite(ctx, SeedID) },
+		"ReplaceAllInvites": func() error {
+			return s.ReplaceAllInvites(ctx, map[string]store.InviteRecord{"aaa-002": rec})
+		},
+		"GetIdempotencyRecord": func() error { _, err := s.GetIdempotencyRecord(ctx, "k1"); return err },
+		"PutIdempotencyRecord": func() error {
+			return s.PutIdempotencyRecord(ctx, "k1", store.IdempotencyRecord{CreatedAt: time.Now()})
+		},
+		"DeleteIdempotencyRecords": func() error {
+			_, err := s.DeleteIdempotencyRecords(ctx, time.Now())
+			return err
+		},
+		"ListWebhooks":   func() error { _, err := s.ListWebhooks(ctx); return err },
+		"GetWebhook":     func() error { _, err := s.GetWebhook(ctx, "w1"); return err },
+		"PutWebhook":     func() error { return s.PutWebhook(ctx, store.Webhook{ID: "w1", CreatedAt: time.Now()}) },
+		"DeleteWebhook":  func() error { return s.DeleteWebhook(ctx, "w1") },
+		"AddDeliveries":  func() error { return s.AddDeliveries(ctx, []store.Delivery{{ID: "d1", WebhookID: "w1"}}) },
+		"UpdateDelivery": func() error { return s.UpdateDelivery(ctx, store.Delivery{ID: "d1"}) },
+		"DueDeliveries": func() error {
+			_, err := s.DueDeliveries(ctx, time.Now(), 10)
+			return err
+		},
+		"ListDeliveries": func() error {
+			_, err := s.ListDeliveries(ctx, "w1", 10)
+			return err
+		},
+		"DeleteDeliveries": func() error {
+			_, err := s.DeleteDeliveries(ctx, time.Now())
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, want) {
+			t.Fatalf("%s: expected error wrapping %v, got %v", name, want, err)
+		}
+	}
+
+	all, err := s.GetAllInvites(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, ok := all[SeedID]
+	if len(all) != 1 || !ok || got.Accepted || got.AdditionalCount != 2 || got.Views.Opened() {
+		t.Fatalf("expected no changes after canceled calls, got %+v", all)
+	}
+}
+
+// cancelAfter is a context whose Err reports context.Canceled from its
+// (n+1)th call on, cancelling an operation part way through.
+type cancelAfter struct {
+	context.Context
+	mu sync.Mutex
+	n  int
+}
+
+func (c *cancelAfter) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n > 0 {
+		c.n--
+		return nil
+	}
+	return context.Canceled
+}
+
+// testContextCanceledMidway cancels the bulk operations after they have
+// started on the records: each must stop with context.Canceled and write
+// nothing.
+func testContextCanceledMidway(t *testing.T, open Opener) {
+	const count = 20
+	s := open(t)
+	before := make(map[string]store.InviteRecord, count)
+	after := make(map[string]store.InviteRecord, count)
+	for i := range count {
+		before[fmt.Sprintf("a%02d", i)] = store.InviteRecord{People: []string{"Стар Гост"}}
+		after[fmt.Sprintf("b%02d", i)] = store.InviteRecord{People: []string{"Нов Гост"}}
+	}
+	if err := s.Seed(t.Context(), before); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+
+	calls := map[string]func(ctx context.Context) error{
+		"Seed":              func(ctx context.Context) error { return s.Seed(ctx, after) },
+		"ReplaceAllInvites": func(ctx context.Context) error { return s.ReplaceAllInvites(ctx, after) },
+		"GetAllInvites":     func(ctx context.Context) error { _, err := s.GetAllInvites(ctx); return err },
+		"ListInvites": func(ctx context.Context) error {
+			_, err := s.ListInvites(ctx, store.ListQuery{Sort: store.SortByName, Limit: count})
+			return err
+		},
+	}
+	for name, call := range calls {
+		// Reason: five checks get every driver past its checks before
+		// starting and under the lock, and stop it a few records in
+		ctx := &cancelAfter{Context: t.Context(), n: 5}
+		if err := call(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("%s: expected error wrapping context.Canceled, got %v", name, err)
+		}
+	}
+
+	all, err := s.GetAllInvites(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != count {
+		t.Fatalf("expected the %d seeded invites unchanged, got %d", count, len(all))
+	}
+	for id := range before {
+		if _, ok := all[id]; !ok {
+			t.Fatalf("expected %s kept, got %v", id, all)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func idempotencyRecord(body string, createdAt time.Time) store.IdempotencyRecord {
+	return store.IdempotencyRecord{
+		Fingerprint:     "fp-" + body,
+		Status:          200,
+		ContentType:     "application/json; charset=utf-8",
+		ContentLanguage: "en",
+		Body:            []byte(body),
+		CreatedAt:       createdAt,
+	}
+}
+
+func testIdempotencyRecord(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+
+	if _, err := s.GetIdempotencyRecord(ctx, "k1"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing key, got %v", err)
+	}
+
+	want := idempotencyRecord(`{"accepted":true}`, time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	if err := s.PutIdempotencyRecord(ctx, "k1", want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := s.GetIdempotencyRecord(ctx, "k1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("expected %+v, got %+v", want, *got)
+	}
+
+	// Reason: a caller changing the returned body must not change the record
+	got.Body[0] = 'x'
+	if again, _ := s.GetIdempotencyRecord(ctx, "k1"); string(again.Body) != string(want.Body) {
+		t.Fatalf("expected stored body unchanged, got %q", again.Body)
+	}
+
+	replaced := idempotencyRecord("{}", want.CreatedAt.Add(time.Minute))
+	if err := s.PutIdempotencyRecord(ctx, "k1", replaced); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := s.GetIdempotencyRecord(ctx, "k1"); got.Fingerprint != replaced.Fingerprint {
+		t.Fatalf("expected the record replaced, got %+v", got)
+	}
+}
+
+func testIdempotencyRecordEmptyBody(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+
+	rec := store.IdempotencyRecord{Fingerprint: "fp", Status: 204, CreatedAt: time.Now().UTC()}
+	if err := s.PutIdempotencyRecord(ctx, "k1", rec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := s.GetIdempotencyRecord(ctx, "k1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != 204 || len(got.Body) != 0 {
+		t.Fatalf("expected an empty 204, got %+v", got)
+	}
+}
+
+func testDeleteIdempotencyRecords(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for key, age := range map[string]time.Duration{"old": 48 * time.Hour, "older": 72 * time.Hour, "new": time.Hour} {
+		if err := s.PutIdempotencyRecord(ctx, key, idempotencyRecord(key, now.Add(-age))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	deleted, err := s.DeleteIdempotencyRecords(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted, got %d", deleted)
+	}
+	for _, key := range []string{"old", "older"} {
+		if _, err := s.GetIdempotencyRecord(ctx, key); !errors.Is(err, store.ErrNotFound) {
+			t.Fatalf("expected %s deleted, got %v", key, err)
+		}
+	}
+	if _, err := s.GetIdempotencyRecord(ctx, "new"); err != nil {
+		t.Fatalf("expected new kept, got %v", err)
+	}
+
+	// Replacing the invites leaves the keys alone
+	if err := s.ReplaceAllInvites(ctx, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.GetIdempotencyRecord(ctx, "new"); err != nil {
+		t.Fatalf("expected new kept after replacing invites, got %v", err)
+	}
+}
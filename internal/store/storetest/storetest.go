@@ -0,0 +1,109 @@
+// Package storetest holds the behaviour every store.Store implementation
+// must share. A driver's tests call Run with a function that opens an empty
+// store; each case runs as a subtest on a fresh store.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// Opener opens an empty store configured with opts, registering any cleanup
+// with t.
+type Opener func(t *testing.T, opts ...store.Option) store.Store
+
+// SeedID is the invite seeded by Seeded.
+const SeedID = "aaa-001"
+
+// Seeded opens a store holding SeedID, an unanswered invite for two people
+// with room for two additional guests.
+func Seeded(t *testing.T, open Opener) store.Store {
+	t.Helper()
+	s := open(t)
+	err := s.Seed(t.Context(), map[string]store.InviteRecord{
+		SeedID: {
+			People:          []string{"Иван Петров", "Мария Петрова"},
+			AdditionalCount: 2,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	return s
+}
+
+var cases = []struct {
+	name string
+	test func(t *testing.T, open Opener)
+}{
+	{"GetInvite", testGetInvite},
+	{"GetInvite/NotFound", testGetInviteNotFound},
+	{"GetInvite/ReturnsCopies", testReturnsCopies},
+	{"GetInvite/CompactsLegacyViews", testCompactsLegacyViews},
+	{"RecordView", testRecordView},
+	{"RecordView/Policy", testRecordViewPolicy},
+	{"RecordViews", testRecordViews},
+	{"UpdateInvite", testUpdateInvite},
+	{"UpdateInvite/NotFound", testUpdateInviteNotFound},
+	{"UpdateInvite/AcceptedFalse", testUpdateInviteAcceptedFalse},
+	{"UpdateInvite/TooManyGuests", testUpdateInviteTooMany},
+	{"UpdateInvite/NormalizesNames", testUpdateInviteNormalizes},
+	{"UpdateInvite/Duplicates", testUpdateInviteDuplicates},
+	{"UpdateInvite/Blank", testUpdateInviteBlank},
+	{"UpdateInvite/Reaccept", testUpdateInviteReaccept},
+	{"UpdateInvite/Deadline", testUpdateInviteDeadline},
+	{"Seed/SkipsExisting", testSeedSkipsExisting},
+	{"CreateInvite", testCreateInvite},
+	{"EditInvite", testEditInvite},
+	{"DeleteInvite", testDeleteInvite},
+	{"GetAllInvites", testGetAllInvites},
+	{"GetAllInvites/Empty", testGetAllInvitesEmpty},
+	{"ReplaceAllInvites", testReplaceAllInvites},
+	{"ReplaceAllInvites/Empty", testReplaceAllInvitesEmpty},
+	{"ListInvites", testListInvites},
+	{"ListInvites/DeletedCursor", testListInvitesDeletedCursor},
+	{"ListInvites/InvalidLimit", testListInvitesInvalidLimit},
+	{"IdempotencyRecord", testIdempotencyRecord},
+	{"IdempotencyRecord/EmptyBody", testIdempotencyRecordEmptyBody},
+	{"DeleteIdempotencyRecords", testDeleteIdempotencyRecords},
+	{"Webhooks", testWebhooks},
+	{"Deliveries", testDeliveries},
+	{"Deliveries/DeletedWebhook", testDeliveriesDeletedWebhook},
+	{"DeleteDeliveries", testDeleteDeliveries},
+	{"UpdateInviteWithOutbox", testUpdateInviteWithOutbox},
+	{"Concurrent/ViewsAndAccepts", testConcurrentViewsAndAccepts},
+	{"Concurrent/Accepts", testConcurrentAccepts},
+	{"Concurrent/Creates", testConcurrentCreates},
+	{"Context/Canceled", testContextCanceled},
+	{"Context/DeadlineExceeded", testContextDeadline},
+	{"Context/CanceledMidway", testContextCanceledMidway},
+}
+
+// Run runs the shared store behaviour against stores from open. Run it with
+// -race to check the concurrency cases.
+func Run(t *testing.T, open Opener) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) { c.test(t, open) })
+	}
+}
+
+func get(t *testing.T, s store.Store, id string) *store.InviteRecord {
+	t.Helper()
+	rec, err := s.GetInvite(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec
+}
+
+// missing fails unless id is absent from s.
+func missing(t *testing.T, s store.Store, id string) {
+	t.Helper()
+	rec, err := s.GetInvite(context.Background(), id)
+	if !errors.Is(err, store.ErrNotFound) || rec != nil {
+		t.Fatalf("expected ErrNotFound for %s, got %+v, %v", id, rec, err)
+	}
+}
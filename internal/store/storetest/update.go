@@ -0,0 +1,190 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func testUpdateInvite(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	if err := s.RecordView(ctx, SeedID, store.View{At: time.Now().UTC()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	before := time.Now().UTC()
+	rec, err := s.UpdateInvite(ctx, SeedID, true, []string{"Георги"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rec.Accepted || rec.Declined {
+		t.Fatalf("expected accepted invite, got %+v", rec)
+	}
+	if len(rec.Additional) != 1 || rec.Additional[0] != "Георги" {
+		t.Fatalf("unexpected additional: %v", rec.Additional)
+	}
+	if rec.AcceptedAt == nil || rec.AcceptedAt.Before(before.Add(-time.Second)) {
+		t.Fatalf("expected accepted_at to be set to now, got %v", rec.AcceptedAt)
+	}
+
+	stored := get(t, s, SeedID)
+	if !stored.Accepted || len(stored.Additional) != 1 || stored.Views.Browser.Count != 1 {
+		t.Fatalf("expected the acceptance saved with the view kept, got %+v", stored)
+	}
+}
+
+func testUpdateInviteNotFound(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	rec, err := s.UpdateInvite(context.Background(), "nonexistent", true, nil)
+	if !errors.Is(err, store.ErrNotFound) || rec != nil {
+		t.Fatalf("expected ErrNotFound for nonexistent invite, got %+v, %v", rec, err)
+	}
+	missing(t, s, "nonexistent")
+}
+
+func testUpdateInviteAcceptedFalse(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	if _, err := s.UpdateInvite(context.Background(), SeedID, false, nil); !errors.Is(err, store.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition for accepted=false, got %v", err)
+	}
+	if get(t, s, SeedID).Accepted {
+		t.Fatal("expected rejected update to leave the invite unchanged")
+	}
+}
+
+func testUpdateInviteTooMany(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	_, err := s.UpdateInvite(context.Background(), SeedID, true, []string{"А", "Б", "В"})
+	var tooMany *store.TooManyGuestsError
+	if !errors.As(err, &tooMany) || !errors.Is(err, store.ErrTooManyGuests) {
+		t.Fatalf("expected TooManyGuestsError, got %v", err)
+	}
+	if tooMany.Got != 3 || tooMany.Max != 2 {
+		t.Fatalf("expected got=3 max=2, got got=%d max=%d", tooMany.Got, tooMany.Max)
+	}
+	if get(t, s, SeedID).Accepted {
+		t.Fatal("expected rejected update to leave the invite unchanged")
+	}
+}
+
+func testUpdateInviteNormalizes(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	rec, err := s.UpdateInvite(context.Background(), SeedID, true, []string{"  георги   димитров ", "ЕЛЕНА стоянова-петрова"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"Георги Димитров", "Елена Стоянова-Петрова"}
+	for i, name := range want {
+		if rec.Additional[i] != name {
+			t.Fatalf("expected additional[%d] %q, got %q", i, name, rec.Additional[i])
+		}
+	}
+}
+
+func testUpdateInviteDuplicates(t *testing.T, open Opener) {
+	tests := []struct {
+		name       string
+		additional []string
+		index      int
+		inPeople   bool
+	}{
+		{"repeated additional", []string{"Георги Димитров", "георги  димитров"}, 1, false},
+		{"already in people", []string{"ИВАН ПЕТРОВ"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Seeded(t, open)
+
+			_, err := s.UpdateInvite(context.Background(), SeedID, true, tt.additional)
+			var dup *store.DuplicateGuestError
+			if !errors.As(err, &dup) || !errors.Is(err, store.ErrDuplicateGuest) {
+				t.Fatalf("expected DuplicateGuestError, got %v", err)
+			}
+			if dup.Index != tt.index || dup.InPeople != tt.inPeople {
+				t.Fatalf("expected index %d inPeople %v, got %+v", tt.index, tt.inPeople, dup)
+			}
+			if get(t, s, SeedID).Accepted {
+				t.Fatal("expected rejected update to leave the invite unchanged")
+			}
+		})
+	}
+}
+
+func testUpdateInviteBlank(t *testing.T, open Opener) {
+	for _, name := range []string{"", "   ", "\t \n"} {
+		s := Seeded(t, open)
+
+		_, err := s.UpdateInvite(context.Background(), SeedID, true, []string{"Георги", name})
+		var blank *store.BlankGuestError
+		if !errors.As(err, &blank) || !errors.Is(err, store.ErrBlankGuest) || blank.Index != 1 {
+			t.Fatalf("%q: expected BlankGuestError at 1, got %v", name, err)
+		}
+		if get(t, s, SeedID).Accepted {
+			t.Fatal("expected rejected update to leave the invite unchanged")
+		}
+	}
+}
+
+func testUpdateInviteReaccept(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	if _, err := s.EditInvite(ctx, SeedID, func(r *store.InviteRecord) error {
+		r.Declined = true
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.UpdateInvite(ctx, SeedID, true, []string{"Георги", "Елена"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Reason: a second answer replaces the guest list rather than adding to it
+	rec, err := s.UpdateInvite(ctx, SeedID, true, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rec.Accepted || rec.Declined || len(rec.Additional) != 0 {
+		t.Fatalf("expected accepted invite without additional guests, got %+v", rec)
+	}
+}
+
+func testUpdateInviteDeadline(t *testing.T, open Opener) {
+	ctx := context.Background()
+	seed := map[string]store.InviteRecord{SeedID: {People: []string{"Тест"}, AdditionalCount: 1}}
+
+	s := open(t, store.WithRSVPDeadline(time.Now().Add(-time.Minute)))
+	if err := s.Seed(t.Context(), seed); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	if _, err := s.UpdateInvite(ctx, SeedID, true, nil); !errors.Is(err, store.ErrDeadlinePassed) {
+		t.Fatalf("expected ErrDeadlinePassed, got %v", err)
+	}
+	if get(t, s, SeedID).Accepted {
+		t.Fatal("expected late update to leave the invite unchanged")
+	}
+	// Reason: the deadline only closes guest replies; admins can still edit
+	rec, err := s.EditInvite(ctx, SeedID, func(r *store.InviteRecord) error {
+		r.Accepted = true
+		return nil
+	})
+	if err != nil || !rec.Accepted {
+		t.Fatalf("expected admin edit after the deadline to succeed, got %+v, %v", rec, err)
+	}
+
+	s = open(t, store.WithRSVPDeadline(time.Now().Add(time.Hour)))
+	if err := s.Seed(t.Context(), seed); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	if _, err := s.UpdateInvite(ctx, SeedID, true, nil); err != nil {
+		t.Fatalf("expected update before the deadline to succeed, got %v", err)
+	}
+}
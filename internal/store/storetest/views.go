@@ -0,0 +1,154 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+func testGetInvite(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	rec := get(t, s, SeedID)
+	if rec == nil {
+		t.Fatal("expected invite, got nil")
+	}
+	if len(rec.People) != 2 || rec.AdditionalCount != 2 {
+		t.Fatalf("expected 2 people and 2 additional, got %+v", rec)
+	}
+	if rec.Accepted || rec.AcceptedAt != nil {
+		t.Fatal("expected unanswered invite")
+	}
+
+	// Reason: reading must never record a view, however often it happens
+	get(t, s, SeedID)
+	if rec := get(t, s, SeedID); rec.Views.Opened() || rec.Views.Bot.Count != 0 {
+		t.Fatalf("expected no views after reads, got %+v", rec.Views)
+	}
+}
+
+func testGetInviteNotFound(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+
+	missing(t, s, "nonexistent")
+}
+
+func testReturnsCopies(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	rec := get(t, s, SeedID)
+	rec.People[0] = "Променен"
+	rec.AdditionalCount = 9
+	if rec := get(t, s, SeedID); rec.People[0] != "Иван Петров" || rec.AdditionalCount != 2 {
+		t.Fatalf("expected stored invite unaffected by changes to a read, got %+v", rec)
+	}
+
+	replaced := map[string]store.InviteRecord{"bbb-001": {People: []string{"Гост"}}}
+	if err := s.ReplaceAllInvites(ctx, replaced); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replaced["bbb-001"].People[0] = "Променен"
+	if rec := get(t, s, "bbb-001"); rec.People[0] != "Гост" {
+		t.Fatalf("expected stored invite unaffected by changes to the input, got %+v", rec)
+	}
+}
+
+func testCompactsLegacyViews(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+
+	first := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := s.ReplaceAllInvites(ctx, map[string]store.InviteRecord{
+		SeedID: {People: []string{"Тест"}, ViewedAt: []time.Time{first.Add(time.Hour), first, first.Add(time.Minute)}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := get(t, s, SeedID)
+	if rec.ViewedAt != nil || rec.Views.Browser.Count != 3 || !rec.Views.Browser.First.Equal(first) {
+		t.Fatalf("expected 3 compacted views from %v, got %+v", first, rec)
+	}
+}
+
+func testRecordView(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	views := []store.View{
+		{At: now},
+		{At: now.Add(time.Minute)}, // within the dedupe window
+		{At: now.Add(time.Minute), Bot: true},
+		{At: now.Add(time.Hour)},
+	}
+	for _, v := range views {
+		if err := s.RecordView(ctx, SeedID, v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := s.RecordView(ctx, "nonexistent", store.View{At: now}); err != nil {
+		t.Fatalf("expected missing invite to be ignored, got %v", err)
+	}
+	missing(t, s, "nonexistent")
+
+	rec := get(t, s, SeedID)
+	if rec.Views.Browser.Count != 2 || rec.Views.Bot.Count != 1 || len(rec.Views.Recent) != 3 {
+		t.Fatalf("expected 2 browser, 1 bot and 3 recent views, got %+v", rec.Views)
+	}
+	if !rec.Views.Browser.First.Equal(now) || !rec.Views.Browser.Last.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected first/last browser views %v/%v, got %+v", now, now.Add(time.Hour), rec.Views.Browser)
+	}
+}
+
+func testRecordViewPolicy(t *testing.T, open Opener) {
+	s := open(t, store.WithViewPolicy(store.ViewPolicy{MaxRecent: 2}))
+	ctx := context.Background()
+
+	if err := s.CreateInvite(ctx, SeedID, store.InviteRecord{People: []string{"Тест"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Now().UTC()
+	for i := 0; i < 3; i++ {
+		if err := s.RecordView(ctx, SeedID, store.View{At: now}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	rec := get(t, s, SeedID)
+	if rec.Views.Browser.Count != 3 || len(rec.Views.Recent) != 2 {
+		t.Fatalf("expected 3 views without dedupe and 2 kept, got %+v", rec.Views)
+	}
+}
+
+func testRecordViews(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	events := []store.ViewEvent{
+		{ID: SeedID, View: store.View{At: now}},
+		{ID: SeedID, View: store.View{At: now.Add(time.Second)}}, // deduplicated
+		{ID: SeedID, View: store.View{At: now, Bot: true}},
+		{ID: "nonexistent", View: store.View{At: now}},
+	}
+	counted, err := s.RecordViews(ctx, events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counted) != 2 || counted[0] != events[0] || counted[1] != events[2] {
+		t.Fatalf("expected the first browser and the bot view counted, got %+v", counted)
+	}
+
+	rec := get(t, s, SeedID)
+	if rec.Views.Browser.Count != 1 || rec.Views.Bot.Count != 1 {
+		t.Fatalf("expected 1 browser and 1 bot view, got %+v", rec.Views)
+	}
+
+	if counted, err := s.RecordViews(ctx, nil); err != nil || len(counted) != 0 {
+		t.Fatalf("expected empty batch to count nothing, got %+v, %v", counted, err)
+	}
+}
@@ -0,0 +1,289 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+var webhookTime = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func webhook(id string, events ...string) store.Webhook {
+	return store.Webhook{
+		ID:        id,
+		URL:       "https://example.com/" + id,
+		Secret:    "secret-" + id + "-0123456789",
+		Events:    events,
+		CreatedAt: webhookTime,
+	}
+}
+
+// delivery is a pending delivery to webhookID due at webhookTime plus due.
+func delivery(id, webhookID string, due time.Duration) store.Delivery {
+	return store.Delivery{
+		ID:            id,
+		WebhookID:     webhookID,
+		EventID:       "evt-" + id,
+		EventType:     "invite.accepted",
+		Payload:       []byte(`{"id":"evt-` + id + `"}`),
+		State:         store.DeliveryPending,
+		NextAttemptAt: webhookTime.Add(due),
+		CreatedAt:     webhookTime,
+		UpdatedAt:     webhookTime,
+	}
+}
+
+func deliveryIDs(list []store.Delivery) []string {
+	ids := make([]string, 0, len(list))
+	for _, d := range list {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+func putWebhooks(t *testing.T, s store.Store, hooks ...store.Webhook) {
+	t.Helper()
+	for _, w := range hooks {
+		if err := s.PutWebhook(context.Background(), w); err != nil {
+			t.Fatalf("failed to save webhook %s: %v", w.ID, err)
+		}
+	}
+}
+
+func testWebhooks(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+
+	if hooks, err := s.ListWebhooks(ctx); err != nil || len(hooks) != 0 {
+		t.Fatalf("expected no webhooks, got %v, %v", hooks, err)
+	}
+	if _, err := s.GetWebhook(ctx, "w1"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing webhook, got %v", err)
+	}
+
+	w2, w1 := webhook("w2", "invite.viewed"), webhook("w1", "invite.accepted", "invite.declined")
+	putWebhooks(t, s, w2, w1)
+	got, err := s.GetWebhook(ctx, "w1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*got, w1) {
+		t.Fatalf("expected %+v, got %+v", w1, *got)
+	}
+	hooks, err := s.ListWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(hooks, []store.Webhook{w1, w2}) {
+		t.Fatalf("expected w1 and w2 in ID order, got %+v", hooks)
+	}
+
+	w1.URL, w1.Events = "https://example.com/changed", []string{"invite.updated"}
+	putWebhooks(t, s, w1)
+	if got, _ := s.GetWebhook(ctx, "w1"); !reflect.DeepEqual(*got, w1) {
+		t.Fatalf("expected the webhook replaced with %+v, got %+v", w1, *got)
+	}
+
+	if err := s.DeleteWebhook(ctx, "w1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.GetWebhook(ctx, "w1"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected w1 deleted, got %v", err)
+	}
+	if err := s.DeleteWebhook(ctx, "w1"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	// Replacing the invites leaves the webhooks alone
+	if err := s.ReplaceAllInvites(ctx, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.GetWebhook(ctx, "w2"); err != nil {
+		t.Fatalf("expected w2 kept after replacing invites, got %v", err)
+	}
+}
+
+func testDeliveries(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+	putWebhooks(t, s, webhook("w1"), webhook("w2"))
+
+	err := s.AddDeliveries(ctx, []store.Delivery{
+		delivery("d1", "w1", 0),
+		delivery("d2", "w2", 0),
+		delivery("d3", "w1", time.Hour),
+		delivery("d4", "w1", -time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	due, err := s.DueDeliveries(ctx, webhookTime, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := deliveryIDs(due); !reflect.DeepEqual(got, []string{"d1", "d2", "d4"}) {
+		t.Fatalf("expected d1, d2 and d4 due oldest first, got %v", got)
+	}
+	if !reflect.DeepEqual(due[0], delivery("d1", "w1", 0)) {
+		t.Fatalf("expected %+v, got %+v", delivery("d1", "w1", 0), due[0])
+	}
+	if due, _ := s.DueDeliveries(ctx, webhookTime, 1); len(due) != 1 || due[0].ID != "d1" {
+		t.Fatalf("expected the limit to keep only d1, got %v", deliveryIDs(due))
+	}
+
+	sent := due[0]
+	sent.State, sent.Attempts, sent.LastStatus = store.DeliveryDelivered, 1, 204
+	sent.UpdatedAt = webhookTime.Add(time.Second)
+	if err := s.UpdateDelivery(ctx, sent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	retry := due[2]
+	retry.Attempts, retry.LastStatus, retry.LastError = 1, 500, "server error"
+	retry.NextAttemptAt = webhookTime.Add(time.Minute)
+	if err := s.UpdateDelivery(ctx, retry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if due, _ := s.DueDeliveries(ctx, webhookTime, 10); !reflect.DeepEqual(deliveryIDs(due), []string{"d2"}) {
+		t.Fatalf("expected only d2 due after the attempts, got %v", deliveryIDs(due))
+	}
+	if err := s.UpdateDelivery(ctx, delivery("missing", "w1", 0)); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound updating a missing delivery, got %v", err)
+	}
+
+	list, err := s.ListDeliveries(ctx, "w1", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := deliveryIDs(list); !reflect.DeepEqual(got, []string{"d4", "d3", "d1"}) {
+		t.Fatalf("expected w1's deliveries newest first, got %v", got)
+	}
+	if !reflect.DeepEqual(list[2], sent) || !reflect.DeepEqual(list[0], retry) {
+		t.Fatalf("expected the attempts saved, got %+v", list)
+	}
+	if list, _ := s.ListDeliveries(ctx, "w1", 2); len(list) != 2 {
+		t.Fatalf("expected the limit to keep 2 deliveries, got %d", len(list))
+	}
+}
+
+func testDeliveriesDeletedWebhook(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+	putWebhooks(t, s, webhook("w1"), webhook("w2"))
+
+	if err := s.AddDeliveries(ctx, []store.Delivery{delivery("d1", "w1", 0), delivery("d2", "w2", 0)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.DeleteWebhook(ctx, "w1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list, _ := s.ListDeliveries(ctx, "w1", 10); len(list) != 0 {
+		t.Fatalf("expected w1's deliveries deleted with it, got %v", deliveryIDs(list))
+	}
+
+	// Reason: an event published while its webhook is deleted must not leave
+	// a delivery nobody can list or remove
+	if err := s.AddDeliveries(ctx, []store.Delivery{delivery("d3", "w1", 0), delivery("d4", "w2", 0)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	due, err := s.DueDeliveries(ctx, webhookTime, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := deliveryIDs(due); !reflect.DeepEqual(got, []string{"d2", "d4"}) {
+		t.Fatalf("expected only w2's deliveries, got %v", got)
+	}
+}
+
+func testDeleteDeliveries(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+	putWebhooks(t, s, webhook("w1"))
+
+	old := func(id, state string, age time.Duration) store.Delivery {
+		d := delivery(id, "w1", 0)
+		d.State, d.UpdatedAt = state, webhookTime.Add(-age)
+		return d
+	}
+	err := s.AddDeliveries(ctx, []store.Delivery{
+		old("d1", store.DeliveryDelivered, 48*time.Hour),
+		old("d2", store.DeliveryFailed, 72*time.Hour),
+		old("d3", store.DeliveryPending, 72*time.Hour),
+		old("d4", store.DeliveryDelivered, time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deleted, err := s.DeleteDeliveries(ctx, webhookTime.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted, got %d", deleted)
+	}
+	list, _ := s.ListDeliveries(ctx, "w1", 10)
+	if got := deliveryIDs(list); !reflect.DeepEqual(got, []string{"d4", "d3"}) {
+		t.Fatalf("expected the pending and recent deliveries kept, got %v", got)
+	}
+}
+
+func testUpdateInviteWithOutbox(t *testing.T, open Opener) {
+	s := Seeded(t, open)
+	ctx := context.Background()
+	putWebhooks(t, s, webhook("w1"))
+
+	var before, after store.InviteRecord
+	outbox := func(id string, b, a store.InviteRecord) ([]store.Delivery, error) {
+		before, after = b, a
+		return []store.Delivery{delivery("d1", "w1", 0), delivery("d2", "gone", 0)}, nil
+	}
+	rec, err := s.UpdateInviteWithOutbox(ctx, SeedID, true, []string{"Петър Колев"}, outbox)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if before.Accepted || !after.Accepted || !reflect.DeepEqual(after, *rec) {
+		t.Fatalf("expected the invite before and after accepting, got %+v and %+v", before, after)
+	}
+	due, _ := s.DueDeliveries(ctx, webhookTime, 10)
+	if got := deliveryIDs(due); !reflect.DeepEqual(got, []string{"d1"}) {
+		t.Fatalf("expected d1 saved and the delivery to a missing webhook dropped, got %v", got)
+	}
+
+	// An outbox error aborts the change with it
+	failed := errors.New("outbox failed")
+	_, err = s.EditInviteWithOutbox(ctx, SeedID, func(r *store.InviteRecord) error {
+		r.Language = "en"
+		return nil
+	}, func(string, store.InviteRecord, store.InviteRecord) ([]store.Delivery, error) {
+		return nil, failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected the outbox error, got %v", err)
+	}
+	if got, _ := s.GetInvite(ctx, SeedID); got.Language != "" {
+		t.Fatalf("expected the edit rolled back, got language %q", got.Language)
+	}
+
+	_, err = s.EditInviteWithOutbox(ctx, SeedID, func(r *store.InviteRecord) error {
+		r.Additional[0] = "Петър Иванов"
+		return nil
+	}, func(_ string, b, a store.InviteRecord) ([]store.Delivery, error) {
+		before, after = b, a
+		return []store.Delivery{delivery("d3", "w1", 0)}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if before.Additional[0] != "Петър Колев" || after.Additional[0] != "Петър Иванов" {
+		t.Fatalf("expected before untouched by the edit, got %v and %v", before.Additional, after.Additional)
+	}
+	due, _ = s.DueDeliveries(ctx, webhookTime, 10)
+	if got := deliveryIDs(due); !reflect.DeepEqual(got, []string{"d1", "d3"}) {
+		t.Fatalf("expected d3 saved with the edit, got %v", got)
+	}
+}
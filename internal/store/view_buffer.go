@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ViewEvent is a view of the invite with ID, queued for a batch write.
+type ViewEvent struct {
+	ID   string
+	View View
+}
+
+// ViewWriter persists batches of views and returns the ones that were
+// counted; every store implements it.
+type ViewWriter interface {
+	RecordViews(ctx context.Context, events []ViewEvent) ([]ViewEvent, error)
+}
+
+var (
+	// ErrViewQueueFull is returned by ViewBuffer.RecordView when the queue is
+	// at capacity; the view is dropped.
+	ErrViewQueueFull = errors.New("view queue full")
+	// ErrViewBufferClosed is returned by ViewBuffer.RecordView after Shutdown.
+	ErrViewBufferClosed = errors.New("view buffer closed")
+)
+
+// IsDroppedView reports whether err from RecordView only means the view was
+// dropped, because the queue was full or the server is shutting down. Views
+// are best effort, so callers need not treat that as a failure.
+func IsDroppedView(err error) bool {
+	return errors.Is(err, ErrViewQueueFull) || errors.Is(err, ErrViewBufferClosed)
+}
+
+// ViewBufferOptions sizes a ViewBuffer. Zero values use the defaults.
+type ViewBufferOptions struct {
+	// QueueSize bounds the views waiting to be written (default 1024).
+	QueueSize int
+	// BatchSize is the most views written in one transaction (default 256).
+	BatchSize int
+	// FlushInterval is the longest a view waits before it is written
+	// (default 1s).
+	FlushInterval time.Duration
+}
+
+// ViewBuffer records views asynchronously: RecordView only queues the view
+// and a single goroutine writes them in batches, so page reads never wait
+// for the database writer lock.
+type ViewBuffer struct {
+	w    ViewWriter
+	opts ViewBufferOptions
+
+	// Reason: mu guards closing queue, so RecordView never sends on a
+	// closed channel
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan ViewEvent
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// NewViewBuffer starts the writer goroutine; call Shutdown to flush and stop it.
+func NewViewBuffer(w ViewWriter, opts ViewBufferOptions) *ViewBuffer {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+
+	b := &ViewBuffer{
+		w:     w,
+		opts:  opts,
+		queue: make(chan ViewEvent, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// RecordView queues view without blocking. When the queue is full the view
+// is dropped and ErrViewQueueFull returned.
+func (b *ViewBuffer) RecordView(_ context.Context, id string, view View) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrViewBufferClosed
+	}
+	select {
+	case b.queue <- ViewEvent{ID: id, View: view}:
+		return nil
+	default:
+		b.dropped.Add(1)
+		return ErrViewQueueFull
+	}
+}
+
+// Shutdown stops accepting views and waits until every queued view has been
+// written, or ctx is done.
+func (b *ViewBuffer) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *ViewBuffer) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]ViewEvent, 0, b.opts.BatchSize)
+	for {
+		select {
+		case e, ok := <-b.queue:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= b.opts.BatchSize {
+				b.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			b.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush writes batch; failures are logged and the views dropped, as view
+// tracking is best effort and must not hold up the queue.
+func (b *ViewBuffer) flush(batch []ViewEvent) {
+	if dropped := b.dropped.Swap(0); dropped > 0 {
+		log.WithField("dropped", dropped).Warn("view queue full, views dropped")
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if _, err := b.w.RecordViews(context.Background(), batch); err != nil {
+		log.WithError(err).WithField("views", len(batch)).Error("failed to write views")
+	}
+}
+
+// bufferedStore is an InviteStore whose views go through a ViewBuffer.
+type bufferedStore struct {
+	InviteStore
+	views *ViewBuffer
+}
+
+// WithViewBuffer returns s with RecordView served by views.
+func WithViewBuffer(s InviteStore, views *ViewBuffer) InviteStore {
+	return bufferedStore{InviteStore: s, views: views}
+}
+
+func (s bufferedStore) RecordView(ctx context.Context, id string, view View) error {
+	return s.views.RecordView(ctx, id, view)
+}
@@ -0,0 +1,208 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeViewWriter records the batches it is given. When gate is set each
+// write waits for it to be closed.
+type fakeViewWriter struct {
+	mu      sync.Mutex
+	batches [][]ViewEvent
+	gate    chan struct{}
+}
+
+func (w *fakeViewWriter) RecordViews(_ context.Context, events []ViewEvent) ([]ViewEvent, error) {
+	if w.gate != nil {
+		<-w.gate
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, append([]ViewEvent(nil), events...))
+	return events, nil
+}
+
+func (w *fakeViewWriter) sizes() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sizes := make([]int, len(w.batches))
+	for i, b := range w.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
+
+func recordN(t *testing.T, b *ViewBuffer, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := b.RecordView(context.Background(), fmt.Sprint(i), View{At: time.Now()}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestViewBuffer_FlushesOnShutdown(t *testing.T) {
+	w := &fakeViewWriter{}
+	b := NewViewBuffer(w, ViewBufferOptions{BatchSize: 2, FlushInterval: time.Hour})
+
+	recordN(t, b, 5)
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := w.sizes(); fmt.Sprint(got) != "[2 2 1]" {
+		t.Fatalf("expected batches [2 2 1], got %v", got)
+	}
+	if err := b.RecordView(context.Background(), "x", View{}); !errors.Is(err, ErrViewBufferClosed) {
+		t.Fatalf("expected ErrViewBufferClosed, got %v", err)
+	}
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected repeated Shutdown to succeed, got %v", err)
+	}
+}
+
+func TestViewBuffer_FlushInterval(t *testing.T) {
+	w := &fakeViewWriter{}
+	b := NewViewBuffer(w, ViewBufferOptions{FlushInterval: 10 * time.Millisecond})
+	t.Cleanup(func() { b.Shutdown(context.Background()) })
+
+	recordN(t, b, 1)
+	deadline := time.Now().Add(time.Second)
+	for len(w.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the view to be written by the periodic flush")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestViewBuffer_QueueFull(t *testing.T) {
+	w := &fakeViewWriter{gate: make(chan struct{})}
+	b := NewViewBuffer(w, ViewBufferOptions{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour})
+
+	// The writer goroutine takes the first view and blocks writing it
+	recordN(t, b, 1)
+	deadline := time.Now().Add(time.Second)
+	for len(b.queue) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the writer to take the first view")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	recordN(t, b, 1)
+	if err := b.RecordView(context.Background(), "x", View{}); !errors.Is(err, ErrViewQueueFull) {
+		t.Fatalf("expected ErrViewQueueFull, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected Shutdown to give up with the blocked writer, got %v", err)
+	}
+
+	close(w.gate)
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := w.sizes(); fmt.Sprint(got) != "[1 1]" {
+		t.Fatalf("expected both queued views written, got %v", got)
+	}
+}
+
+func TestWithViewBuffer(t *testing.T) {
+	s := seedTestStore(t)
+	views := NewViewBuffer(s, ViewBufferOptions{FlushInterval: time.Hour})
+	buffered := WithViewBuffer(s, views)
+	ctx := context.Background()
+
+	if err := buffered.RecordView(ctx, "aaa-001", View{At: time.Now()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec, _ := buffered.GetInvite(ctx, "aaa-001"); rec.Views.Opened() {
+		t.Fatal("expected the view to wait in the queue")
+	}
+
+	if err := views.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec, _ := buffered.GetInvite(ctx, "aaa-001"); !rec.Views.Opened() {
+		t.Fatal("expected the view to be written on shutdown")
+	}
+}
+
+// BenchmarkInviteRead measures a public invite read (GetInvite followed by
+// RecordView) under parallel load, with views written directly in their own
+// transaction or queued to a ViewBuffer. Dedupe is off so every view writes.
+func BenchmarkInviteRead(b *testing.B) {
+	const invites = 100
+
+	setup := func(b *testing.B) *BBoltStore {
+		s, err := NewBBoltStore(b.TempDir()+"/bench.db", WithViewPolicy(ViewPolicy{MaxRecent: 20}))
+		if err != nil {
+			b.Fatalf("failed to create store: %v", err)
+		}
+		b.Cleanup(func() { s.Close() })
+		seed := make(map[string]InviteRecord, invites)
+		for i := 0; i < invites; i++ {
+			seed[fmt.Sprint(i)] = InviteRecord{People: []string{"Гост"}}
+		}
+		if err := s.ReplaceAllInvites(context.Background(), seed); err != nil {
+			b.Fatalf("failed to seed: %v", err)
+		}
+		return s
+	}
+
+	run := func(b *testing.B, s InviteStore) {
+		ctx := context.Background()
+		var dropped atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				id := fmt.Sprint(i % invites)
+				if _, err := s.GetInvite(ctx, id); err != nil {
+					b.Error(err)
+				}
+				// Reason: a full queue drops views by design; they are
+				// reported so the latency is not read without them
+				err := s.RecordView(ctx, id, View{At: time.Now()})
+				if errors.Is(err, ErrViewQueueFull) {
+					dropped.Add(1)
+				} else if err != nil {
+					b.Error(err)
+				}
+				i++
+			}
+		})
+		b.ReportMetric(float64(dropped.Load())/float64(b.N), "dropped/op")
+	}
+
+	b.Run("direct", func(b *testing.B) {
+		run(b, setup(b))
+	})
+	b.Run("buffered", func(b *testing.B) {
+		s := setup(b)
+		views := NewViewBuffer(s, ViewBufferOptions{})
+		b.Cleanup(func() { views.Shutdown(context.Background()) })
+		run(b, WithViewBuffer(s, views))
+	})
+}
+
+func TestIsDroppedView(t *testing.T) {
+	for err, want := range map[error]bool{
+		nil:                 false,
+		ErrViewQueueFull:    true,
+		ErrViewBufferClosed: true,
+		fmt.Errorf("recording view: %w", ErrViewQueueFull):    true,
+		storageErr("recording view", errors.New("disk full")): false,
+	} {
+		if got := IsDroppedView(err); got != want {
+			t.Fatalf("IsDroppedView(%v): expected %v, got %v", err, want, got)
+		}
+	}
+}
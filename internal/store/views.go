@@ -0,0 +1,126 @@
+package store
+
+import (
+	"slices"
+	"time"
+)
+
+// View is one recorded opening of an invite.
+type View struct {
+	At time.Time `json:"at"`
+	// Bot marks crawlers and link-preview fetchers, which do not count as the
+	// guests opening their invite.
+	Bot bool `json:"bot,omitempty"`
+}
+
+// ViewStats counts the views of one kind.
+type ViewStats struct {
+	Count int        `json:"count"`
+	First *time.Time `json:"first,omitempty"`
+	Last  *time.Time `json:"last,omitempty"`
+}
+
+// Views is the compacted view history of an invite: counts and first/last
+// seen per kind, plus the most recent views.
+type Views struct {
+	Browser ViewStats `json:"browser"`
+	Bot     ViewStats `json:"bot"`
+	// Recent holds the latest views, oldest first, up to ViewPolicy.MaxRecent.
+	Recent []View `json:"recent,omitempty"`
+}
+
+// Opened reports whether a guest (not a bot) has viewed the invite.
+func (v Views) Opened() bool {
+	return v.Browser.Count > 0
+}
+
+// ViewPolicy controls how views are deduplicated and how much history is kept.
+type ViewPolicy struct {
+	// DedupeWindow drops a view when one of the same kind was counted less
+	// than this long before, so refreshes and retries count once.
+	DedupeWindow time.Duration
+	// MaxRecent caps Views.Recent.
+	MaxRecent int
+}
+
+// DefaultViewPolicy is used by stores not given another policy.
+var DefaultViewPolicy = ViewPolicy{DedupeWindow: 30 * time.Minute, MaxRecent: 20}
+
+// record adds view to the history and reports whether it was counted.
+func (v *Views) record(view View, p ViewPolicy) bool {
+	stats := &v.Browser
+	if view.Bot {
+		stats = &v.Bot
+	}
+	// Reason: compared in both directions, as batched views may arrive
+	// slightly out of order
+	if stats.Last != nil && absDuration(view.At.Sub(*stats.Last)) < p.DedupeWindow {
+		return false
+	}
+
+	stats.Count++
+	if stats.First == nil || view.At.Before(*stats.First) {
+		stats.First = timePtr(view.At)
+	}
+	if stats.Last == nil || view.At.After(*stats.Last) {
+		stats.Last = timePtr(view.At)
+	}
+
+	v.Recent = append(v.Recent, view)
+	if over := len(v.Recent) - max(p.MaxRecent, 0); over > 0 {
+		v.Recent = slices.Delete(v.Recent, 0, over)
+	}
+	return true
+}
+
+// applyViews records events on the invites returned by load, which gives nil
+// for a missing invite, and returns the changed invites and the views that
+// were counted. Several views of one invite are applied to the same record
+// so each is deduplicated against the previous.
+func applyViews(events []ViewEvent, p ViewPolicy, load func(id string) (*InviteRecord, error)) (map[string]*InviteRecord, []ViewEvent, error) {
+	loaded := make(map[string]*InviteRecord)
+	changed := make(map[string]*InviteRecord)
+	var counted []ViewEvent
+	for _, e := range events {
+		r, ok := loaded[e.ID]
+		if !ok {
+			var err error
+			if r, err = load(e.ID); err != nil {
+				return nil, nil, err
+			}
+			loaded[e.ID] = r
+		}
+		if r != nil && r.Views.record(e.View, p) {
+			changed[e.ID] = r
+			counted = append(counted, e)
+		}
+	}
+	return changed, counted, nil
+}
+
+// compactLegacyViews folds the unbounded viewed_at list written by earlier
+// versions into Views; it is persisted the next time the invite is saved.
+func (r *InviteRecord) compactLegacyViews() {
+	if len(r.ViewedAt) == 0 {
+		return
+	}
+	legacy := slices.Clone(r.ViewedAt)
+	slices.SortFunc(legacy, time.Time.Compare)
+	r.ViewedAt = nil
+
+	// Reason: the old list was never deduplicated, so every entry counts
+	for _, at := range legacy {
+		r.Views.record(View{At: at.UTC()}, ViewPolicy{MaxRecent: DefaultViewPolicy.MaxRecent})
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+// viewed returns the history of browser views at times, without dedupe.
+func viewed(times ...time.Time) Views {
+	var v Views
+	for _, at := range times {
+		v.record(View{At: at}, ViewPolicy{MaxRecent: DefaultViewPolicy.MaxRecent})
+	}
+	return v
+}
+
+func TestViews_Record(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	policy := ViewPolicy{DedupeWindow: 10 * time.Minute, MaxRecent: 3}
+
+	tests := []struct {
+		name      string
+		view      View
+		counted   bool
+		browser   int
+		bot       int
+		lastAfter time.Time
+	}{
+		{"first view", View{At: start}, true, 1, 0, start},
+		{"refresh within window", View{At: start.Add(5 * time.Minute)}, false, 1, 0, start},
+		{"bot is counted separately", View{At: start.Add(5 * time.Minute), Bot: true}, true, 1, 1, start},
+		{"out of order within window", View{At: start.Add(-5 * time.Minute)}, false, 1, 1, start},
+		{"after window", View{At: start.Add(10 * time.Minute)}, true, 2, 1, start.Add(10 * time.Minute)},
+		{"late arrival before first", View{At: start.Add(-time.Hour)}, true, 3, 1, start.Add(10 * time.Minute)},
+	}
+
+	var v Views
+	for _, tt := range tests {
+		if got := v.record(tt.view, policy); got != tt.counted {
+			t.Fatalf("%s: expected counted=%v, got %v", tt.name, tt.counted, got)
+		}
+		if v.Browser.Count != tt.browser || v.Bot.Count != tt.bot {
+			t.Fatalf("%s: expected %d browser/%d bot views, got %+v", tt.name, tt.browser, tt.bot, v)
+		}
+		if !v.Browser.Last.Equal(tt.lastAfter) {
+			t.Fatalf("%s: expected last browser view %v, got %v", tt.name, tt.lastAfter, v.Browser.Last)
+		}
+	}
+
+	if !v.Browser.First.Equal(start.Add(-time.Hour)) {
+		t.Fatalf("expected first browser view to move back, got %v", v.Browser.First)
+	}
+	if len(v.Recent) != policy.MaxRecent || !v.Recent[0].Bot {
+		t.Fatalf("expected the %d latest views starting with the bot, got %+v", policy.MaxRecent, v.Recent)
+	}
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Webhook is an admin-configured endpoint that is sent the events it
+// subscribes to.
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret keys the HMAC signature on every delivery. It is never shown
+	// back to admins.
+	Secret    string    `json:"secret"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Delivery states.
+const (
+	// DeliveryPending deliveries are in the outbox, waiting for their next
+	// attempt.
+	DeliveryPending = "pending"
+	// DeliveryDelivered deliveries got a 2xx answer.
+	DeliveryDelivered = "delivered"
+	// DeliveryFailed deliveries ran out of attempts.
+	DeliveryFailed = "failed"
+)
+
+// Delivery is one event on its way to one webhook. Pending deliveries form
+// the outbox; finished ones are kept as the delivery log until they are
+// deleted.
+type Delivery struct {
+	// ID orders deliveries by creation, oldest first.
+	ID        string `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// Payload is the exact request body sent on every attempt.
+	Payload       []byte    `json:"payload"`
+	State         string    `json:"state"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// LastStatus is the HTTP status of the last attempt; 0 if it got no
+	// answer.
+	LastStatus int       `json:"last_status,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// OutboxFunc returns the deliveries raised by the invite id changing from
+// before to after. It runs inside the write that saves the change, so it
+// must not call the store; an error aborts the change and is returned as it
+// is.
+type OutboxFunc func(id string, before, after InviteRecord) ([]Delivery, error)
+
+// WebhookStore keeps webhooks and their deliveries, apart from the invites:
+// replacing all invites leaves them alone.
+type WebhookStore interface {
+	// ListWebhooks returns every webhook in ID order.
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	// GetWebhook returns the webhook, or ErrNotFound.
+	GetWebhook(ctx context.Context, id string) (*Webhook, error)
+	// PutWebhook saves w under w.ID, replacing any earlier version.
+	PutWebhook(ctx context.Context, w Webhook) error
+	// DeleteWebhook removes the webhook and its deliveries, or returns
+	// ErrNotFound.
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// AddDeliveries puts deliveries in the outbox, all or none. Deliveries to
+	// a webhook that no longer exists are dropped.
+	AddDeliveries(ctx context.Context, deliveries []Delivery) error
+	// DueDeliveries returns up to limit pending deliveries whose next attempt
+	// is at or before now, oldest first.
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	// UpdateDelivery saves d after an attempt, or returns ErrNotFound when
+	// it was deleted meanwhile.
+	UpdateDelivery(ctx context.Context, d Delivery) error
+	// ListDeliveries returns up to limit deliveries to the webhook, newest
+	// first.
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]Delivery, error)
+	// DeleteDeliveries removes the finished deliveries last updated before
+	// cutoff and returns how many there were. Pending ones are kept.
+	DeleteDeliveries(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// due reports whether d should be attempted at now.
+func (d Delivery) due(now time.Time) bool {
+	return d.State == DeliveryPending && !d.NextAttemptAt.After(now)
+}
+
+// finishedBefore reports whether d is in the log and older than cutoff.
+func (d Delivery) finishedBefore(cutoff time.Time) bool {
+	return d.State != DeliveryPending && d.UpdatedAt.Before(cutoff)
+}
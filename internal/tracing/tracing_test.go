@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_None(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+}
+
+func TestSetup_FileExporterWritesSpans(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterFile, FilePath: path, ServiceName: "wedding-test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+	if !strings.Contains(string(data), "test-span") {
+		t.Fatalf("expected span in trace file, got %q", data)
+	}
+	if !strings.Contains(string(data), "wedding-test") {
+		t.Fatalf("expected service name in trace file, got %q", data)
+	}
+}
+
+func TestSetup_FileExporterInvalidPath(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: ExporterFile, FilePath: filepath.Join(os.DevNull, "x", "traces.json")})
+	if err == nil {
+		t.Fatal("expected error for invalid trace file path")
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "jaeger"})
+	if err == nil {
+		t.Fatal("expected error for unknown exporter")
+	}
+}
@@ -0,0 +1,80 @@
+// Package translit converts Bulgarian Cyrillic text to Latin script using the
+// official Streamlined System set out in the Bulgarian Transliteration Act.
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// table maps lower case Bulgarian letters to their Latin spelling.
+var table = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l",
+	'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s",
+	'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "sht", 'ъ': "a", 'ь': "y", 'ю': "yu", 'я': "ya",
+}
+
+// Bulgarian transliterates s. Characters outside the Bulgarian alphabet,
+// including Latin letters, are kept as they are.
+func Bulgarian(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		lower := unicode.ToLower(r)
+		// Reason: the Act spells a word-final "ия" as "ia", e.g. София → Sofia
+		if lower == 'и' && i+1 < len(runes) && unicode.ToLower(runes[i+1]) == 'я' && wordEnd(runes, i+2) {
+			b.WriteString(matchCase("i", runes, i))
+			b.WriteString(matchCase("a", runes, i+1))
+			i++
+			continue
+		}
+		lat, ok := table[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(matchCase(lat, runes, i))
+	}
+	return b.String()
+}
+
+// matchCase capitalises lat like runes[i]: digraphs become "Zh" in mixed
+// case words and "ZH" in words written entirely in capitals.
+func matchCase(lat string, runes []rune, i int) string {
+	if !unicode.IsUpper(runes[i]) {
+		return lat
+	}
+	if len(lat) > 1 && allCaps(runes, i) {
+		return strings.ToUpper(lat)
+	}
+	return strings.ToUpper(lat[:1]) + lat[1:]
+}
+
+// allCaps reports whether the word containing runes[i] has more than one
+// letter and no lower case letters.
+func allCaps(runes []rune, i int) bool {
+	start, end := i, i+1
+	for start > 0 && unicode.IsLetter(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && unicode.IsLetter(runes[end]) {
+		end++
+	}
+	if end-start < 2 {
+		return false
+	}
+	for _, r := range runes[start:end] {
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func wordEnd(runes []rune, i int) bool {
+	return i >= len(runes) || !unicode.IsLetter(runes[i])
+}
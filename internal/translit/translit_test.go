@@ -0,0 +1,31 @@
+package translit
+
+import "testing"
+
+func TestBulgarian(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Иван Петров", "Ivan Petrov"},
+		{"Жечка Цветкова", "Zhechka Tsvetkova"},
+		{"Щерю Хубенов", "Shteryu Hubenov"},
+		{"Ясен Йорданов", "Yasen Yordanov"},
+		{"Ъгъл Кьосев", "Agal Kyosev"},
+		{"Юлия Чолакова", "Yulia Cholakova"},
+		{"София", "Sofia"},
+		{"Илиян", "Iliyan"},
+		{"Мария-Магдалена", "Maria-Magdalena"},
+		{"ЖИВКОВ", "ZHIVKOV"},
+		{"ЮЛИЯ", "YULIA"},
+		{"Ж. Шишков", "Zh. Shishkov"},
+		{"John Smith", "John Smith"},
+		{"Ana Мария", "Ana Maria"},
+	}
+	for _, tt := range tests {
+		if got := Bulgarian(tt.in); got != tt.want {
+			t.Fatalf("Bulgarian(%q): expected %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package useragent classifies HTTP clients by their User-Agent header.
+package useragent
+
+import "strings"
+
+// bots are lowercase User-Agent fragments of link-preview fetchers, search
+// engines and other crawlers.
+var bots = []string{
+	"facebookexternalhit", // Facebook, Messenger
+	"facebot",
+	"twitterbot",
+	"viber",
+	"whatsapp",
+	"telegrambot",
+	"slackbot",
+	"slack-imgproxy",
+	"discordbot",
+	"linkedinbot",
+	"skypeuripreview",
+	"pinterest",
+	"redditbot",
+	"applebot",
+	"googlebot",
+	"bingbot",
+	"yandexbot",
+	"duckduckbot",
+	"vkshare",
+	"embedly",
+	"bot/",
+	"crawler",
+	"spider",
+}
+
+// IsBot reports whether ua belongs to a crawler or link-preview fetcher
+// rather than a person's browser.
+func IsBot(ua string) bool {
+	ua = strings.ToLower(ua)
+	for _, b := range bots {
+		if strings.Contains(ua, b) {
+			return true
+		}
+	}
+	return false
+}
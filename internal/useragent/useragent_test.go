@@ -0,0 +1,26 @@
+package useragent
+
+import "testing"
+
+func TestIsBot(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want bool
+	}{
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"Twitterbot/1.0", true},
+		{"Viber/19.8.0.2 CFNetwork/1390 Darwin/22.0.0", true},
+		{"WhatsApp/2.23.20.0 A", true},
+		{"TelegramBot (like TwitterBot)", true},
+		{"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36", false},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsBot(tt.ua); got != tt.want {
+			t.Fatalf("%q: expected %v, got %v", tt.ua, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package webhook delivers invite events to admin-configured HTTP endpoints.
+// Events are first saved as deliveries in the store's outbox, then posted
+// by a background Dispatcher with an HMAC signature, retried with backoff
+// until they succeed or run out of attempts, and kept as the delivery log.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+// maxRetryDelay caps the backoff between attempts.
+const maxRetryDelay = time.Hour
+
+// batchSize is the most due deliveries read from the outbox at once.
+const batchSize = 50
+
+// Options configures a Dispatcher. Zero values fall back to the defaults
+// noted on each field.
+type Options struct {
+	// Timeout bounds each delivery attempt (default 10s).
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is tried before it is marked
+	// failed (default 8).
+	MaxAttempts int
+	// RetryBase is the delay before the first retry; it doubles with every
+	// further attempt, up to an hour (default 30s).
+	RetryBase time.Duration
+	// PollInterval is how often the outbox is checked for due retries
+	// (default 5s). New events are sent straight away.
+	PollInterval time.Duration
+	// Retention is how long finished deliveries stay in the log (default 7
+	// days).
+	Retention time.Duration
+	// Client sends the requests (default a client that does not follow
+	// redirects).
+	Client *http.Client
+}
+
+func (o Options) withDefaults() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 8
+	}
+	if o.RetryBase <= 0 {
+		o.RetryBase = 30 * time.Second
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 5 * time.Second
+	}
+	if o.Retention <= 0 {
+		o.Retention = 7 * 24 * time.Hour
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{
+			// Reason: a redirect would resend the signed body somewhere the
+			// admin never configured
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	return o
+}
+
+// Dispatcher publishes events to the outbox and delivers them in the
+// background. Deliveries are sent one at a time, oldest first.
+type Dispatcher struct {
+	store store.WebhookStore
+	opts  Options
+	now   func() time.Time
+
+	wake     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDispatcher starts delivering the outbox in s until ctx is cancelled or
+// Stop is called. Deliveries still pending then are sent after a restart.
+func NewDispatcher(ctx context.Context, s store.WebhookStore, opts Options) *Dispatcher {
+	d := &Dispatcher{
+		store: s,
+		opts:  opts.withDefaults(),
+		now:   func() time.Time { return time.Now().UTC() },
+		wake:  make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go d.run(ctx)
+	return d
+}
+
+// Stop ends the background delivery, after the attempt in progress, and
+// waits for it to exit. It is safe to call more than once.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+	<-d.done
+}
+
+// nudge makes the background loop check the outbox now.
+func (d *Dispatcher) nudge() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer close(d.done)
+
+	poll := time.NewTicker(d.opts.PollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(min(d.opts.Retention, time.Hour))
+	defer prune.Stop()
+
+	for {
+		d.deliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stop:
+			return
+		case <-poll.C:
+		case <-d.wake:
+		case <-prune.C:
+			d.prune(context.WithoutCancel(ctx))
+		}
+	}
+}
+
+// deliverDue attempts every due delivery, a batch at a time, until none
+// are left or the dispatcher is stopping.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	// Reason: stopping must not cut an attempt short, or a delivery the
+	// receiver got could be saved as failed; stopping is checked between
+	// attempts instead
+	work := context.WithoutCancel(ctx)
+	for {
+		due, err := d.store.DueDeliveries(work, d.now(), batchSize)
+		if err != nil {
+			log.WithError(err).Error("failed to read webhook outbox")
+			return
+		}
+		progressed := false
+		for _, dl := range due {
+			if d.stopping(ctx) {
+				return
+			}
+			if d.attempt(work, dl) {
+				progressed = true
+			}
+		}
+		// Reason: when no delivery in a full batch could be saved, reading
+		// again would return the same batch at once; the next poll retries
+		if len(due) < batchSize || !progressed {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) stopping(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-d.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// attempt sends dl once and saves the outcome: delivered on a 2xx answer,
+// otherwise scheduled for a retry, or failed after MaxAttempts. It reports
+// whether dl is no longer due as it was, false when the store failed.
+func (d *Dispatcher) attempt(ctx context.Context, dl store.Delivery) bool {
+	logger := log.WithFields(log.Fields{"delivery_id": dl.ID, "webhook_id": dl.WebhookID, "event": dl.EventType})
+
+	hook, err := d.store.GetWebhook(ctx, dl.WebhookID)
+	if errors.Is(err, store.ErrNotFound) {
+		// The webhook was deleted meanwhile, and its deliveries with it
+		return true
+	}
+	if err != nil {
+		logger.WithError(err).Error("failed to load webhook")
+		return false
+	}
+
+	status, sendErr := d.send(ctx, hook, dl)
+	now := d.now()
+	dl.Attempts++
+	dl.LastStatus = status
+	dl.LastError = ""
+	dl.UpdatedAt = now
+	switch {
+	case sendErr == nil:
+		dl.State = store.DeliveryDelivered
+		logger.WithField("status", status).Info("webhook delivered")
+	case dl.Attempts >= d.opts.MaxAttempts:
+		dl.State = store.DeliveryFailed
+		dl.LastError = sendErr.Error()
+		logger.WithError(sendErr).WithField("attempts", dl.Attempts).Error("webhook delivery failed, giving up")
+	default:
+		dl.LastError = sendErr.Error()
+		dl.NextAttemptAt = now.Add(d.retryDelay(dl.Attempts))
+		logger.WithError(sendErr).WithFields(log.Fields{
+			"attempts":   dl.Attempts,
+			"next_retry": dl.NextAttemptAt,
+		}).Warn("webhook delivery failed, will retry")
+	}
+
+	if err := d.store.UpdateDelivery(ctx, dl); err != nil && !errors.Is(err, store.ErrNotFound) {
+		logger.WithError(err).Error("failed to save webhook delivery")
+		return false
+	}
+	return true
+}
+
+// send posts dl's payload to hook and returns the response status, or 0
+// when there was no response.
+func (d *Dispatcher) send(ctx context.Context, hook *store.Webhook, dl store.Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wedding-webhooks/1")
+	req.Header.Set(EventHeader, dl.EventType)
+	req.Header.Set(DeliveryHeader, dl.ID)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, d.now(), dl.Payload))
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	// Reason: draining lets the connection be reused for the next delivery
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay is the wait after the given number of failed attempts:
+// RetryBase doubled for each attempt after the first, up to maxRetryDelay.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.opts.RetryBase
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// prune deletes finished deliveries older than Retention from the log.
+func (d *Dispatcher) prune(ctx context.Context) {
+	deleted, err := d.store.DeleteDeliveries(ctx, d.now().Add(-d.opts.Retention))
+	if err != nil {
+		log.WithError(err).Error("failed to prune webhook delivery log")
+		return
+	}
+	if deleted > 0 {
+		log.WithField("deleted", deleted).Info("pruned webhook delivery log")
+	}
+}
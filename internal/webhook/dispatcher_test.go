@@ -0,0 +1,288 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dimitarkovachev/wedding/internal/events"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+const testSecret = "secret-0123456789"
+
+// receiver is a stand-in webhook endpoint that answers with the statuses
+// in order, then 204, and keeps every request it verified.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	invalid  atomic.Int32
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := Verify(testSecret, r.Header.Get(SignatureHeader), body, time.Now(), time.Minute); err != nil {
+		rc.invalid.Add(1)
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusNoContent
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+func setupDispatcher(t *testing.T, opts Options, statuses ...int) (*Dispatcher, *store.MemoryStore, *receiver) {
+	t.Helper()
+	rc := &receiver{statuses: statuses}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	s := store.NewMemoryStore()
+	err := s.PutWebhook(context.Background(), store.Webhook{
+		ID:        "w1",
+		URL:       srv.URL,
+		Secret:    testSecret,
+		Events:    []string{events.InviteAccepted},
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("failed to save webhook: %v", err)
+	}
+
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 5 * time.Millisecond
+	}
+	d := NewDispatcher(context.Background(), s, opts)
+	t.Cleanup(d.Stop)
+	return d, s, rc
+}
+
+// waitForDelivery waits until the only delivery to w1 is no longer pending.
+func waitForDelivery(t *testing.T, s *store.MemoryStore) store.Delivery {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		list, err := s.ListDeliveries(context.Background(), "w1", 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(list) == 1 && list[0].State != store.DeliveryPending {
+			return list[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the delivery to finish, got %+v", list)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcher_Delivers(t *testing.T) {
+	d, s, rc := setupDispatcher(t, Options{})
+
+	e := events.New(events.InviteAccepted, "aaa-001", time.Now().UTC())
+	if err := d.Publish(context.Background(), e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dl := waitForDelivery(t, s)
+	if dl.State != store.DeliveryDelivered || dl.Attempts != 1 || dl.LastStatus != http.StatusNoContent {
+		t.Fatalf("expected delivered on the first attempt, got %+v", dl)
+	}
+
+	if n := rc.invalid.Load(); n != 0 {
+		t.Fatalf("expected valid signatures, got %d invalid", n)
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	req := rc.requests[0]
+	if req.Header.Get(EventHeader) != events.InviteAccepted || req.Header.Get(DeliveryHeader) != dl.ID {
+		t.Fatalf("expected event and delivery headers, got %v", req.Header)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected application/json, got %q", got)
+	}
+	var got events.Event
+	if err := json.Unmarshal(rc.bodies[0], &got); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if got.ID != e.ID || got.Type != e.Type || got.Data.InviteID != "aaa-001" {
+		t.Fatalf("expected %+v posted, got %+v", e, got)
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	d, s, rc := setupDispatcher(t, Options{RetryBase: time.Millisecond},
+		http.StatusInternalServerError, http.StatusServiceUnavailable)
+
+	if err := d.Publish(context.Background(), events.New(events.InviteAccepted, "aaa-001", time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dl := waitForDelivery(t, s)
+	if dl.State != store.DeliveryDelivered || dl.Attempts != 3 || dl.LastError != "" {
+		t.Fatalf("expected delivered on the third attempt, got %+v", dl)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if id := rc.requests[0].Header.Get(DeliveryHeader); id != rc.requests[2].Header.Get(DeliveryHeader) {
+		t.Fatalf("expected the same delivery ID on every attempt, got %s", id)
+	}
+}
+
+func TestDispatcher_GivesUp(t *testing.T) {
+	d, s, rc := setupDispatcher(t, Options{RetryBase: time.Millisecond, MaxAttempts: 2},
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusBadGateway)
+
+	if err := d.Publish(context.Background(), events.New(events.InviteAccepted, "aaa-001", time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dl := waitForDelivery(t, s)
+	if dl.State != store.DeliveryFailed || dl.Attempts != 2 || dl.LastStatus != http.StatusBadGateway || dl.LastError == "" {
+		t.Fatalf("expected failed after 2 attempts, got %+v", dl)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := rc.count(); n != 2 {
+		t.Fatalf("expected no attempts after giving up, got %d", n)
+	}
+}
+
+func TestDispatcher_Unreachable(t *testing.T) {
+	d, s, _ := setupDispatcher(t, Options{MaxAttempts: 1})
+	hook, _ := s.GetWebhook(context.Background(), "w1")
+	hook.URL = "http://127.0.0.1:1/"
+	if err := s.PutWebhook(context.Background(), *hook); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := d.Publish(context.Background(), events.New(events.InviteAccepted, "aaa-001", time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dl := waitForDelivery(t, s)
+	if dl.State != store.DeliveryFailed || dl.LastStatus != 0 || dl.LastError == "" {
+		t.Fatalf("expected failed without a status, got %+v", dl)
+	}
+}
+
+func TestPublish_Subscriptions(t *testing.T) {
+	d, s, _ := setupDispatcher(t, Options{})
+	ctx := context.Background()
+
+	err := d.Publish(ctx,
+		events.New(events.InviteViewed, "aaa-001", time.Now()),
+		events.New(events.InviteDeclined, "aaa-001", time.Now()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list, _ := s.ListDeliveries(ctx, "w1", 10); len(list) != 0 {
+		t.Fatalf("expected no deliveries for unsubscribed events, got %+v", list)
+	}
+
+	if err := d.Publish(ctx, events.New(events.InviteAccepted, "aaa-001", time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dl := waitForDelivery(t, s); dl.EventType != events.InviteAccepted {
+		t.Fatalf("expected the accepted event delivered, got %+v", dl)
+	}
+}
+
+func TestDispatcher_PrunesLog(t *testing.T) {
+	d, s, rc := setupDispatcher(t, Options{Retention: 20 * time.Millisecond})
+
+	if err := d.Publish(context.Background(), events.New(events.InviteAccepted, "aaa-001", time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		list, _ := s.ListDeliveries(context.Background(), "w1", 10)
+		if len(list) == 0 && rc.count() == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the delivered entry pruned, got %+v", list)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// brokenHooks fails every GetWebhook and counts the outbox reads.
+type brokenHooks struct {
+	*store.MemoryStore
+	reads atomic.Int32
+}
+
+func (s *brokenHooks) GetWebhook(context.Context, string) (*store.Webhook, error) {
+	return nil, errors.New("disk I/O error")
+}
+
+func (s *brokenHooks) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]store.Delivery, error) {
+	s.reads.Add(1)
+	return s.MemoryStore.DueDeliveries(ctx, now, limit)
+}
+
+func TestDispatcher_StopsOnBatchWithoutProgress(t *testing.T) {
+	_, mem, _ := setupDispatcher(t, Options{PollInterval: time.Hour})
+	now := time.Now().UTC()
+	var due []store.Delivery
+	for i := range batchSize {
+		due = append(due, store.Delivery{
+			ID: fmt.Sprintf("d%03d", i), WebhookID: "w1", EventType: events.InviteAccepted,
+			State: store.DeliveryPending, NextAttemptAt: now, CreatedAt: now, UpdatedAt: now,
+		})
+	}
+	if err := mem.AddDeliveries(context.Background(), due); err != nil {
+		t.Fatalf("failed to add deliveries: %v", err)
+	}
+
+	s := &brokenHooks{MemoryStore: mem}
+	d := &Dispatcher{store: s, opts: Options{}.withDefaults(), now: func() time.Time { return time.Now().UTC() }, stop: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		d.deliverDue(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		close(d.stop)
+		t.Fatalf("expected deliverDue to give up on a batch it could not deliver, read the outbox %d times", s.reads.Load())
+	}
+	if n := s.reads.Load(); n != 1 {
+		t.Fatalf("expected one outbox read, got %d", n)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	d := &Dispatcher{opts: Options{RetryBase: 30 * time.Second}}
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		8:  maxRetryDelay,
+		50: maxRetryDelay,
+	} {
+		if got := d.retryDelay(attempts); got != want {
+			t.Fatalf("attempt %d: expected %s, got %s", attempts, want, got)
+		}
+	}
+}
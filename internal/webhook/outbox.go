@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/dimitarkovachev/wedding/internal/events"
+	"github.com/dimitarkovachev/wedding/internal/store"
+)
+
+var _ events.Outbox = (*Dispatcher)(nil)
+
+// Publish saves one delivery per event and subscribed webhook in the outbox,
+// then wakes the background loop to send them. Once Publish returns, the
+// deliveries survive a restart.
+func (d *Dispatcher) Publish(ctx context.Context, evs ...events.Event) error {
+	hooks, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("listing webhooks: %w", err)
+	}
+	deliveries, err := d.deliveries(hooks, evs)
+	if err != nil || len(deliveries) == 0 {
+		return err
+	}
+	if err := d.store.AddDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("adding deliveries: %w", err)
+	}
+	d.nudge()
+	return nil
+}
+
+// Prepare lists the webhooks before an invite change is saved, so the
+// deliveries for its events can be saved with it.
+func (d *Dispatcher) Prepare(ctx context.Context) (func([]events.Event) ([]store.Delivery, error), error) {
+	hooks, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing webhooks: %w", err)
+	}
+	return func(evs []events.Event) ([]store.Delivery, error) {
+		return d.deliveries(hooks, evs)
+	}, nil
+}
+
+// Saved wakes the background loop to send the deliveries just saved.
+func (d *Dispatcher) Saved() {
+	d.nudge()
+}
+
+// deliveries returns one pending delivery per event and webhook in hooks
+// subscribed to it.
+func (d *Dispatcher) deliveries(hooks []store.Webhook, evs []events.Event) ([]store.Delivery, error) {
+	now := d.now()
+	var deliveries []store.Delivery
+	for _, e := range evs {
+		var payload []byte
+		for _, h := range hooks {
+			if !slices.Contains(h.Events, e.Type) {
+				continue
+			}
+			if payload == nil {
+				var err error
+				if payload, err = json.Marshal(e); err != nil {
+					return nil, fmt.Errorf("encoding event %s: %w", e.ID, err)
+				}
+			}
+			deliveries = append(deliveries, store.Delivery{
+				ID:            events.NewID(),
+				WebhookID:     h.ID,
+				EventID:       e.ID,
+				EventType:     e.Type,
+				Payload:       payload,
+				State:         store.DeliveryPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			})
+		}
+	}
+	return deliveries, nil
+}
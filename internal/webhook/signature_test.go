@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"invite.accepted"}`)
+	header := Sign("secret-0123456789", now, body)
+
+	if err := Verify("secret-0123456789", header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatalf("expected a valid signature, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   string
+		now    time.Time
+	}{
+		{"wrong secret", "other-secret-0123", header, string(body), now},
+		{"changed body", "secret-0123456789", header, `{"type":"invite.declined"}`, now},
+		{"stale", "secret-0123456789", header, string(body), now.Add(10 * time.Minute)},
+		{"from the future", "secret-0123456789", header, string(body), now.Add(-10 * time.Minute)},
+		{"missing", "secret-0123456789", "", string(body), now},
+		{"no timestamp", "secret-0123456789", "v1=abc", string(body), now},
+		{"no signature", "secret-0123456789", "t=1777636800", string(body), now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, []byte(tt.body), tt.now, 5*time.Minute)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
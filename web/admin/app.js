@@ -0,0 +1,157 @@
+var pageSize = 50;
+var nextCursor = '';
+var listedCount = 0;
+
+function setStatus(msg, isError) {
+    var el = document.getElementById('status');
+    el.className = isError ? 'error' : 'success';
+    el.textContent = msg;
+}
+
+function getJSON(url) {
+    return fetch(url).then(function(r) {
+        if (!r.ok) return r.json().then(function(b) { throw new Error(errorText(b) || 'HTTP ' + r.status); });
+        return r.json();
+    });
+}
+
+function listURL(cursor, limit) {
+    var params = new URLSearchParams();
+    var filters = { q: 'search', status: 'statusFilter', sort: 'sort', order: 'order' };
+    Object.keys(filters).forEach(function(name) {
+        var v = document.getElementById(filters[name]).value.trim();
+        if (v) params.set(name, v);
+    });
+    params.set('limit', limit);
+    if (cursor) params.set('cursor', cursor);
+    return '/admin/invites?' + params.toString();
+}
+
+// fetchAllInvites follows every page and rebuilds the map PUT /admin/invites expects.
+function fetchAllInvites() {
+    var all = {};
+    function next(cursor) {
+        var url = '/admin/invites?limit=500' + (cursor ? '&cursor=' + encodeURIComponent(cursor) : '');
+        return getJSON(url).then(function(page) {
+            page.items.forEach(function(e) { all[e.id] = e.invite; });
+            return page.next_cursor ? next(page.next_cursor) : all;
+        });
+    }
+    return next('');
+}
+
+function loadRead() {
+    setStatus('Loading...', false);
+    nextCursor = '';
+    listedCount = 0;
+    document.getElementById('content').innerHTML =
+        '<table id="invites"><tr><th>ID</th><th>Status</th><th>People</th><th>Additional</th>' +
+        '<th>Additional Count</th><th>Last Viewed</th><th>Accepted At</th><th></th></tr></table>' +
+        '<button id="btnMore" onclick="loadMore()" hidden>Load more</button>';
+    loadMore();
+}
+
+function loadMore() {
+    getJSON(listURL(nextCursor, pageSize))
+        .then(function(page) {
+            appendRows(page.items);
+            listedCount += page.items.length;
+            nextCursor = page.next_cursor || '';
+            document.getElementById('btnMore').hidden = !nextCursor;
+            setStatus(listedCount + ' invites shown' + (nextCursor ? ', more available' : ''), false);
+        })
+        .catch(function(err) { setStatus('Failed to load: ' + err.message, true); });
+}
+
+function appendRows(items) {
+    var html = '';
+    for (var i = 0; i < items.length; i++) {
+        var r = items[i].invite;
+        var viewed = r.views && r.views.browser.last || '';
+        html += '<tr><td>' + esc(items[i].id) + '</td><td>' + esc(items[i].status) +
+            '</td><td>' + (r.people || []).map(esc).join('<br>') +
+            '</td><td>' + (r.additional || []).map(esc).join('<br>') + '</td><td>' + r.additional_count +
+            '</td><td>' + formatTime(viewed) + '</td><td>' + formatTime(r.accepted_at) +
+            '</td><td><button data-id="' + escAttr(items[i].id) + '" onclick="openEditor(this.dataset.id)">Edit</button></td></tr>';
+    }
+    document.getElementById('invites').insertAdjacentHTML('beforeend', html);
+}
+
+function formatTime(t) {
+    return t ? esc(new Date(t).toLocaleString()) : '';
+}
+
+function loadDuplicates() {
+    setStatus('Loading...', false);
+    fetch('/admin/reports/duplicates')
+        .then(function(r) {
+            if (!r.ok) throw new Error('HTTP ' + r.status);
+            return r.json();
+        })
+        .then(function(report) {
+            setStatus(report.pairs.length + ' probable duplicates (similarity >= ' + report.min_similarity + ')', false);
+            var html = '<table><tr><th>Similarity</th><th>Guest</th><th>Possible duplicate</th></tr>';
+            for (var i = 0; i < report.pairs.length; i++) {
+                var p = report.pairs[i];
+                html += '<tr><td>' + p.similarity.toFixed(2) + '</td><td>' + guestRef(p.first) +
+                    '</td><td>' + guestRef(p.second) + '</td></tr>';
+            }
+            html += '</table>';
+            document.getElementById('content').innerHTML = html;
+        })
+        .catch(function(err) { setStatus('Failed to load: ' + err.message, true); });
+}
+
+function guestRef(g) {
+    return esc(g.name) + '<br><small>' + esc(g.invite_id) + ' / ' + esc(g.field) + '[' + g.index + ']</small>';
+}
+
+function loadEdit() {
+    setStatus('Loading...', false);
+    fetchAllInvites()
+        .then(function(data) {
+            setStatus('', false);
+            var html = '<textarea id="editor">' + esc(JSON.stringify(data, null, 2)) + '</textarea>' +
+                '<br><button onclick="submitUpdate()">Update</button>';
+            document.getElementById('content').innerHTML = html;
+        })
+        .catch(function(err) { setStatus('Failed to load: ' + err.message, true); });
+}
+
+function submitUpdate() {
+    var text = document.getElementById('editor').value;
+    var parsed;
+    try { parsed = JSON.parse(text); } catch (e) {
+        setStatus('Invalid JSON: ' + e.message, true);
+        return;
+    }
+    setStatus('Updating...', false);
+    fetch('/admin/invites', {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(parsed)
+    })
+    .then(function(r) {
+        if (!r.ok) return r.json().then(function(b) { throw new Error(errorText(b) || 'HTTP ' + r.status); });
+        return r.json();
+    })
+    .then(function() { setStatus('Updated successfully', false); })
+    .catch(function(err) { setStatus('Update failed: ' + err.message, true); });
+}
+
+function errorText(b) {
+    var fields = (b.fields || []).map(function(f) { return f.pointer + ': ' + f.message; });
+    return [b.message].concat(fields).join('; ');
+}
+
+function esc(s) {
+    var d = document.createElement('div');
+    d.textContent = s;
+    return d.innerHTML;
+}
+
+function escAttr(s) {
+    return esc(s).replace(/"/g, '&quot;');
+}
+
+loadRead();
@@ -0,0 +1,218 @@
+// Per-invite form editor. Validation mirrors the InviteInput schema and the
+// server's guest name rules, which are fetched from /admin/name-rules.
+
+var nameRules = null;
+var editing = null; // { id, invite } of the invite being edited; id is null for a new invite
+
+function loadNameRules() {
+    if (nameRules) return Promise.resolve(nameRules);
+    return getJSON('/admin/name-rules').then(function(r) {
+        // Reason: Go writes scripts as \p{Cyrillic}, JavaScript needs \p{Script=Cyrillic}
+        var cls = r.scripts.map(function(s) { return '\\p{Script=' + s + '}'; }).join('') +
+            Array.from(r.extra_chars).map(function(c) { return c.replace(/[\\\]\[^-]/g, '\\$&'); }).join('');
+        nameRules = { regex: new RegExp('^[' + cls + ']+$', 'u'), maxLength: r.max_length, pattern: r.pattern };
+        return nameRules;
+    });
+}
+
+// normalizeName matches the server: trim and collapse inner whitespace.
+function normalizeName(s) {
+    return s.trim().split(/\s+/).filter(Boolean).join(' ');
+}
+
+function openEditor(id) {
+    setStatus('Loading...', false);
+    var load = id === null
+        ? Promise.resolve({ id: null, invite: { people: [''], additional_count: 0, additional: [], accepted: false } })
+        : getJSON('/admin/invites/' + encodeURIComponent(id)).then(function(e) { return { id: e.id, invite: e.invite }; });
+    Promise.all([load, loadNameRules()])
+        .then(function(results) {
+            editing = results[0];
+            setStatus('', false);
+            renderEditor();
+        })
+        .catch(function(err) { setStatus('Failed to load: ' + err.message, true); });
+}
+
+function rsvpOf(inv) {
+    if (inv.accepted) return 'accepted';
+    if (inv.declined) return 'declined';
+    return 'none';
+}
+
+function renderEditor() {
+    var inv = editing.invite;
+    var rsvp = rsvpOf(inv);
+    var html = '<h2>' + (editing.id ? 'Invite ' + esc(editing.id) : 'New invite') + '</h2>' +
+        '<form id="inviteForm" onsubmit="saveInvite(); return false;" novalidate>' +
+        '<fieldset><legend>People</legend><div id="people"></div>' +
+        '<button type="button" onclick="addRow(\'people\', \'\')">Add person</button>' +
+        '<span class="field-error" data-error="/people"></span></fieldset>' +
+        '<fieldset><legend>Additional guests</legend>' +
+        '<div class="row">Allowed: <input id="additionalCount" type="number" min="0" step="1" value="' + inv.additional_count + '" data-pointer="/additional_count">' +
+        '<span class="field-error" data-error="/additional_count"></span></div>' +
+        '<div id="additional"></div>' +
+        '<button type="button" onclick="addRow(\'additional\', \'\')">Add guest</button></fieldset>' +
+        '<fieldset><legend>Answer</legend>' +
+        ['none', 'accepted', 'declined'].map(function(v) {
+            return '<label><input type="radio" name="rsvp" value="' + v + '"' + (rsvp === v ? ' checked' : '') + '> ' +
+                { none: 'No answer', accepted: 'Accepted', declined: 'Declined' }[v] + '</label> ';
+        }).join('') +
+        (inv.accepted_at ? '<div><small>Accepted at ' + formatTime(inv.accepted_at) + '</small></div>' : '') +
+        '</fieldset>' +
+        '<fieldset><legend>Language</legend><select id="language" data-pointer="/language">' +
+        '<option value="">Follow the browser</option><option value="bg">Bulgarian</option><option value="en">English</option>' +
+        '</select><span class="field-error" data-error="/language"></span></fieldset>' +
+        '<fieldset><legend>Views</legend>' + viewHistory(inv.views) + '</fieldset>' +
+        '<button type="submit">Save</button>' +
+        (editing.id ? '<button type="button" onclick="deleteInvite()">Delete</button>' : '') +
+        '<button type="button" onclick="loadRead()">Cancel</button>' +
+        '</form>';
+    document.getElementById('content').innerHTML = html;
+    document.getElementById('language').value = inv.language || '';
+    (inv.people || []).forEach(function(p) { addRow('people', p); });
+    (inv.additional || []).forEach(function(a) { addRow('additional', a); });
+}
+
+function viewHistory(views) {
+    if (!views) return '<p>Never opened</p>';
+    var recent = (views.recent || []).slice().reverse();
+    return viewStats('Guests', views.browser) + viewStats('Link previews and bots', views.bot) +
+        (recent.length === 0 ? '' : '<p>Latest views</p><ol>' + recent.map(function(v) {
+            return '<li>' + formatTime(v.at) + (v.bot ? ' (bot)' : '') + '</li>';
+        }).join('') + '</ol>');
+}
+
+function viewStats(label, s) {
+    if (!s || s.count === 0) return '<p>' + label + ': never</p>';
+    return '<p>' + label + ': ' + s.count + (s.count === 1 ? ' view' : ' views') +
+        ', first ' + formatTime(s.first) + ', last ' + formatTime(s.last) + '</p>';
+}
+
+function addRow(list, value) {
+    var container = document.getElementById(list);
+    var row = document.createElement('div');
+    row.className = 'row';
+    row.innerHTML = '<input type="text"> <button type="button">Remove</button><span class="field-error"></span>';
+    row.querySelector('input').value = value;
+    row.querySelector('button').onclick = function() {
+        container.removeChild(row);
+        renumber(list);
+    };
+    container.appendChild(row);
+    renumber(list);
+}
+
+// renumber keeps each row's JSON pointer in step with its position, so
+// server field errors land on the right input.
+function renumber(list) {
+    var rows = document.getElementById(list).children;
+    for (var i = 0; i < rows.length; i++) {
+        var pointer = '/' + list + '/' + i;
+        rows[i].querySelector('input').dataset.pointer = pointer;
+        rows[i].querySelector('.field-error').dataset.error = pointer;
+    }
+}
+
+function readForm() {
+    var values = function(list) {
+        return Array.from(document.querySelectorAll('#' + list + ' input')).map(function(el) { return normalizeName(el.value); });
+    };
+    var input = {
+        people: values('people'),
+        additional_count: Number(document.getElementById('additionalCount').value),
+        additional: values('additional').filter(Boolean),
+        rsvp: document.querySelector('input[name=rsvp]:checked').value
+    };
+    var lang = document.getElementById('language').value;
+    if (lang) input.language = lang;
+    return input;
+}
+
+// validate returns field errors in the same shape as the server's.
+function validate(input) {
+    var errors = [];
+    var add = function(pointer, message) { errors.push({ pointer: pointer, message: message }); };
+    if (input.people.length === 0) add('/people', 'At least one person is required');
+    input.people.forEach(function(p, i) {
+        if (!p) add('/people/' + i, 'Name must not be empty');
+    });
+    if (!Number.isInteger(input.additional_count) || input.additional_count < 0) {
+        add('/additional_count', 'Must be a whole number, 0 or more');
+    } else if (input.additional.length > input.additional_count) {
+        add('/additional/' + input.additional_count, 'Only ' + input.additional_count + ' additional guests are allowed');
+    }
+    input.additional.forEach(function(a, i) {
+        if (nameRules.maxLength > 0 && Array.from(a).length > nameRules.maxLength) {
+            add('/additional/' + i, 'At most ' + nameRules.maxLength + ' characters');
+        } else if (!nameRules.regex.test(a)) {
+            add('/additional/' + i, 'Contains characters that are not allowed (' + nameRules.pattern + ')');
+        }
+    });
+    return errors;
+}
+
+function showErrors(errors) {
+    document.querySelectorAll('#inviteForm .field-error').forEach(function(el) { el.textContent = ''; });
+    document.querySelectorAll('#inviteForm input.invalid').forEach(function(el) { el.classList.remove('invalid'); });
+    var unplaced = [];
+    errors.forEach(function(e) {
+        var msg = document.querySelector('#inviteForm [data-error="' + e.pointer + '"]');
+        var input = document.querySelector('#inviteForm [data-pointer="' + e.pointer + '"]');
+        if (input) input.classList.add('invalid');
+        if (msg) msg.textContent = e.message;
+        else unplaced.push(e.pointer + ': ' + e.message);
+    });
+    return unplaced;
+}
+
+function saveInvite() {
+    // Reason: blank additional rows are dropped, so re-render them away before
+    // pointers are matched against the submitted list
+    document.querySelectorAll('#additional .row').forEach(function(row) {
+        if (!normalizeName(row.querySelector('input').value)) row.parentNode.removeChild(row);
+    });
+    renumber('additional');
+
+    var input = readForm();
+    var errors = validate(input);
+    var unplaced = showErrors(errors);
+    if (errors.length > 0) {
+        setStatus('Please fix the highlighted fields' + (unplaced.length ? ': ' + unplaced.join('; ') : ''), true);
+        return;
+    }
+
+    setStatus('Saving...', false);
+    var url = editing.id ? '/admin/invites/' + encodeURIComponent(editing.id) : '/admin/invites';
+    fetch(url, {
+        method: editing.id ? 'PUT' : 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(input)
+    })
+    .then(function(r) {
+        return r.json().then(function(b) {
+            if (!r.ok) {
+                var unplaced = showErrors(b.fields || []);
+                throw new Error([b.message].concat(unplaced).join('; '));
+            }
+            return b;
+        });
+    })
+    .then(function(entry) {
+        editing = { id: entry.id, invite: entry.invite };
+        renderEditor();
+        setStatus('Saved', false);
+    })
+    .catch(function(err) { setStatus('Save failed: ' + err.message, true); });
+}
+
+function deleteInvite() {
+    if (!confirm('Delete this invite? This cannot be undone.')) return;
+    fetch('/admin/invites/' + encodeURIComponent(editing.id), { method: 'DELETE' })
+        .then(function(r) {
+            if (!r.ok) return r.json().then(function(b) { throw new Error(errorText(b)); });
+            setStatus('Invite deleted', false);
+            loadRead();
+        })
+        .catch(function(err) { setStatus('Delete failed: ' + err.message, true); });
+}
@@ -0,0 +1,10 @@
+// Package web embeds the static UI files so the binary serves them without
+// depending on the working directory.
+package web
+
+import "embed"
+
+// FS holds the admin UI under admin/ and the guest RSVP page under guest/.
+//
+//go:embed admin guest
+var FS embed.FS